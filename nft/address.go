@@ -0,0 +1,123 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+type AddressField string
+
+// Address Fields
+const (
+	AddressFieldSource      AddressField = schema.PayloadFieldIPSAddr
+	AddressFieldDestination AddressField = schema.PayloadFieldIPDAddr
+)
+
+// NewAddressRules returns the rules which match the given addresses in the given field,
+// followed by the given statements.
+// The addresses are IP addresses or CIDR prefixes (e.g. `10.0.0.1` or `2001:db8::/32`), of any IP version.
+// The IPv4 and IPv6 addresses are matched by separate rules (`ip saddr ...` and `ip6 saddr ...`),
+// as a single rule cannot match both, therefore up to two rules are returned, the IPv4 rule first.
+// It fails when the addresses are not supported by the table family (e.g. IPv6 addresses in an ip family table).
+func NewAddressRules(table *schema.Table, chain *schema.Chain, field AddressField, addresses []string, statements []schema.Statement, comment string) ([]*schema.Rule, error) {
+	var ipv4Addresses, ipv6Addresses []schema.Expression
+	for _, address := range addresses {
+		expression, isIPv4, err := addressExpression(address)
+		if err != nil {
+			return nil, err
+		}
+		if isIPv4 {
+			ipv4Addresses = append(ipv4Addresses, expression)
+		} else {
+			ipv6Addresses = append(ipv6Addresses, expression)
+		}
+	}
+
+	var rules []*schema.Rule
+	for _, group := range []struct {
+		protocol  string
+		addresses []schema.Expression
+	}{
+		{schema.PayloadProtocolIP4, ipv4Addresses},
+		{schema.PayloadProtocolIP6, ipv6Addresses},
+	} {
+		if len(group.addresses) == 0 {
+			continue
+		}
+		if !isProtocolSupportedByFamily(group.protocol, table.Family) {
+			return nil, fmt.Errorf("%s addresses are not supported in the %s family", group.protocol, table.Family)
+		}
+
+		right := group.addresses[0]
+		if len(group.addresses) > 1 {
+			right = AnonymousSet(group.addresses...)
+		}
+		match := addressMatch(group.protocol, field, right)
+		rules = append(rules, NewRule(table, chain, append([]schema.Statement{match}, statements...), nil, nil, comment))
+	}
+	return rules, nil
+}
+
+// addressMatch returns the match statement of the address field in the IP header of the given protocol.
+func addressMatch(protocol string, field AddressField, right schema.Expression) schema.Statement {
+	return schema.Statement{Match: &schema.Match{
+		Op:    schema.OperEQ,
+		Left:  schema.Expression{Payload: &schema.Payload{Protocol: protocol, Field: string(field)}},
+		Right: right,
+	}}
+}
+
+// addressExpression returns the expression of the IP address or CIDR prefix
+// and reports if it is an IPv4 one.
+func addressExpression(address string) (schema.Expression, bool, error) {
+	if strings.Contains(address, "/") {
+		_, prefix, err := net.ParseCIDR(address)
+		if err != nil {
+			return schema.Expression{}, false, err
+		}
+		expression, err := prefixExpression(prefix)
+		return expression, prefix.IP.To4() != nil, err
+	}
+
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return schema.Expression{}, false, fmt.Errorf("invalid IP address: %q", address)
+	}
+	addr := ip.String()
+	return schema.Expression{String: &addr}, ip.To4() != nil, nil
+}
+
+// prefixExpression returns the expression of the CIDR prefix (e.g. `10.0.0.0/8`).
+func prefixExpression(prefix *net.IPNet) (schema.Expression, error) {
+	prefixLen, _ := prefix.Mask.Size()
+	data, err := json.Marshal(map[string]interface{}{
+		"prefix": map[string]interface{}{"addr": prefix.IP.String(), "len": prefixLen},
+	})
+	if err != nil {
+		return schema.Expression{}, err
+	}
+	return schema.Expression{RowData: data}, nil
+}
@@ -0,0 +1,80 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/networkplumbing/go-nft/nft"
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+func TestNewAddressRules(t *testing.T) {
+	accept := []schema.Statement{{Verdict: schema.Accept()}}
+
+	t.Run("Split IPv4 and IPv6 addresses in the inet family", func(t *testing.T) {
+		table := nft.NewTable(tableName, nft.FamilyINET)
+		chain := nft.NewRegularChain(table, chainName)
+		rules, err := nft.NewAddressRules(table, chain, nft.AddressFieldSource,
+			[]string{"10.0.0.1", "2001:db8::1", "192.168.0.0/16"}, accept, "trusted",
+		)
+		assert.NoError(t, err)
+		assert.Len(t, rules, 2)
+
+		assertRuleExpr(t, rules[0], chainName,
+			`[{"match":{"op":"==","left":{"payload":{"protocol":"ip","field":"saddr"}},`+
+				`"right":{"set":["10.0.0.1",{"prefix":{"addr":"192.168.0.0","len":16}}]}}},{"accept":null}]`)
+		assertRuleExpr(t, rules[1], chainName,
+			`[{"match":{"op":"==","left":{"payload":{"protocol":"ip6","field":"saddr"}},"right":"2001:db8::1"}},{"accept":null}]`)
+		assert.Equal(t, "trusted", rules[1].Comment)
+	})
+
+	t.Run("Match IPv4 addresses in the ip family", func(t *testing.T) {
+		table := nft.NewTable(tableName, nft.FamilyIP)
+		chain := nft.NewRegularChain(table, chainName)
+		rules, err := nft.NewAddressRules(table, chain, nft.AddressFieldDestination, []string{"10.0.0.0/8"}, accept, "")
+		assert.NoError(t, err)
+		assert.Len(t, rules, 1)
+
+		serialized, err := json.Marshal(rules[0].Expr[0])
+		assert.NoError(t, err)
+		assert.Equal(t,
+			`{"match":{"op":"==","left":{"payload":{"protocol":"ip","field":"daddr"}},"right":{"prefix":{"addr":"10.0.0.0","len":8}}}}`,
+			string(serialized),
+		)
+	})
+
+	t.Run("Fail to match IPv6 addresses in the ip family", func(t *testing.T) {
+		table := nft.NewTable(tableName, nft.FamilyIP)
+		chain := nft.NewRegularChain(table, chainName)
+		_, err := nft.NewAddressRules(table, chain, nft.AddressFieldSource, []string{"10.0.0.1", "::1"}, accept, "")
+		assert.EqualError(t, err, "ip6 addresses are not supported in the ip family")
+	})
+
+	t.Run("Fail to match an invalid address", func(t *testing.T) {
+		table := nft.NewTable(tableName, nft.FamilyINET)
+		chain := nft.NewRegularChain(table, chainName)
+		_, err := nft.NewAddressRules(table, chain, nft.AddressFieldSource, []string{"10.0.0"}, accept, "")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,112 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+const arenaChunkSize = 256
+
+type arenaChunk struct {
+	statements  [arenaChunkSize]schema.Statement
+	matches     [arenaChunkSize]schema.Match
+	expressions [arenaChunkSize]schema.Expression
+}
+
+var arenaChunkPool = sync.Pool{
+	New: func() interface{} { return &arenaChunk{} },
+}
+
+// Arena is an opt-in allocator of statements and expressions for short-lived configs,
+// e.g. configs built on each loop of a high-frequency reconciler.
+// Allocations are carved out of chunks taken from a shared pool, which are returned to it on Release.
+//
+// Release should be called once the config built from the arena allocations is no longer used
+// (commonly right after ApplyConfig).
+// The allocations must not be accessed after the release.
+// An Arena is not safe for concurrent use.
+type Arena struct {
+	chunks []*arenaChunk
+
+	statementsUsed  int
+	matchesUsed     int
+	expressionsUsed int
+}
+
+// Statements returns a slice of n zeroed statements.
+// Requests larger than the arena chunk are allocated regularly.
+// It fails when n is negative.
+func (a *Arena) Statements(n int) ([]schema.Statement, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("invalid arena statements count: %d", n)
+	}
+	if n > arenaChunkSize {
+		return make([]schema.Statement, n), nil
+	}
+	if len(a.chunks) == 0 || a.statementsUsed+n > arenaChunkSize {
+		a.newChunk()
+	}
+	chunk := a.chunks[len(a.chunks)-1]
+	statements := chunk.statements[a.statementsUsed : a.statementsUsed+n : a.statementsUsed+n]
+	a.statementsUsed += n
+	return statements, nil
+}
+
+// Match returns a match with the given operator and operands.
+func (a *Arena) Match(op string, left, right schema.Expression) *schema.Match {
+	if len(a.chunks) == 0 || a.matchesUsed == arenaChunkSize {
+		a.newChunk()
+	}
+	match := &a.chunks[len(a.chunks)-1].matches[a.matchesUsed]
+	a.matchesUsed++
+	match.Op, match.Left, match.Right = op, left, right
+	return match
+}
+
+// Expression returns a pointer to a copy of the given expression.
+func (a *Arena) Expression(expression schema.Expression) *schema.Expression {
+	if len(a.chunks) == 0 || a.expressionsUsed == arenaChunkSize {
+		a.newChunk()
+	}
+	expr := &a.chunks[len(a.chunks)-1].expressions[a.expressionsUsed]
+	a.expressionsUsed++
+	*expr = expression
+	return expr
+}
+
+// Release clears the arena allocations and returns them to the pool.
+// The arena may be reused afterwards.
+func (a *Arena) Release() {
+	for _, chunk := range a.chunks {
+		*chunk = arenaChunk{}
+		arenaChunkPool.Put(chunk)
+	}
+	a.chunks = a.chunks[:0]
+	a.statementsUsed, a.matchesUsed, a.expressionsUsed = 0, 0, 0
+}
+
+func (a *Arena) newChunk() {
+	a.chunks = append(a.chunks, arenaChunkPool.Get().(*arenaChunk))
+	a.statementsUsed, a.matchesUsed, a.expressionsUsed = 0, 0, 0
+}
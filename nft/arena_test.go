@@ -0,0 +1,115 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft_test
+
+import (
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/networkplumbing/go-nft/nft"
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+func TestArena(t *testing.T) {
+	t.Run("config built from arena allocations", testArenaConfig)
+	t.Run("release clears the allocations", testArenaRelease)
+	t.Run("negative statements count is rejected", testArenaNegativeStatements)
+}
+
+func testArenaConfig(t *testing.T) {
+	var arena nft.Arena
+	defer arena.Release()
+
+	arenaConfig := buildConfig(t, &arena, 1000)
+	regularConfig := buildConfig(t, nil, 1000)
+
+	arenaJSON, err := arenaConfig.ToJSON()
+	assert.NoError(t, err)
+	regularJSON, err := regularConfig.ToJSON()
+	assert.NoError(t, err)
+	assert.Equal(t, string(regularJSON), string(arenaJSON))
+}
+
+func testArenaRelease(t *testing.T) {
+	var arena nft.Arena
+	statements, err := arena.Statements(2)
+	assert.NoError(t, err)
+	statements[0].Drop = true
+	iface := "lo"
+	match := arena.Match(schema.OperEQ, schema.Expression{Meta: &schema.Meta{Key: schema.MetaKeyIIFName}}, schema.Expression{String: &iface})
+
+	arena.Release()
+
+	assert.Equal(t, schema.Statement{}, statements[0])
+	assert.Equal(t, schema.Match{}, *match)
+}
+
+func testArenaNegativeStatements(t *testing.T) {
+	var arena nft.Arena
+	statements, err := arena.Statements(-1)
+	assert.Error(t, err)
+	assert.Nil(t, statements)
+}
+
+func BenchmarkConfigBuild(b *testing.B) {
+	b.Run("regular", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			buildConfig(b, nil, 100)
+		}
+	})
+	b.Run("arena", func(b *testing.B) {
+		b.ReportAllocs()
+		var arena nft.Arena
+		for i := 0; i < b.N; i++ {
+			buildConfig(b, &arena, 100)
+			arena.Release()
+		}
+	})
+}
+
+// buildConfig builds a config with the given number of rules, using the arena when it is provided.
+func buildConfig(tb testing.TB, arena *nft.Arena, rulesCount int) *nft.Config {
+	config := nft.NewConfig()
+	table := nft.NewTable(tableName, nft.FamilyIP)
+	chain := nft.NewRegularChain(table, chainName)
+	config.AddTable(table)
+	config.AddChain(chain)
+
+	left := schema.Expression{Payload: &schema.Payload{Protocol: schema.PayloadProtocolTCP, Field: schema.PayloadFieldTCPDPort}}
+	for i := 0; i < rulesCount; i++ {
+		port := float64(i)
+		right := schema.Expression{Float64: &port}
+		var statements []schema.Statement
+		if arena != nil {
+			var err error
+			statements, err = arena.Statements(2)
+			assert.NoError(tb, err)
+			statements[0].Match = arena.Match(schema.OperEQ, left, right)
+		} else {
+			statements = make([]schema.Statement, 2)
+			statements[0].Match = &schema.Match{Op: schema.OperEQ, Left: left, Right: right}
+		}
+		statements[1].Verdict.SimpleVerdict.Accept = true
+		config.AddRule(nft.NewRule(table, chain, statements, nil, nil, ""))
+	}
+	return config
+}
@@ -0,0 +1,63 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft
+
+import (
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+// And returns the binary AND of the expressions (e.g. `ct mark & 0xff00`).
+func And(left, right schema.Expression) schema.Expression {
+	return schema.Expression{And: []schema.Expression{left, right}}
+}
+
+// Or returns the binary OR of the expressions (e.g. `syn | ack`).
+func Or(left, right schema.Expression) schema.Expression {
+	return schema.Expression{Or: []schema.Expression{left, right}}
+}
+
+// Xor returns the binary XOR of the expressions (e.g. `meta mark ^ 0x1`).
+func Xor(left, right schema.Expression) schema.Expression {
+	return schema.Expression{Xor: []schema.Expression{left, right}}
+}
+
+// LShift returns the expression shifted left by the given number of bits (e.g. `ct mark << 8`).
+func LShift(expression schema.Expression, bits int) schema.Expression {
+	b := float64(bits)
+	return schema.Expression{LShift: []schema.Expression{expression, {Float64: &b}}}
+}
+
+// RShift returns the expression shifted right by the given number of bits (e.g. `ct mark >> 8`).
+func RShift(expression schema.Expression, bits int) schema.Expression {
+	b := float64(bits)
+	return schema.Expression{RShift: []schema.Expression{expression, {Float64: &b}}}
+}
+
+// MatchMasked returns a match statement which tests the expression, under the given mask, to equal the value.
+// For example, matching the connection mark `ct mark & 0xff00 == 0x1000`.
+func MatchMasked(expression schema.Expression, mask, value uint32) schema.Statement {
+	m := float64(mask)
+	v := float64(value)
+	return schema.Statement{Match: &schema.Match{
+		Op:    schema.OperEQ,
+		Left:  And(expression, schema.Expression{Float64: &m}),
+		Right: schema.Expression{Float64: &v},
+	}}
+}
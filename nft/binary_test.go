@@ -0,0 +1,97 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/networkplumbing/go-nft/nft"
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+func TestBinaryExpressions(t *testing.T) {
+	ctMark := schema.Expression{Ct: &schema.Ct{Key: schema.CtKeyMark}}
+	packetMark := schema.Expression{Meta: &schema.Meta{Key: schema.MetaKeyMark}}
+	one := float64(1)
+
+	tests := []struct {
+		name                 string
+		expression           schema.Expression
+		serializedExpression string
+	}{
+		{
+			name:                 "or",
+			expression:           nft.Or(packetMark, schema.Expression{Float64: &one}),
+			serializedExpression: `{"|":[{"meta":{"key":"mark"}},1]}`,
+		},
+		{
+			name:                 "xor",
+			expression:           nft.Xor(packetMark, schema.Expression{Float64: &one}),
+			serializedExpression: `{"^":[{"meta":{"key":"mark"}},1]}`,
+		},
+		{
+			name:                 "left shift",
+			expression:           nft.LShift(ctMark, 8),
+			serializedExpression: `{"\u003c\u003c":[{"ct":{"key":"mark"}},8]}`,
+		},
+		{
+			name:                 "right shift",
+			expression:           nft.RShift(ctMark, 8),
+			serializedExpression: `{"\u003e\u003e":[{"ct":{"key":"mark"}},8]}`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name+", check serialization", func(t *testing.T) {
+			serialized, err := json.Marshal(test.expression)
+			assert.NoError(t, err)
+			assert.Equal(t, test.serializedExpression, string(serialized))
+		})
+
+		t.Run(test.name+", check deserialization", func(t *testing.T) {
+			var expression schema.Expression
+			assert.NoError(t, json.Unmarshal([]byte(test.serializedExpression), &expression))
+			assert.Equal(t, test.expression, expression)
+		})
+	}
+
+	t.Run("deserialize unescaped shift", func(t *testing.T) {
+		var expression schema.Expression
+		assert.NoError(t, json.Unmarshal([]byte(`{">>":[{"ct":{"key":"mark"}},8]}`), &expression))
+		assert.Equal(t, nft.RShift(ctMark, 8), expression)
+	})
+}
+
+func TestMatchMasked(t *testing.T) {
+	const serialized = `{"match":{"op":"==","left":{"\u0026":[{"ct":{"key":"mark"}},65280]},"right":4096}}`
+	statement := nft.MatchMasked(schema.Expression{Ct: &schema.Ct{Key: schema.CtKeyMark}}, 0xff00, 0x1000)
+
+	serializedStatement, err := json.Marshal(statement)
+	assert.NoError(t, err)
+	assert.Equal(t, serialized, string(serializedStatement))
+
+	var deserialized schema.Statement
+	assert.NoError(t, json.Unmarshal([]byte(serialized), &deserialized))
+	assert.Equal(t, statement, deserialized)
+}
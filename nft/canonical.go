@@ -0,0 +1,163 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+const (
+	anonymousSetKey = "set"
+	rangeKey        = "range"
+)
+
+// CanonicalizeStatements returns the statements with their anonymous sets in a canonical form.
+// When listing the ruleset, nft reorders and merges the elements of anonymous sets,
+// therefore a rule read back from the system may not be identical to the rule that has been applied.
+// Canonicalizing both forms allows comparing them.
+//
+// The following is performed on each anonymous set:
+//   - Duplicate elements are removed.
+//   - Numerical elements and ranges which overlap or are adjacent, are merged into ranges.
+//   - Elements are sorted, numerical elements first.
+//   - A set with a single element is replaced by the element itself.
+func CanonicalizeStatements(statements []schema.Statement) ([]schema.Statement, error) {
+	if statements == nil {
+		return nil, nil
+	}
+
+	canonicalStatements := make([]schema.Statement, len(statements))
+	for i, statement := range statements {
+		data, err := canonicalStatementJSON(statement)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &canonicalStatements[i]); err != nil {
+			return nil, err
+		}
+	}
+	return canonicalStatements, nil
+}
+
+// canonicalStatementJSON returns the JSON encoding of the statement, with its anonymous sets canonicalized.
+func canonicalStatementJSON(statement schema.Statement) ([]byte, error) {
+	data, err := json.Marshal(statement)
+	if err != nil {
+		return nil, err
+	}
+	var dynamicStructure interface{}
+	if err := json.Unmarshal(data, &dynamicStructure); err != nil {
+		return nil, err
+	}
+	return json.Marshal(canonicalizeValue(dynamicStructure))
+}
+
+func canonicalizeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = canonicalizeValue(item)
+		}
+		if elements, isSet := v[anonymousSetKey].([]interface{}); isSet && len(v) == 1 {
+			elements = canonicalizeSetElements(elements)
+			if len(elements) == 1 {
+				return elements[0]
+			}
+			v[anonymousSetKey] = elements
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = canonicalizeValue(item)
+		}
+		return v
+	}
+	return value
+}
+
+type interval struct {
+	from, to float64
+}
+
+func canonicalizeSetElements(elements []interface{}) []interface{} {
+	var intervals []interval
+	others := map[string]interface{}{}
+	for _, element := range elements {
+		if i, isInterval := numericalInterval(element); isInterval {
+			intervals = append(intervals, i)
+			continue
+		}
+		data, _ := json.Marshal(element)
+		others[string(data)] = element
+	}
+
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i].from < intervals[j].from })
+	var merged []interval
+	for _, i := range intervals {
+		if last := len(merged) - 1; last >= 0 && i.from <= merged[last].to+1 {
+			merged[last].to = math.Max(merged[last].to, i.to)
+			continue
+		}
+		merged = append(merged, i)
+	}
+
+	canonicalElements := make([]interface{}, 0, len(merged)+len(others))
+	for _, i := range merged {
+		if i.from == i.to {
+			canonicalElements = append(canonicalElements, i.from)
+		} else {
+			canonicalElements = append(canonicalElements, map[string]interface{}{rangeKey: []interface{}{i.from, i.to}})
+		}
+	}
+	keys := make([]string, 0, len(others))
+	for key := range others {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		canonicalElements = append(canonicalElements, others[key])
+	}
+	return canonicalElements
+}
+
+// numericalInterval returns the interval of an integer element or an integers range element.
+func numericalInterval(element interface{}) (interval, bool) {
+	switch e := element.(type) {
+	case float64:
+		if e == math.Trunc(e) {
+			return interval{e, e}, true
+		}
+	case map[string]interface{}:
+		r, isRange := e[rangeKey].([]interface{})
+		if !isRange || len(e) != 1 || len(r) != 2 {
+			return interval{}, false
+		}
+		from, isFromNumber := r[0].(float64)
+		to, isToNumber := r[1].(float64)
+		if isFromNumber && isToNumber && from == math.Trunc(from) && to == math.Trunc(to) && from <= to {
+			return interval{from, to}, true
+		}
+	}
+	return interval{}, false
+}
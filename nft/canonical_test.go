@@ -0,0 +1,75 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/networkplumbing/go-nft/nft"
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+func TestCanonicalizeStatements(t *testing.T) {
+	t.Run("Canonicalize anonymous set with unordered and adjacent elements", func(t *testing.T) {
+		statements := matchDPortWithSet(`{"set":[8080,"http",22,{"range":[8000,8079]},23,22]}`)
+
+		canonicalStatements, err := nft.CanonicalizeStatements(statements)
+		assert.NoError(t, err)
+
+		serialized, err := json.Marshal(canonicalStatements)
+		assert.NoError(t, err)
+		assert.Equal(t,
+			`[{"match":{"op":"==","left":{"payload":{"protocol":"tcp","field":"dport"}},"right":{"set":[{"range":[22,23]},{"range":[8000,8080]},"http"]}}}]`,
+			string(serialized),
+		)
+	})
+
+	t.Run("Canonicalize anonymous set with a single element", func(t *testing.T) {
+		canonicalStatements, err := nft.CanonicalizeStatements(matchDPortWithSet(`{"set":[22]}`))
+		assert.NoError(t, err)
+
+		port := float64(22)
+		assert.Equal(t, schema.Expression{Float64: &port}, canonicalStatements[0].Match.Right)
+	})
+}
+
+func testRuleLookupWithReadBackSet(t *testing.T) {
+	t.Run("Lookup a rule with an anonymous set in its read-back form", func(t *testing.T) {
+		table := nft.NewTable(tableName, nft.FamilyIP)
+		chain := nft.NewRegularChain(table, chainName)
+
+		config := nft.NewConfig()
+		config.AddRule(nft.NewRule(table, chain, matchDPortWithSet(`{"set":[{"range":[1000,2000]},80,81,443]}`), nil, nil, ""))
+
+		rule := nft.NewRule(table, chain, matchDPortWithSet(`{"set":[443,{"range":[80,81]},{"range":[1000,2000]}]}`), nil, nil, "")
+		assert.Len(t, config.LookupRule(rule), 1)
+	})
+}
+
+func matchDPortWithSet(set string) []schema.Statement {
+	return []schema.Statement{{Match: &schema.Match{
+		Op:    schema.OperEQ,
+		Left:  schema.Expression{Payload: &schema.Payload{Protocol: schema.PayloadProtocolTCP, Field: schema.PayloadFieldTCPDPort}},
+		Right: schema.Expression{RowData: json.RawMessage(set)},
+	}}}
+}
@@ -20,6 +20,9 @@
 package nft
 
 import (
+	"encoding/json"
+	"fmt"
+
 	"github.com/networkplumbing/go-nft/nft/schema"
 )
 
@@ -42,6 +45,44 @@ const (
 	HookForward     ChainHook = schema.HookForward
 	HookPostRouting ChainHook = schema.HookPostRouting
 	HookIngress     ChainHook = schema.HookIngress
+	HookEgress      ChainHook = schema.HookEgress
+)
+
+// Chain Priorities
+// The netfilter priorities of the ip, ip6 and inet families, commonly used for base chains, e.g.
+// `prio := nft.PriorityNATDest` as the priority of a NAT prerouting chain.
+// A chain of a lower priority value is evaluated before a chain (on the same hook) of a higher value.
+const (
+	PriorityConntrackDefrag = -400
+	PriorityRaw             = -300
+	PriorityConntrack       = -200
+	PriorityMangle          = -150
+	PriorityNATDest         = -100
+	PriorityFilter          = 0
+	PrioritySecurity        = 50
+	PriorityNATSource       = 100
+	PriorityConntrackHelper = 300
+)
+
+// Bridge Chain Priorities
+const (
+	PriorityBridgeNATDest   = -300
+	PriorityBridgeFilter    = -200
+	PriorityBridgeOut       = 100
+	PriorityBridgeNATSource = 300
+)
+
+type ChainPriorityName string
+
+// Chain Priority Names
+const (
+	PriorityNameRaw      ChainPriorityName = schema.PrioNameRaw
+	PriorityNameMangle   ChainPriorityName = schema.PrioNameMangle
+	PriorityNameDstNAT   ChainPriorityName = schema.PrioNameDstNAT
+	PriorityNameFilter   ChainPriorityName = schema.PrioNameFilter
+	PriorityNameSecurity ChainPriorityName = schema.PrioNameSecurity
+	PriorityNameSrcNAT   ChainPriorityName = schema.PrioNameSrcNAT
+	PriorityNameOut      ChainPriorityName = schema.PrioNameOut
 )
 
 // Chain Policies
@@ -81,6 +122,69 @@ func NewChain(table *schema.Table, name string, ctype *ChainType, hook *ChainHoo
 	return c
 }
 
+// NewNetdevChain returns a new schema chain structure for a filter base chain attached to the given devices,
+// on the ingress or egress hook.
+// It is commonly used in the netdev family, while the inet family supports the ingress hook with a single device.
+// The policy is optional.
+func NewNetdevChain(table *schema.Table, name string, hook ChainHook, prio int, devices []string, policy *ChainPolicy) *schema.Chain {
+	ctype := TypeFilter
+	c := NewChain(table, name, &ctype, &hook, &prio, policy)
+	c.Dev = append(schema.StringList(nil), devices...)
+	return c
+}
+
+// ChainWithPriorityName sets the textual priority of the given (base) chain, replacing its numeric priority,
+// and returns it.
+// nft resolves the name to a numeric priority according to the table family and the chain hook.
+func ChainWithPriorityName(chain *schema.Chain, name ChainPriorityName) *schema.Chain {
+	chain.Prio = nil
+	chain.PrioName = string(name)
+	return chain
+}
+
+// chainPriorities are the numeric values of the priority names, per family.
+// The bridge family has its own values, the other families share the default ones.
+var (
+	chainPriorities = map[string]int{
+		schema.PrioNameRaw:      PriorityRaw,
+		schema.PrioNameMangle:   PriorityMangle,
+		schema.PrioNameDstNAT:   PriorityNATDest,
+		schema.PrioNameFilter:   PriorityFilter,
+		schema.PrioNameSecurity: PrioritySecurity,
+		schema.PrioNameSrcNAT:   PriorityNATSource,
+	}
+	bridgeChainPriorities = map[string]int{
+		schema.PrioNameDstNAT: PriorityBridgeNATDest,
+		schema.PrioNameFilter: PriorityBridgeFilter,
+		schema.PrioNameOut:    PriorityBridgeOut,
+		schema.PrioNameSrcNAT: PriorityBridgeNATSource,
+	}
+)
+
+// withChainDefaults returns a copy of a chain entry in the form nft lists it:
+// a base chain without a policy has the accept policy, and a priority name is resolved to its numeric value.
+// Other entries are returned as is.
+func withChainDefaults(nftable schema.Nftable) schema.Nftable {
+	if nftable.Chain == nil || nftable.Chain.Type == "" {
+		return nftable
+	}
+	chain := *nftable.Chain
+	if chain.Policy == "" {
+		chain.Policy = schema.PolicyAccept
+	}
+	if chain.PrioName != "" {
+		priorities := chainPriorities
+		if chain.Family == schema.FamilyBridge {
+			priorities = bridgeChainPriorities
+		}
+		if prio, exists := priorities[chain.PrioName]; exists {
+			chain.Prio, chain.PrioName = &prio, ""
+		}
+	}
+	nftable.Chain = &chain
+	return nftable
+}
+
 // AddChain appends the given chain to the nftable config.
 // The chain is added without an explicit action (`add`).
 // Adding multiple times the same chain has no affect when the config is applied.
@@ -89,6 +193,26 @@ func (c *Config) AddChain(chain *schema.Chain) {
 	c.Nftables = append(c.Nftables, nftable)
 }
 
+// AddChainChecked appends the given chain to the nftable config, similar to AddChain,
+// after verifying that the chain table is declared in the config.
+// It catches the common mistake of mixing table identifiers (e.g. the ip and inet families) in a transaction.
+// Tables which already exist on the system can be declared by adding them to the config,
+// as adding an existing table has no effect when the config is applied.
+func (c *Config) AddChainChecked(chain *schema.Chain) error {
+	if err := c.checkTableDeclared(chain.Family, chain.Table); err != nil {
+		return fmt.Errorf("chain %q: %v", chain.Name, err)
+	}
+	c.AddChain(chain)
+	return nil
+}
+
+// CreateChain appends the given chain to the nftable config with the `create` action.
+// Unlike adding, creating a chain which already exists results with a failure when the config is applied.
+func (c *Config) CreateChain(chain *schema.Chain) {
+	nftable := schema.Nftable{Create: &schema.Objects{Chain: chain}}
+	c.Nftables = append(c.Nftables, nftable)
+}
+
 // DeleteChain appends a given chain to the nftable config
 // with the `delete` action.
 // Attempting to delete a non-existing chain, results with a failure when the config is applied.
@@ -98,6 +222,38 @@ func (c *Config) DeleteChain(chain *schema.Chain) {
 	c.Nftables = append(c.Nftables, nftable)
 }
 
+// DeleteChainByHandle appends a given chain to the nftable config
+// with the `delete` action, identifying the chain strictly by its table and handle (ignoring its name).
+// See DeleteTableByHandle for details.
+func (c *Config) DeleteChainByHandle(chain *schema.Chain) error {
+	if chain.Handle == nil {
+		return fmt.Errorf("chain %q in table %s %s has no handle", chain.Name, chain.Family, chain.Table)
+	}
+	c.DeleteChain(&schema.Chain{Family: chain.Family, Table: chain.Table, Handle: chain.Handle})
+	return nil
+}
+
+// DestroyChain appends a given chain to the nftable config with the `destroy` action.
+// Unlike deleting, destroying a non-existing chain has no effect when the config is applied.
+// The chain must not contain any rules or be used as a jump target.
+func (c *Config) DestroyChain(chain *schema.Chain) {
+	nftable := schema.Nftable{Destroy: &schema.Objects{Chain: chain}}
+	c.Nftables = append(c.Nftables, nftable)
+}
+
+// RenameChain appends a given chain to the nftable config with the `rename` action,
+// renaming the chain to the new name when applied.
+// The chain rules are kept, as well as the jumps to it (which follow the renamed chain),
+// allowing a prepared chain to take over the name of a replaced one (e.g. in a blue/green rules swap)
+// without flushing it.
+// Attempting to rename a non-existing chain or to an existing chain name, results with a failure
+// when the config is applied.
+func (c *Config) RenameChain(chain *schema.Chain, newName string) {
+	renamed := &schema.Chain{Family: chain.Family, Table: chain.Table, Name: chain.Name, NewName: newName}
+	nftable := schema.Nftable{Rename: &schema.Objects{Chain: renamed}}
+	c.Nftables = append(c.Nftables, nftable)
+}
+
 // FlushChain appends a given chain to the nftable config
 // with the `flush` action.
 // All rules under the chain are removed (when applied).
@@ -107,32 +263,228 @@ func (c *Config) FlushChain(chain *schema.Chain) {
 	c.Nftables = append(c.Nftables, nftable)
 }
 
+// DeleteAllChains appends to the nftable config the commands to delete all the chains of the given table,
+// as found in the current config (commonly read from the system using ReadConfig).
+// All chains are flushed before they are deleted, removing their rules and the jump references between them.
+// Attempting to delete a chain which no longer exists, results with a failure when the config is applied.
+func (c *Config) DeleteAllChains(current *Config, table *schema.Table) {
+	var chains []*schema.Chain
+	for _, nftable := range current.Nftables {
+		if chain := nftable.Chain; chain != nil && chain.Family == table.Family && chain.Table == table.Name {
+			chains = append(chains, chain)
+		}
+	}
+
+	for _, chain := range chains {
+		c.FlushChain(chain)
+	}
+	for _, chain := range chains {
+		c.DeleteChain(chain)
+	}
+}
+
+// OrderJumpTargets reorders the nftable config, moving the chain additions which are jump or goto targets
+// (including the verdicts of anonymous verdict maps) before the first rule which references them.
+// The transaction fails when a rule references a chain which is added later in the same config,
+// therefore reordering allows adding the chains and rules in any order.
+// A chain addition is not moved before a command which deletes or flushes the same chain.
+func (c *Config) OrderJumpTargets() {
+	moved := make([]bool, len(c.Nftables))
+	ordered := make([]schema.Nftable, 0, len(c.Nftables))
+
+	for i, nftable := range c.Nftables {
+		if moved[i] {
+			continue
+		}
+		if rule := addedRule(nftable); rule != nil {
+			for _, target := range jumpTargets(rule) {
+				if j := c.indexOfChainAddition(rule.Family, rule.Table, target, i+1); j >= 0 && !moved[j] {
+					ordered = append(ordered, c.Nftables[j])
+					moved[j] = true
+				}
+			}
+		}
+		ordered = append(ordered, nftable)
+	}
+
+	c.Nftables = ordered
+}
+
+// indexOfChainAddition returns the index of the chain addition, searching from the given index.
+// The search stops when a command other than an addition references the chain, returning -1.
+func (c *Config) indexOfChainAddition(family, table, name string, from int) int {
+	for i := from; i < len(c.Nftables); i++ {
+		nftable := c.Nftables[i]
+		chain := nftable.Chain
+		if nftable.Add != nil {
+			chain = nftable.Add.Chain
+		} else if nftable.Create != nil {
+			chain = nftable.Create.Chain
+		}
+		if chain != nil && chain.Family == family && chain.Table == table && chain.Name == name {
+			return i
+		}
+		for _, objects := range []*schema.Objects{nftable.Delete, nftable.Destroy, nftable.Flush, nftable.Rename} {
+			if objects == nil {
+				continue
+			}
+			if ch := objects.Chain; ch != nil && ch.Family == family && ch.Table == table && ch.Name == name {
+				return -1
+			}
+			if t := objects.Table; t != nil && t.Family == family && t.Name == table {
+				return -1
+			}
+		}
+	}
+	return -1
+}
+
+func addedRule(nftable schema.Nftable) *schema.Rule {
+	if nftable.Add != nil {
+		return nftable.Add.Rule
+	}
+	if nftable.Insert != nil {
+		return nftable.Insert.Rule
+	}
+	return nftable.Rule
+}
+
+// jumpTargets returns the chain names which the rule statements jump or go to,
+// including the verdicts of the anonymous verdict maps.
+func jumpTargets(rule *schema.Rule) []string {
+	var targets []string
+	for _, statement := range rule.Expr {
+		verdicts := []schema.Verdict{statement.Verdict}
+		if statement.Vmap != nil {
+			verdicts = append(verdicts, anonymousMapVerdicts(statement.Vmap.Data)...)
+		}
+		for _, verdict := range verdicts {
+			if verdict.Jump != nil {
+				targets = append(targets, verdict.Jump.Target)
+			}
+			if verdict.Goto != nil {
+				targets = append(targets, verdict.Goto.Target)
+			}
+		}
+	}
+	return targets
+}
+
+// anonymousMapVerdicts returns the verdicts of the anonymous map elements (e.g. `{ 22 : jump ssh }`).
+// Elements which cannot be decoded as verdict map elements are skipped.
+func anonymousMapVerdicts(data schema.Expression) []schema.Verdict {
+	var verdicts []schema.Verdict
+	for _, element := range data.Set {
+		if element.RowData == nil {
+			continue
+		}
+		var mapElement schema.MapElement
+		if err := json.Unmarshal(element.RowData, &mapElement); err == nil && mapElement.Verdict != nil {
+			verdicts = append(verdicts, *mapElement.Verdict)
+		}
+	}
+	return verdicts
+}
+
+// EnsureChain appends the given chain to the nftable config, unless an equivalent chain is already
+// appended to it (with the same identity and attributes, e.g. the base chain hook and priority).
+// See EnsureTable for details.
+func (c *Config) EnsureChain(chain *schema.Chain) bool {
+	if c.hasEquivalentObject(schema.Nftable{Chain: chain}) {
+		return false
+	}
+	c.AddChain(chain)
+	return true
+}
+
+// RemoveChain removes a previously appended chain from the nftable config, together with its rules,
+// it does not affect the system.
+// Entries with an explicit action (e.g. a chain flush) are not removed.
+// It reports if the chain has been found and removed.
+func (c *Config) RemoveChain(chain *schema.Chain) bool {
+	removed := c.removeEntries(func(nftable schema.Nftable) bool {
+		if ch := nftable.Chain; ch != nil {
+			return ch.Family == chain.Family && ch.Table == chain.Table && ch.Name == chain.Name
+		}
+		r := nftable.Rule
+		return r != nil && r.Family == chain.Family && r.Table == chain.Table && r.Chain == chain.Name
+	})
+	return removed > 0
+}
+
 // LookupChain searches the configuration for a matching chain and returns it.
 // The chain is matched first by the table and chain name.
 // Other matching fields are optional (for matching base chains).
+// The (netdev) chain devices are compared regardless of their order.
 // Mutating the returned chain will result in mutating the configuration.
 func (c *Config) LookupChain(toFind *schema.Chain) *schema.Chain {
 	for _, nftable := range c.Nftables {
 		if chain := nftable.Chain; chain != nil {
 			match := chain.Table == toFind.Table && chain.Family == toFind.Family && chain.Name == toFind.Name
-			if match {
-				if t := toFind.Type; t != "" {
-					match = match && chain.Type == t
-				}
-				if h := toFind.Hook; h != "" {
-					match = match && chain.Hook == h
-				}
-				if p := toFind.Prio; p != nil {
-					match = match && chain.Prio != nil && *chain.Prio == *p
-				}
-				if p := toFind.Policy; p != "" {
-					match = match && chain.Policy == p
-				}
-				if match {
-					return chain
-				}
+			if match && isChainMatching(chain, toFind) {
+				return chain
 			}
 		}
 	}
 	return nil
 }
+
+// LookupChains searches the configuration for the matching chains and returns them.
+// Unlike LookupChain, an empty family, table or chain name matches any,
+// e.g. all the chains with a given name are matched regardless of their family and table.
+// Mutating the returned chains will result in mutating the configuration.
+func (c *Config) LookupChains(toFind *schema.Chain) []*schema.Chain {
+	var chains []*schema.Chain
+	for _, nftable := range c.Nftables {
+		if chain := nftable.Chain; chain != nil {
+			match := isWildcardMatch(toFind.Family, chain.Family) && isWildcardMatch(toFind.Table, chain.Table) &&
+				isWildcardMatch(toFind.Name, chain.Name)
+			if match && isChainMatching(chain, toFind) {
+				chains = append(chains, chain)
+			}
+		}
+	}
+	return chains
+}
+
+// isChainMatching reports if the chain matches the optional (base chain) fields to find.
+func isChainMatching(chain, toFind *schema.Chain) bool {
+	match := true
+	if t := toFind.Type; t != "" {
+		match = match && chain.Type == t
+	}
+	if h := toFind.Hook; h != "" {
+		match = match && chain.Hook == h
+	}
+	if p := toFind.Prio; p != nil {
+		match = match && chain.Prio != nil && *chain.Prio == *p
+	}
+	if p := toFind.PrioName; p != "" {
+		match = match && chain.PrioName == p
+	}
+	if d := toFind.Dev; len(d) > 0 {
+		match = match && areDevicesEqual(chain.Dev, d)
+	}
+	if p := toFind.Policy; p != "" {
+		match = match && chain.Policy == p
+	}
+	return match
+}
+
+// areDevicesEqual reports if both device lists contain the same devices, regardless of their order.
+func areDevicesEqual(a, b schema.StringList) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	devices := make(map[string]int, len(a))
+	for _, dev := range a {
+		devices[dev]++
+	}
+	for _, dev := range b {
+		if devices[dev] == 0 {
+			return false
+		}
+		devices[dev]--
+	}
+	return true
+}
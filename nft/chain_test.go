@@ -20,6 +20,7 @@
 package nft_test
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 	"testing"
@@ -36,9 +37,11 @@ type chainActionFunc func(*nft.Config, *schema.Chain)
 
 // Chain Actions
 const (
-	chainADD    chainAction = "add"
-	chainDELETE chainAction = "delete"
-	chainFLUSH  chainAction = "flush"
+	chainADD     chainAction = "add"
+	chainDELETE  chainAction = "delete"
+	chainFLUSH   chainAction = "flush"
+	chainCREATE  chainAction = "create"
+	chainDESTROY chainAction = "destroy"
 )
 
 const chainName = "test-chain"
@@ -50,6 +53,16 @@ func TestChain(t *testing.T) {
 	testRegularChainsActions(t)
 
 	testChainLookup(t)
+	testChainsWildcardLookup(t)
+	testDeleteAllChains(t)
+	testAddChainChecked(t)
+	testOrderJumpTargets(t)
+	testNetdevChainDevices(t)
+	testChainPriorityName(t)
+	testChainPriorityConstants(t)
+	testRenameChain(t)
+	testRemoveChain(t)
+	testEnsureChain(t)
 }
 
 func testAddBaseChains(t *testing.T) {
@@ -105,9 +118,11 @@ func testAddBaseChains(t *testing.T) {
 
 func testRegularChainsActions(t *testing.T) {
 	actions := map[chainAction]chainActionFunc{
-		chainADD:    func(c *nft.Config, chain *schema.Chain) { c.AddChain(chain) },
-		chainDELETE: func(c *nft.Config, chain *schema.Chain) { c.DeleteChain(chain) },
-		chainFLUSH:  func(c *nft.Config, chain *schema.Chain) { c.FlushChain(chain) },
+		chainADD:     func(c *nft.Config, chain *schema.Chain) { c.AddChain(chain) },
+		chainDELETE:  func(c *nft.Config, chain *schema.Chain) { c.DeleteChain(chain) },
+		chainFLUSH:   func(c *nft.Config, chain *schema.Chain) { c.FlushChain(chain) },
+		chainCREATE:  func(c *nft.Config, chain *schema.Chain) { c.CreateChain(chain) },
+		chainDESTROY: func(c *nft.Config, chain *schema.Chain) { c.DestroyChain(chain) },
 	}
 
 	table := nft.NewTable(tableName, nft.FamilyIP)
@@ -184,3 +199,335 @@ func testChainLookup(t *testing.T) {
 		assert.Nil(t, config.LookupChain(chain))
 	})
 }
+
+func testDeleteAllChains(t *testing.T) {
+	t.Run("Delete all chains of a table", func(t *testing.T) {
+		current := nft.NewConfig()
+		table := nft.NewTable(tableName, nft.FamilyIP)
+		current.AddTable(table)
+		current.AddChain(nft.NewRegularChain(table, "chain1"))
+		current.AddChain(nft.NewRegularChain(table, "chain2"))
+		otherTable := nft.NewTable(tableName, nft.FamilyIP6)
+		current.AddChain(nft.NewRegularChain(otherTable, "chain3"))
+
+		config := nft.NewConfig()
+		config.DeleteAllChains(current, table)
+
+		serializedConfig, err := config.ToJSON()
+		assert.NoError(t, err)
+
+		chainArgs := fmt.Sprintf(`"family":%q,"table":%q`, table.Family, table.Name)
+		expected := fmt.Sprintf(
+			`{"nftables":[`+
+				`{"flush":{"chain":{%[1]s,"name":"chain1"}}},{"flush":{"chain":{%[1]s,"name":"chain2"}}},`+
+				`{"delete":{"chain":{%[1]s,"name":"chain1"}}},{"delete":{"chain":{%[1]s,"name":"chain2"}}}`+
+				`]}`,
+			chainArgs,
+		)
+		assert.Equal(t, expected, string(serializedConfig))
+	})
+}
+
+func testAddChainChecked(t *testing.T) {
+	t.Run("Add checked chain to a declared table", func(t *testing.T) {
+		config := nft.NewConfig()
+		table := nft.NewTable(tableName, nft.FamilyINET)
+		config.AddTable(table)
+
+		assert.NoError(t, config.AddChainChecked(nft.NewRegularChain(table, chainName)))
+		assert.Len(t, config.Nftables, 2)
+	})
+
+	t.Run("Add checked chain to a table declared in another family", func(t *testing.T) {
+		config := nft.NewConfig()
+		config.AddTable(nft.NewTable(tableName, nft.FamilyINET))
+
+		chain := nft.NewRegularChain(nft.NewTable(tableName, nft.FamilyIP), chainName)
+		err := config.AddChainChecked(chain)
+		assert.EqualError(t, err, `chain "test-chain": table ip test-table is not declared in the config, found it in family: inet`)
+		assert.Len(t, config.Nftables, 1)
+	})
+
+	t.Run("Add checked chain to a created table", func(t *testing.T) {
+		config := nft.NewConfig()
+		table := nft.NewTable(tableName, nft.FamilyINET)
+		config.CreateTable(table)
+
+		assert.NoError(t, config.AddChainChecked(nft.NewRegularChain(table, chainName)))
+		assert.Len(t, config.Nftables, 2)
+	})
+}
+
+func testOrderJumpTargets(t *testing.T) {
+	table := nft.NewTable(tableName, nft.FamilyIP)
+	baseChain := nft.NewRegularChain(table, "base")
+	targetChain := nft.NewRegularChain(table, "target")
+	jumpRule := nft.NewRule(table, baseChain, []schema.Statement{
+		{Verdict: schema.Verdict{Jump: &schema.ToTarget{Target: targetChain.Name}}},
+	}, nil, nil, "")
+	targetRule := nft.NewRule(table, targetChain, []schema.Statement{{Verdict: schema.Accept()}}, nil, nil, "")
+
+	t.Run("Order a jump target chain which is added after the referencing rule", func(t *testing.T) {
+		config := nft.NewConfig()
+		config.AddTable(table)
+		config.AddChain(baseChain)
+		config.AddRule(jumpRule)
+		config.AddChain(targetChain)
+		config.AddRule(targetRule)
+
+		config.OrderJumpTargets()
+
+		expected := []schema.Nftable{
+			{Table: table}, {Chain: baseChain}, {Chain: targetChain}, {Rule: jumpRule}, {Rule: targetRule},
+		}
+		assert.Equal(t, expected, config.Nftables)
+	})
+
+	t.Run("Order a verdict map target chain which is added after the referencing rule", func(t *testing.T) {
+		var vmapRule schema.Rule
+		assert.NoError(t, json.Unmarshal([]byte(`{"family":"ip","table":"test-table","chain":"base","expr":[`+
+			`{"vmap":{"key":{"payload":{"protocol":"tcp","field":"dport"}},"data":{"set":[[22,{"goto":{"target":"target"}}]]}}}`+
+			`]}`), &vmapRule))
+		config := nft.NewConfig()
+		config.AddChain(baseChain)
+		config.AddRule(&vmapRule)
+		config.AddChain(targetChain)
+
+		config.OrderJumpTargets()
+
+		expected := []schema.Nftable{{Chain: baseChain}, {Chain: targetChain}, {Rule: &vmapRule}}
+		assert.Equal(t, expected, config.Nftables)
+	})
+
+	t.Run("Keep a jump target chain which is added after its deletion", func(t *testing.T) {
+		config := nft.NewConfig()
+		config.AddRule(jumpRule)
+		config.DeleteChain(targetChain)
+		config.AddChain(targetChain)
+		expected := append([]schema.Nftable{}, config.Nftables...)
+
+		config.OrderJumpTargets()
+		assert.Equal(t, expected, config.Nftables)
+	})
+}
+
+func testNetdevChainDevices(t *testing.T) {
+	table := nft.NewTable(tableName, nft.FamilyNETDEV)
+	serializedConfig := fmt.Sprintf(
+		`{"nftables":[{"chain":{"family":"netdev","table":%q,"name":%q,"type":"filter","hook":"ingress","prio":0,"dev":["eth0","eth1"]}}]}`,
+		tableName, chainName,
+	)
+
+	ctype, hook, prio := nft.TypeFilter, nft.HookIngress, 0
+	chain := nft.NewChain(table, chainName, &ctype, &hook, &prio, nil)
+	chain.Dev = schema.StringList{"eth0", "eth1"}
+
+	t.Run("Read a netdev chain with its devices", func(t *testing.T) {
+		var deserializedConfig nft.Config
+		assert.NoError(t, json.Unmarshal([]byte(serializedConfig), &deserializedConfig))
+
+		expectedConfig := nft.NewConfig()
+		expectedConfig.AddChain(chain)
+		assert.Equal(t, expectedConfig, &deserializedConfig)
+	})
+
+	t.Run("Read a netdev chain with a single device", func(t *testing.T) {
+		var deserializedChain schema.Chain
+		assert.NoError(t, json.Unmarshal([]byte(`{"family":"netdev","table":"t","name":"c","dev":"eth0"}`), &deserializedChain))
+		assert.Equal(t, schema.StringList{"eth0"}, deserializedChain.Dev)
+	})
+
+	config := nft.NewConfig()
+	config.AddChain(chain)
+
+	t.Run("Lookup a netdev chain with its devices in a different order", func(t *testing.T) {
+		toFind := *chain
+		toFind.Dev = schema.StringList{"eth1", "eth0"}
+		assert.Equal(t, chain, config.LookupChain(&toFind))
+	})
+
+	t.Run("Lookup a netdev chain which lost a device", func(t *testing.T) {
+		toFind := *chain
+		toFind.Dev = schema.StringList{"eth0", "eth2"}
+		assert.Nil(t, config.LookupChain(&toFind))
+	})
+
+	t.Run("New netdev chain", func(t *testing.T) {
+		netdevChain := nft.NewNetdevChain(table, chainName, nft.HookIngress, 0, []string{"eth0", "eth1"}, nil)
+		assert.Equal(t, chain, netdevChain)
+	})
+
+	t.Run("New netdev egress chain with a single device", func(t *testing.T) {
+		policy := nft.PolicyDrop
+		config := nft.NewConfig()
+		config.AddChain(nft.NewNetdevChain(table, chainName, nft.HookEgress, -100, []string{"eth0"}, &policy))
+
+		serialized, err := config.ToJSON()
+		assert.NoError(t, err)
+		expected := fmt.Sprintf(
+			`{"nftables":[{"chain":{"family":"netdev","table":%q,"name":%q,"type":"filter","hook":"egress","prio":-100,"dev":"eth0","policy":"drop"}}]}`,
+			tableName, chainName,
+		)
+		assert.Equal(t, expected, string(serialized))
+	})
+}
+
+func testChainPriorityName(t *testing.T) {
+	table := nft.NewTable(tableName, nft.FamilyINET)
+	serializedConfig := fmt.Sprintf(
+		`{"nftables":[{"chain":{"family":"inet","table":%q,"name":%q,"type":"nat","hook":"prerouting","prio":"dstnat"}}]}`,
+		tableName, chainName,
+	)
+
+	ctype, hook, prio := nft.TypeNAT, nft.HookPreRouting, -100
+	chain := nft.ChainWithPriorityName(nft.NewChain(table, chainName, &ctype, &hook, &prio, nil), nft.PriorityNameDstNAT)
+
+	t.Run("Chain with a priority name, check serialization", func(t *testing.T) {
+		config := nft.NewConfig()
+		config.AddChain(chain)
+
+		serialized, err := config.ToJSON()
+		assert.NoError(t, err)
+		assert.Equal(t, serializedConfig, string(serialized))
+	})
+
+	t.Run("Chain with a priority name, check deserialization", func(t *testing.T) {
+		var deserializedConfig nft.Config
+		assert.NoError(t, json.Unmarshal([]byte(serializedConfig), &deserializedConfig))
+
+		expectedConfig := nft.NewConfig()
+		expectedConfig.AddChain(chain)
+		assert.Equal(t, expectedConfig, &deserializedConfig)
+		assert.Nil(t, deserializedConfig.Nftables[0].Chain.Prio)
+	})
+
+	t.Run("Lookup a chain by its priority name", func(t *testing.T) {
+		config := nft.NewConfig()
+		config.AddChain(chain)
+
+		toFind := *chain
+		assert.Equal(t, chain, config.LookupChain(&toFind))
+		toFind.PrioName = schema.PrioNameSrcNAT
+		assert.Nil(t, config.LookupChain(&toFind))
+	})
+
+	t.Run("Read chain with an invalid priority", func(t *testing.T) {
+		var deserializedChain schema.Chain
+		assert.Error(t, json.Unmarshal([]byte(`{"family":"ip","table":"t","name":"c","prio":[0]}`), &deserializedChain))
+	})
+}
+
+func testChainPriorityConstants(t *testing.T) {
+	t.Run("Base chain with a priority constant", func(t *testing.T) {
+		table := nft.NewTable(tableName, nft.FamilyIP)
+		ctype, hook, prio := nft.TypeNAT, nft.HookPostRouting, nft.PriorityNATSource
+		config := nft.NewConfig()
+		config.AddChain(nft.NewChain(table, chainName, &ctype, &hook, &prio, nil))
+
+		serialized, err := config.ToJSON()
+		assert.NoError(t, err)
+		expected := fmt.Sprintf(
+			`{"nftables":[{"chain":{"family":"ip","table":%q,"name":%q,"type":"nat","hook":"postrouting","prio":100}}]}`,
+			tableName, chainName,
+		)
+		assert.Equal(t, expected, string(serialized))
+	})
+}
+
+func testRenameChain(t *testing.T) {
+	table := nft.NewTable(tableName, nft.FamilyIP)
+	chain := nft.NewRegularChain(table, chainName)
+	chain.Comment = "blue"
+
+	serializedConfig := fmt.Sprintf(
+		`{"nftables":[{"rename":{"chain":{"family":"ip","table":%q,"name":%q,"newname":"test-chain-green"}}}]}`,
+		tableName, chainName,
+	)
+
+	t.Run("Rename chain, check serialization", func(t *testing.T) {
+		config := nft.NewConfig()
+		config.RenameChain(chain, "test-chain-green")
+
+		serialized, err := config.ToJSON()
+		assert.NoError(t, err)
+		assert.Equal(t, serializedConfig, string(serialized))
+		assert.Empty(t, chain.NewName)
+	})
+
+	t.Run("Rename chain, check deserialization", func(t *testing.T) {
+		config := nft.NewConfig()
+		assert.NoError(t, config.FromJSON([]byte(serializedConfig)))
+		assert.Equal(t, "test-chain-green", config.Nftables[0].Rename.Chain.NewName)
+	})
+}
+
+func testChainsWildcardLookup(t *testing.T) {
+	ipTable := nft.NewTable(tableName, nft.FamilyIP)
+	ip6Table := nft.NewTable(tableName, nft.FamilyIP6)
+	ipChain := nft.NewRegularChain(ipTable, chainName)
+	ip6Chain := nft.NewRegularChain(ip6Table, chainName)
+	ctype, hook, prio := nft.TypeFilter, nft.HookInput, 0
+	ip6BaseChain := nft.NewChain(ip6Table, "input", &ctype, &hook, &prio, nil)
+	config := nft.NewConfig()
+	config.AddChain(ipChain)
+	config.AddChain(ip6Chain)
+	config.AddChain(ip6BaseChain)
+
+	t.Run("Lookup chains by name in any family", func(t *testing.T) {
+		assert.Equal(t, []*schema.Chain{ipChain, ip6Chain}, config.LookupChains(&schema.Chain{Name: chainName}))
+	})
+
+	t.Run("Lookup chains by table", func(t *testing.T) {
+		chains := config.LookupChains(&schema.Chain{Family: string(nft.FamilyIP6), Table: tableName})
+		assert.Equal(t, []*schema.Chain{ip6Chain, ip6BaseChain}, chains)
+	})
+
+	t.Run("Lookup base chains by hook", func(t *testing.T) {
+		assert.Equal(t, []*schema.Chain{ip6BaseChain}, config.LookupChains(&schema.Chain{Hook: string(nft.HookInput)}))
+	})
+}
+
+func testRemoveChain(t *testing.T) {
+	table := nft.NewTable(tableName, nft.FamilyIP)
+	chain := nft.NewRegularChain(table, chainName)
+	otherChain := nft.NewRegularChain(table, "other-chain")
+	otherRule := nft.NewRule(table, otherChain, []schema.Statement{{Verdict: schema.Drop()}}, nil, nil, "")
+	config := nft.NewConfig()
+	config.AddTable(table)
+	config.AddChain(chain)
+	config.AddChain(otherChain)
+	config.AddRule(nft.NewRule(table, chain, []schema.Statement{{Verdict: schema.Accept()}}, nil, nil, ""))
+	config.AddRule(otherRule)
+
+	t.Run("Remove a chain", func(t *testing.T) {
+		assert.True(t, config.RemoveChain(chain))
+
+		expected := nft.NewConfig()
+		expected.AddTable(table)
+		expected.AddChain(otherChain)
+		expected.AddRule(otherRule)
+		assert.Equal(t, expected, config)
+	})
+
+	t.Run("Remove a missing chain", func(t *testing.T) {
+		assert.False(t, config.RemoveChain(chain))
+	})
+}
+
+func testEnsureChain(t *testing.T) {
+	table := nft.NewTable(tableName, nft.FamilyIP)
+	ctype, hook, prio := nft.TypeFilter, nft.HookInput, 0
+	config := nft.NewConfig()
+
+	t.Run("Ensure a chain", func(t *testing.T) {
+		assert.True(t, config.EnsureChain(nft.NewChain(table, chainName, &ctype, &hook, &prio, nil)))
+		assert.False(t, config.EnsureChain(nft.NewChain(table, chainName, &ctype, &hook, &prio, nil)))
+		assert.Len(t, config.Nftables, 1)
+	})
+
+	t.Run("Ensure a chain with a different priority", func(t *testing.T) {
+		otherPrio := 10
+		assert.True(t, config.EnsureChain(nft.NewChain(table, chainName, &ctype, &hook, &otherPrio, nil)))
+		assert.Len(t, config.Nftables, 2)
+	})
+}
@@ -0,0 +1,235 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+// ErrReadOnly is returned by the mutating operations of a read-only client.
+var ErrReadOnly = errors.New("nft: the client is read-only")
+
+// ErrRawCommands is returned by a strict client when applying a config which includes raw commands.
+var ErrRawCommands = errors.New("nft: the config includes raw commands")
+
+// Client executes the nftables operations on the system, according to its options.
+// The package level operations (e.g. ReadConfig and ApplyConfig) are equivalent to
+// the operations of a client with no options.
+type Client struct {
+	readOnly          bool
+	strictRawCommands bool
+	executor          executor
+}
+
+// ClientOption configures a client.
+type ClientOption func(*Client)
+
+// NewClient returns a new client, configured with the given options.
+func NewClient(options ...ClientOption) *Client {
+	c := &Client{}
+	for _, option := range options {
+		option(c)
+	}
+	return c
+}
+
+// WithReadOnly configures the client to be read-only: the mutating operations
+// fail with ErrReadOnly while the read operations are executed as usual.
+// It allows running the same code in dry-run or staging environments, without changing the system ruleset.
+func WithReadOnly() ClientOption {
+	return func(c *Client) {
+		c.readOnly = true
+	}
+}
+
+// WithStrictRawCommands configures the client to refuse applying configs which include raw commands
+// (see AddRawCommand): the apply operations fail with ErrRawCommands, listing the raw commands
+// (see RawCommandWarnings), without executing `nft`.
+func WithStrictRawCommands() ClientOption {
+	return func(c *Client) {
+		c.strictRawCommands = true
+	}
+}
+
+// WithExecutable configures the client to run the `nft` executable at the given path,
+// instead of the one found in the PATH.
+// It is commonly needed in containers which ship the executable at a non-default location.
+func WithExecutable(path string) ClientOption {
+	return func(c *Client) {
+		c.executor.path = path
+	}
+}
+
+// WithExtraArgs configures the client to pass the given arguments to every `nft` execution,
+// preceding the operation arguments (e.g. `--includepath` or `--optimize`).
+func WithExtraArgs(args ...string) ClientOption {
+	return func(c *Client) {
+		c.executor.args = append(c.executor.args, args...)
+	}
+}
+
+// WithEnv configures the client to add the given environment variables (in the `KEY=value` form)
+// to the process environment of every `nft` execution.
+func WithEnv(env ...string) ClientOption {
+	return func(c *Client) {
+		c.executor.env = append(c.executor.env, env...)
+	}
+}
+
+// WithNetNS configures the client to execute the operations in the given network namespace,
+// given by its path (see ReadConfigInNetNS).
+func WithNetNS(nsPath string) ClientOption {
+	return func(c *Client) {
+		c.executor = *c.executor.inNetNS(nsPath)
+	}
+}
+
+// ReadConfig loads the nftables configuration from the system (see ReadConfig).
+func (c *Client) ReadConfig() (*Config, error) {
+	return c.ReadConfigContext(context.Background())
+}
+
+// ReadConfigContext loads the nftables configuration from the system (see ReadConfigContext).
+func (c *Client) ReadConfigContext(ctx context.Context) (*Config, error) {
+	return c.executor.readConfig(ctx, cmdRuleset)
+}
+
+// ReadSets loads the sets of the given table from the system (see ReadSets).
+func (c *Client) ReadSets(table *schema.Table) (*Config, error) {
+	return c.executor.readConfig(context.Background(), cmdSets, cmdTable, table.Family, table.Name)
+}
+
+// ReadCounters loads the named counters of the given table from the system (see ReadCounters).
+func (c *Client) ReadCounters(table *schema.Table) (*Config, error) {
+	return c.executor.readConfig(context.Background(), cmdCounters, cmdTable, table.Family, table.Name)
+}
+
+// ReadSetElements lists the given set from the system and returns an iterator over its elements
+// (see ReadSetElements).
+func (c *Client) ReadSetElements(set *schema.Set) (*SetElementIterator, error) {
+	return c.executor.readSetElements(set)
+}
+
+// ReadRuleCountersByComment reads the given table from the system and returns its rule counter values,
+// keyed by the rule comment (see ReadRuleCountersByComment).
+func (c *Client) ReadRuleCountersByComment(table *schema.Table) (map[string]CounterValue, error) {
+	return c.executor.readRuleCountersByComment(table)
+}
+
+// ConntrackAvailable probes the system for connection tracking support (see ConntrackAvailable).
+// The probe only checks a transaction, without applying it, therefore it is allowed in read-only mode.
+func (c *Client) ConntrackAvailable() (bool, error) {
+	return c.executor.conntrackAvailable()
+}
+
+// ApplyConfig applies the given nftables config on the system (see ApplyConfig).
+func (c *Client) ApplyConfig(config *Config) error {
+	return c.ApplyConfigContext(context.Background(), config)
+}
+
+// ApplyConfigContext applies the given nftables config on the system (see ApplyConfigContext).
+func (c *Client) ApplyConfigContext(ctx context.Context, config *Config) error {
+	if c.readOnly {
+		return ErrReadOnly
+	}
+	if err := c.checkRawCommands(config); err != nil {
+		return err
+	}
+	return c.executor.applyConfig(ctx, config)
+}
+
+// ApplyConfigResult applies the given nftables config on the system and returns the details
+// of the application (see ApplyConfigResult).
+func (c *Client) ApplyConfigResult(config *Config) (*ApplyResult, error) {
+	if c.readOnly {
+		return nil, ErrReadOnly
+	}
+	if err := c.checkRawCommands(config); err != nil {
+		return nil, err
+	}
+	return c.executor.applyConfigResult(config)
+}
+
+// ApplyConfigEcho applies the given nftables config on the system and returns the applied commands
+// with the kernel assigned handles populated (see ApplyConfigEcho).
+func (c *Client) ApplyConfigEcho(config *Config) (*Config, error) {
+	return c.ApplyConfigEchoContext(context.Background(), config)
+}
+
+// ApplyConfigEchoContext applies the given nftables config on the system and returns the applied commands
+// with the kernel assigned handles populated (see ApplyConfigEchoContext).
+func (c *Client) ApplyConfigEchoContext(ctx context.Context, config *Config) (*Config, error) {
+	if c.readOnly {
+		return nil, ErrReadOnly
+	}
+	if err := c.checkRawCommands(config); err != nil {
+		return nil, err
+	}
+	return c.executor.applyConfigEcho(ctx, config)
+}
+
+// DeleteRulesWhere deletes from the system the rules of the given table and chain which satisfy
+// the predicate (see DeleteRulesWhere).
+func (c *Client) DeleteRulesWhere(table *schema.Table, chain *schema.Chain, predicate func(*schema.Rule) bool) (int, error) {
+	if c.readOnly {
+		return 0, ErrReadOnly
+	}
+	return c.executor.deleteRulesWhere(table, chain, predicate)
+}
+
+// ResetCounters resets the named counters of the given table on the system and returns their values
+// before the reset (see ResetCounters).
+func (c *Client) ResetCounters(table *schema.Table) (*Config, error) {
+	if c.readOnly {
+		return nil, ErrReadOnly
+	}
+	return c.executor.resetConfig(cmdCounters, cmdTable, table.Family, table.Name)
+}
+
+// ResetQuotas resets the named quotas of the given table on the system and returns their values
+// before the reset (see ResetQuotas).
+func (c *Client) ResetQuotas(table *schema.Table) (*Config, error) {
+	if c.readOnly {
+		return nil, ErrReadOnly
+	}
+	return c.executor.resetConfig(cmdQuotas, cmdTable, table.Family, table.Name)
+}
+
+// NewRulesetWatcher returns a watcher of the ruleset which the client manages (see RulesetWatcher).
+func (c *Client) NewRulesetWatcher() *RulesetWatcher {
+	executor := c.executor
+	return &RulesetWatcher{executor: &executor}
+}
+
+// checkRawCommands returns an error listing the raw commands of the config, when the client is strict.
+func (c *Client) checkRawCommands(config *Config) error {
+	if !c.strictRawCommands {
+		return nil
+	}
+	if warnings := config.RawCommandWarnings(); len(warnings) > 0 {
+		return fmt.Errorf("%w: %s", ErrRawCommands, strings.Join(warnings, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/networkplumbing/go-nft/nft"
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+func TestReadOnlyClient(t *testing.T) {
+	client := nft.NewClient(nft.WithReadOnly())
+	config := nft.NewConfig()
+	table := nft.NewTable(tableName, nft.FamilyIP)
+	config.AddTable(table)
+
+	t.Run("Apply config", func(t *testing.T) {
+		assert.True(t, errors.Is(client.ApplyConfig(config), nft.ErrReadOnly))
+	})
+
+	t.Run("Apply config with result", func(t *testing.T) {
+		result, err := client.ApplyConfigResult(config)
+		assert.True(t, errors.Is(err, nft.ErrReadOnly))
+		assert.Nil(t, result)
+	})
+
+	t.Run("Apply config with echo", func(t *testing.T) {
+		echoed, err := client.ApplyConfigEcho(config)
+		assert.True(t, errors.Is(err, nft.ErrReadOnly))
+		assert.Nil(t, echoed)
+	})
+
+	t.Run("Delete rules", func(t *testing.T) {
+		deleted, err := client.DeleteRulesWhere(table, nil, func(*schema.Rule) bool { return true })
+		assert.True(t, errors.Is(err, nft.ErrReadOnly))
+		assert.Zero(t, deleted)
+	})
+
+	t.Run("Reset counters and quotas", func(t *testing.T) {
+		counters, err := client.ResetCounters(table)
+		assert.True(t, errors.Is(err, nft.ErrReadOnly))
+		assert.Nil(t, counters)
+
+		quotas, err := client.ResetQuotas(table)
+		assert.True(t, errors.Is(err, nft.ErrReadOnly))
+		assert.Nil(t, quotas)
+	})
+}
+
+// fakeExecScript reports its arguments and environment in the listed metainfo.
+const fakeExecScript = `#!/bin/sh
+echo "{\"nftables\":[{\"metainfo\":{\"version\":\"$FAKE_NFT_VERSION\",\"release_name\":\"$*\"}}]}"
+`
+
+func TestClientExecOptions(t *testing.T) {
+	executable := filepath.Join(t.TempDir(), "fake-nft")
+	assert.NoError(t, os.WriteFile(executable, []byte(fakeExecScript), 0o755))
+
+	client := nft.NewClient(
+		nft.WithExecutable(executable),
+		nft.WithExtraArgs("--numeric"),
+		nft.WithEnv("FAKE_NFT_VERSION=v9.9.9"),
+	)
+	config, err := client.ReadConfig()
+	assert.NoError(t, err)
+
+	expected := nft.NewConfig()
+	expected.Nftables = append(expected.Nftables, schema.Nftable{Metainfo: &schema.Metainfo{
+		Version:     "v9.9.9",
+		ReleaseName: "--numeric -j list ruleset",
+	}})
+	assert.Equal(t, expected, config)
+}
+
+func TestClientNetNS(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "nsenter"), []byte(fakeExecScript), 0o755))
+	path := os.Getenv("PATH")
+	assert.NoError(t, os.Setenv("PATH", dir+string(os.PathListSeparator)+path))
+	defer os.Setenv("PATH", path)
+
+	client := nft.NewClient(
+		nft.WithExtraArgs("--numeric"),
+		nft.WithNetNS("/var/run/netns/test"),
+		nft.WithEnv("FAKE_NFT_VERSION=v9.9.9"),
+	)
+	config, err := client.ReadConfig()
+	assert.NoError(t, err)
+
+	expected := nft.NewConfig()
+	expected.Nftables = append(expected.Nftables, schema.Nftable{Metainfo: &schema.Metainfo{
+		Version:     "v9.9.9",
+		ReleaseName: "--net=/var/run/netns/test -- nft --numeric -j list ruleset",
+	}})
+	assert.Equal(t, expected, config)
+}
+
+func TestClientApplyConfigEcho(t *testing.T) {
+	// The fake nft executable echoes the given entries with their assigned handles, as nft does,
+	// when requested by the arguments.
+	executable := filepath.Join(t.TempDir(), "fake-nft")
+	script := `#!/bin/sh
+cat > /dev/null
+[ "$*" = "-j --echo --handle -f -" ] || exit 1
+echo '{"nftables":[{"metainfo":{"version":"1.0.2","release_name":"Lester Gooch #3","json_schema_version":1}},` +
+		`{"table":{"family":"ip","name":"test-table","handle":3}},` +
+		`{"rule":{"family":"ip","table":"test-table","chain":"test-chain","expr":[{"accept":null}],"handle":7}}]}'
+`
+	assert.NoError(t, os.WriteFile(executable, []byte(script), 0o755))
+
+	table := nft.NewTable(tableName, nft.FamilyIP)
+	chain := nft.NewRegularChain(table, chainName)
+	config := nft.NewConfig()
+	config.AddTable(table)
+	config.AddRule(nft.NewRule(table, chain, []schema.Statement{nft.Accept()}, nil, nil, ""))
+
+	echoed, err := nft.NewClient(nft.WithExecutable(executable)).ApplyConfigEchoContext(context.Background(), config)
+	assert.NoError(t, err)
+
+	tableHandle, ruleHandle := 3, 7
+	expectedTable := nft.NewTable(tableName, nft.FamilyIP)
+	expectedTable.Handle = &tableHandle
+	expected := nft.NewConfig()
+	expected.AddTable(expectedTable)
+	expected.AddRule(nft.NewRule(table, chain, []schema.Statement{nft.Accept()}, &ruleHandle, nil, ""))
+	assert.Equal(t, expected, echoed, "The echoed entries are at the position of the given entries")
+	assert.Nil(t, config.Nftables[1].Rule.Handle, "The given config is not mutated")
+}
+
+func TestClientReadOptions(t *testing.T) {
+	// The fake nft executable answers only when given the client extra arguments.
+	executable := filepath.Join(t.TempDir(), "fake-nft")
+	script := `#!/bin/sh
+cat > /dev/null
+case "$*" in
+"--numeric -c -j -f -") ;;
+"--numeric -j list set ip test-table test-set")
+	echo '{"nftables":[{"set":{"family":"ip","table":"test-table","name":"test-set","type":"ipv4_addr","elem":["10.0.0.1"]}}]}' ;;
+"--numeric -j list table ip test-table")
+	echo '{"nftables":[{"rule":{"family":"ip","table":"test-table","chain":"test-chain","comment":"a","expr":[{"counter":{"packets":1,"bytes":2}}]}}]}' ;;
+*) exit 1 ;;
+esac
+`
+	assert.NoError(t, os.WriteFile(executable, []byte(script), 0o755))
+	client := nft.NewClient(nft.WithExecutable(executable), nft.WithExtraArgs("--numeric"), nft.WithReadOnly())
+	table := nft.NewTable(tableName, nft.FamilyIP)
+
+	t.Run("Probe conntrack", func(t *testing.T) {
+		available, err := client.ConntrackAvailable()
+		assert.NoError(t, err)
+		assert.True(t, available)
+	})
+
+	t.Run("Read set elements", func(t *testing.T) {
+		elements, err := client.ReadSetElements(nft.NewSet(table, setName, nft.SetTypeIPv4Addr, nil, nil))
+		assert.NoError(t, err)
+		page, err := elements.Next(10)
+		assert.NoError(t, err)
+		assert.NoError(t, elements.Close())
+
+		address := "10.0.0.1"
+		assert.Equal(t, []schema.Expression{{String: &address}}, page)
+	})
+
+	t.Run("Read rule counters by comment", func(t *testing.T) {
+		counters, err := client.ReadRuleCountersByComment(table)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]nft.CounterValue{"a": {Packets: 1, Bytes: 2}}, counters)
+	})
+}
+
+func TestClientApplyConfigContext(t *testing.T) {
+	executable := filepath.Join(t.TempDir(), "fake-nft")
+	assert.NoError(t, os.WriteFile(executable, []byte("#!/bin/sh\nexec sleep 10\n"), 0o755))
+	client := nft.NewClient(nft.WithExecutable(executable))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err := client.ApplyConfigContext(ctx, nft.NewConfig())
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), err)
+}
+
+func TestClientApplyConfigResultVersion(t *testing.T) {
+	// The fake nft executable records each version query.
+	dir := t.TempDir()
+	executable, queries := filepath.Join(dir, "fake-nft"), filepath.Join(dir, "version-queries")
+	script := `#!/bin/sh
+if [ "$1" = "--version" ]; then
+	echo query >> ` + queries + `
+	echo "nftables v9.9.9 (Fake)"
+	exit 0
+fi
+cat > /dev/null
+`
+	assert.NoError(t, os.WriteFile(executable, []byte(script), 0o755))
+	client := nft.NewClient(nft.WithExecutable(executable))
+
+	for i := 0; i < 2; i++ {
+		result, err := client.ApplyConfigResult(nft.NewConfig())
+		assert.NoError(t, err)
+		assert.Equal(t, "v9.9.9", result.NftVersion)
+	}
+
+	recorded, err := os.ReadFile(queries)
+	assert.NoError(t, err)
+	assert.Equal(t, "query\n", string(recorded), "The version is read once")
+}
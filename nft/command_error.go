@@ -0,0 +1,162 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+// nftErrorMessage matches the message of an error reported by nft (e.g. `internal:0:0-0: Error: ...`).
+var nftErrorMessage = regexp.MustCompile(`Error: (.*)$`)
+
+// CommandError describes the failure of a config command (entry), as reported by nft.
+type CommandError struct {
+	// Index is the index of the failed command in the config.
+	Index int
+	// Command is the failed command.
+	Command schema.Nftable
+	// Message is the error message reported by nft.
+	Message string
+}
+
+func (e CommandError) Error() string {
+	return fmt.Sprintf("command %d (%s): %s", e.Index, commandSummary(e.Command), e.Message)
+}
+
+// ApplyError is returned when applying a config fails, identifying the first failed command.
+// nft does not locate the errors of a JSON input (it reports them at `internal:0:0-0`),
+// therefore the failed command is found by checking (`nft -c`) growing prefixes of the config,
+// the first failing prefix ending with the failed command.
+// A failed transaction is not applied at all, therefore the failed command can be dropped
+// from the config (e.g. using the command index) and the config applied again,
+// which may then report the next failed command.
+type ApplyError struct {
+	// Commands lists the failed commands, ordered by their index.
+	// Only the first failed command is identified, later commands are not checked.
+	Commands []CommandError
+	// Err is the error of the nft execution.
+	Err error
+}
+
+func (e *ApplyError) Error() string {
+	messages := make([]string, 0, len(e.Commands))
+	for _, command := range e.Commands {
+		messages = append(messages, command.Error())
+	}
+	return fmt.Sprintf("failed commands: %s: %v", strings.Join(messages, "; "), e.Err)
+}
+
+func (e *ApplyError) Unwrap() error {
+	return e.Err
+}
+
+// applyError returns the apply error of the first failed command of the config,
+// or the execution error when the failed command cannot be identified,
+// e.g. when nft reported nothing, the execution was killed or the config passes the check.
+func (e *executor) applyError(ctx context.Context, c *Config, stderr *bytes.Buffer, err error) error {
+	if stderr == nil || stderr.Len() == 0 || ctx.Err() != nil {
+		return err
+	}
+	commandError, found := e.firstFailedCommand(ctx, c)
+	if !found {
+		return err
+	}
+	return &ApplyError{Commands: []CommandError{commandError}, Err: err}
+}
+
+// firstFailedCommand finds the first command of the config which fails the check,
+// by a binary search over the config prefixes: once a prefix fails, all the longer prefixes fail.
+func (e *executor) firstFailedCommand(ctx context.Context, c *Config) (CommandError, bool) {
+	var commandError CommandError
+	found := false
+	low, high := 0, len(c.Nftables)-1
+	for low <= high {
+		middle := (low + high) / 2
+		message, failed := e.checkCommands(ctx, c.Nftables[:middle+1])
+		if failed {
+			commandError = CommandError{Index: middle, Command: c.Nftables[middle], Message: message}
+			found = true
+			high = middle - 1
+		} else {
+			low = middle + 1
+		}
+	}
+	return commandError, found
+}
+
+// checkCommands checks the commands with `nft -c`, without applying them,
+// and returns the error message reported by nft when the check fails.
+func (e *executor) checkCommands(ctx context.Context, commands []schema.Nftable) (string, bool) {
+	data, err := (&Config{schema.Root{Nftables: commands}}).ToJSON()
+	if err != nil {
+		return err.Error(), true
+	}
+	_, stderr, err := e.runCommand(ctx, data, cmdCheck, cmdJSON, cmdFile, cmdStdin)
+	if err == nil {
+		return "", false
+	}
+	for _, line := range strings.Split(stderr.String(), "\n") {
+		if match := nftErrorMessage.FindStringSubmatch(strings.TrimSpace(line)); match != nil {
+			return match[1], true
+		}
+	}
+	if message := strings.TrimSpace(stderr.String()); message != "" {
+		return message, true
+	}
+	return err.Error(), true
+}
+
+// commandSummary returns a short description of the command, its action and object
+// (e.g. `delete rule ip mytable mychain`).
+func commandSummary(nftable schema.Nftable) string {
+	data, err := json.Marshal(nftable)
+	if err != nil {
+		return "unknown command"
+	}
+
+	// Descend through the action and object kind keys, up to the object fields.
+	var words []string
+	var object map[string]json.RawMessage
+	for {
+		object = nil
+		if err := json.Unmarshal(data, &object); err != nil || len(object) != 1 {
+			break
+		}
+		for key, value := range object {
+			words = append(words, key)
+			data = value
+		}
+	}
+
+	for _, field := range []string{"family", "table", "chain", "name"} {
+		var value string
+		if raw, exists := object[field]; exists && json.Unmarshal(raw, &value) == nil {
+			words = append(words, value)
+		}
+	}
+	return strings.Join(words, " ")
+}
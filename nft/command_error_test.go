@@ -0,0 +1,89 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/networkplumbing/go-nft/nft"
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+func TestCommandErrors(t *testing.T) {
+	config := nft.NewConfig()
+	table := nft.NewTable(tableName, nft.FamilyIP)
+	chain := nft.NewRegularChain(table, chainName)
+	config.AddTable(table)
+	config.AddRule(nft.NewRule(table, chain, []schema.Statement{{Verdict: schema.Accept()}}, nil, nil, ""))
+	config.DeleteTable(nft.NewTable("missing-table", nft.FamilyIP6))
+	config.DeleteTable(nft.NewTable("other-missing-table", nft.FamilyIP6))
+
+	// The fake nft rejects any input which deletes a missing table, locating its errors as nft does for a JSON input.
+	fakeNft := func(t *testing.T, checkScript string) string {
+		executable := filepath.Join(t.TempDir(), "fake-nft")
+		script := "#!/bin/sh\ninput=$(cat)\n" +
+			"if [ \"$1\" = \"-c\" ]; then\n" + checkScript + "fi\n" +
+			"echo 'internal:0:0-0: Error: Could not process rule: No such file or directory' >&2\nexit 1\n"
+		assert.NoError(t, os.WriteFile(executable, []byte(script), 0o755))
+		return executable
+	}
+
+	t.Run("Identify the first failed command by checking the config prefixes", func(t *testing.T) {
+		executable := fakeNft(t, "case \"$input\" in *missing-table*)\n"+
+			"echo 'internal:0:0-0: Error: Could not process rule: No such file or directory' >&2\nexit 1;;\nesac\nexit 0\n")
+
+		err := nft.NewClient(nft.WithExecutable(executable)).ApplyConfig(config)
+		var applyErr *nft.ApplyError
+		assert.True(t, errors.As(err, &applyErr), err)
+		assert.Len(t, applyErr.Commands, 1)
+
+		assert.Equal(t, 2, applyErr.Commands[0].Index)
+		assert.Equal(t, config.Nftables[2], applyErr.Commands[0].Command)
+		assert.EqualError(t, applyErr.Commands[0],
+			"command 2 (delete table ip6 missing-table): Could not process rule: No such file or directory",
+		)
+		assert.Contains(t, err.Error(), "failed commands: command 2 (delete table ip6 missing-table): ")
+	})
+
+	t.Run("Apply error wraps the execution error", func(t *testing.T) {
+		execErr := errors.New("exit status 1")
+		var err error = &nft.ApplyError{
+			Commands: []nft.CommandError{{Index: 1, Command: config.Nftables[1], Message: "Could not process rule"}},
+			Err:      execErr,
+		}
+
+		assert.EqualError(t, err, "failed commands: command 1 (rule ip test-table test-chain): Could not process rule: exit status 1")
+		assert.True(t, errors.Is(err, execErr))
+	})
+
+	t.Run("Execution error when the config passes the check", func(t *testing.T) {
+		executable := fakeNft(t, "exit 0\n")
+
+		err := nft.NewClient(nft.WithExecutable(executable)).ApplyConfig(config)
+		assert.Error(t, err)
+		var applyErr *nft.ApplyError
+		assert.False(t, errors.As(err, &applyErr), err)
+	})
+}
@@ -0,0 +1,49 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft
+
+import (
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+// TableWithComment sets the comment of the given table and returns it,
+// e.g. `nft.TableWithComment(nft.NewTable("app", nft.FamilyINET), "managed by app")`.
+func TableWithComment(table *schema.Table, comment string) *schema.Table {
+	table.Comment = comment
+	return table
+}
+
+// ChainWithComment sets the comment of the given chain and returns it.
+func ChainWithComment(chain *schema.Chain, comment string) *schema.Chain {
+	chain.Comment = comment
+	return chain
+}
+
+// SetWithComment sets the comment of the given set and returns it.
+func SetWithComment(set *schema.Set, comment string) *schema.Set {
+	set.Comment = comment
+	return set
+}
+
+// MapWithComment sets the comment of the given map and returns it.
+func MapWithComment(m *schema.Map, comment string) *schema.Map {
+	m.Comment = comment
+	return m
+}
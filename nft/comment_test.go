@@ -0,0 +1,56 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft_test
+
+import (
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/networkplumbing/go-nft/nft"
+)
+
+func TestComments(t *testing.T) {
+	const serializedConfig = `{"nftables":[` +
+		`{"table":{"family":"inet","name":"test-table","comment":"table comment"}},` +
+		`{"chain":{"family":"inet","table":"test-table","name":"test-chain","comment":"chain comment"}},` +
+		`{"set":{"family":"inet","table":"test-table","name":"test-set","type":"ipv4_addr","comment":"set comment"}},` +
+		`{"map":{"family":"inet","table":"test-table","name":"test-map","type":"ipv4_addr","map":"mark","comment":"map comment"}}` +
+		`]}`
+
+	table := nft.TableWithComment(nft.NewTable(tableName, nft.FamilyINET), "table comment")
+	config := nft.NewConfig()
+	config.AddTable(table)
+	config.AddChain(nft.ChainWithComment(nft.NewRegularChain(table, chainName), "chain comment"))
+	config.AddSet(nft.SetWithComment(nft.NewSet(table, setName, nft.SetTypeIPv4Addr, nil, nil), "set comment"))
+	config.AddMap(nft.MapWithComment(nft.NewMap(table, mapName, nft.SetTypeIPv4Addr, nft.SetTypeMark, nil, nil), "map comment"))
+
+	t.Run("Comments, check serialization", func(t *testing.T) {
+		serialized, err := config.ToJSON()
+		assert.NoError(t, err)
+		assert.Equal(t, serializedConfig, string(serialized))
+	})
+
+	t.Run("Comments, check deserialization", func(t *testing.T) {
+		deserializedConfig := nft.NewConfig()
+		assert.NoError(t, deserializedConfig.FromJSON([]byte(serializedConfig)))
+		assert.Equal(t, config, deserializedConfig)
+	})
+}
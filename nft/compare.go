@@ -0,0 +1,141 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+// ListingOrderEqual reports if the configs are equivalent, regardless of the order differences
+// between a listed ruleset and a config which declares the same entries.
+// When listing the ruleset, nft groups the entries per table and the rules per chain,
+// while a config lists its entries in the order they have been added.
+// Therefore, only the relative order of the rules in each chain is significant.
+// Entries which are specific to a listing are ignored as well: the metainfo entries,
+// the object handles and the counter values.
+func (c *Config) ListingOrderEqual(other *Config) bool {
+	keys, otherKeys := listingOrderKeys(c), listingOrderKeys(other)
+	if len(keys) != len(otherKeys) {
+		return false
+	}
+	for i := range keys {
+		if keys[i] != otherKeys[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// listingOrderKeys returns the entry keys of the config in a canonical order:
+// the (sorted) keys of the non rule entries, followed by the keys of the rules
+// grouped by their chains (in the chains order) and keeping the rules order in each chain.
+func listingOrderKeys(config *Config) []string {
+	var keys []string
+	rulesByChain := map[string][]string{}
+	for _, nftable := range config.Nftables {
+		if nftable.Metainfo != nil {
+			continue
+		}
+		key := entryKey(withoutHandles(nftable))
+		if rule := nftable.Rule; rule != nil {
+			chainKey := rule.Family + " " + rule.Table + " " + rule.Chain
+			rulesByChain[chainKey] = append(rulesByChain[chainKey], key)
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	chainKeys := make([]string, 0, len(rulesByChain))
+	for chainKey := range rulesByChain {
+		chainKeys = append(chainKeys, chainKey)
+	}
+	sort.Strings(chainKeys)
+	for _, chainKey := range chainKeys {
+		keys = append(keys, chainKey)
+		keys = append(keys, rulesByChain[chainKey]...)
+	}
+	return keys
+}
+
+// withoutHandles returns a copy of the entry, excluding the handle of its object.
+func withoutHandles(nftable schema.Nftable) schema.Nftable {
+	switch {
+	case nftable.Table != nil:
+		table := *nftable.Table
+		table.Handle = nil
+		nftable.Table = &table
+	case nftable.Chain != nil:
+		chain := *nftable.Chain
+		chain.Handle = nil
+		nftable.Chain = &chain
+	case nftable.Rule != nil:
+		rule := *nftable.Rule
+		rule.Handle = nil
+		nftable.Rule = &rule
+	case nftable.Set != nil:
+		set := *nftable.Set
+		set.Handle = nil
+		nftable.Set = &set
+	case nftable.Map != nil:
+		m := *nftable.Map
+		m.Handle = nil
+		nftable.Map = &m
+	case nftable.Flowtable != nil:
+		flowtable := *nftable.Flowtable
+		flowtable.Handle = nil
+		nftable.Flowtable = &flowtable
+	case nftable.Counter != nil:
+		counter := *nftable.Counter
+		counter.Handle = nil
+		nftable.Counter = &counter
+	case nftable.Limit != nil:
+		limit := *nftable.Limit
+		limit.Handle = nil
+		nftable.Limit = &limit
+	case nftable.Quota != nil:
+		quota := *nftable.Quota
+		quota.Handle = nil
+		nftable.Quota = &quota
+	case nftable.Synproxy != nil:
+		synproxy := *nftable.Synproxy
+		synproxy.Handle = nil
+		nftable.Synproxy = &synproxy
+	case nftable.CtHelper != nil:
+		ctHelper := *nftable.CtHelper
+		ctHelper.Handle = nil
+		nftable.CtHelper = &ctHelper
+	case nftable.CtTimeout != nil:
+		ctTimeout := *nftable.CtTimeout
+		ctTimeout.Handle = nil
+		nftable.CtTimeout = &ctTimeout
+	}
+	return nftable
+}
+
+// entryKey returns the identity of an entry, which is its JSON encoding excluding the stateful values
+// (see withoutStatefulValues).
+func entryKey(nftable schema.Nftable) string {
+	data, _ := json.Marshal(withoutStatefulValues(nftable))
+	return string(data)
+}
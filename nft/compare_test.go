@@ -0,0 +1,83 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft_test
+
+import (
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/networkplumbing/go-nft/nft"
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+func TestListingOrderEqual(t *testing.T) {
+	table := nft.NewTable(tableName, nft.FamilyIP)
+	input := nft.NewRegularChain(table, "input")
+	output := nft.NewRegularChain(table, "output")
+	set := nft.NewSet(table, setName, nft.SetTypeIPv4Addr, nil, nil)
+	acceptRule := func(chain *schema.Chain, comment string) *schema.Rule {
+		return nft.NewRule(table, chain, []schema.Statement{nft.NewCounter(), {Verdict: schema.Accept()}}, nil, nil, comment)
+	}
+
+	// The config declares the entries in their logical order.
+	config := nft.NewConfig()
+	config.AddTable(table)
+	config.AddChain(input)
+	config.AddRule(acceptRule(input, "first"))
+	config.AddChain(output)
+	config.AddRule(acceptRule(output, "out"))
+	config.AddRule(acceptRule(input, "second"))
+	config.AddSet(set)
+
+	// The listing groups the entries per kind and the rules per chain, with handles and counter values.
+	newListing := func(inputComments ...string) *nft.Config {
+		listing := nft.NewConfig()
+		listing.Nftables = append(listing.Nftables, schema.Nftable{Metainfo: &schema.Metainfo{Version: "1.0.2"}})
+		listing.AddTable(table)
+		listing.AddChain(input)
+		listing.AddChain(output)
+		setHandle := 3
+		listedSet := *set
+		listedSet.Handle = &setHandle
+		listing.AddSet(&listedSet)
+		for i, comment := range inputComments {
+			rule := acceptRule(input, comment)
+			handle := 10 + i
+			rule.Handle = &handle
+			rule.Expr[0].Counter = &schema.Counter{Packets: 5, Bytes: 420}
+			listing.AddRule(rule)
+		}
+		listing.AddRule(acceptRule(output, "out"))
+		return listing
+	}
+
+	t.Run("Compare a config with its listing", func(t *testing.T) {
+		assert.True(t, config.ListingOrderEqual(newListing("first", "second")))
+	})
+
+	t.Run("Compare a config with a listing of reordered chain rules", func(t *testing.T) {
+		assert.False(t, config.ListingOrderEqual(newListing("second", "first")))
+	})
+
+	t.Run("Compare a config with a listing of a missing rule", func(t *testing.T) {
+		assert.False(t, config.ListingOrderEqual(newListing("first")))
+	})
+}
@@ -0,0 +1,31 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft
+
+import (
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+// Concat returns a concatenation expression of the given expressions (e.g. `ip saddr . tcp dport`).
+// It forms compound keys for matches and for lookups in sets and maps with concatenated types,
+// in which case the set (or map) type lists the types of the concatenated keys.
+func Concat(expressions ...schema.Expression) schema.Expression {
+	return schema.Expression{Concat: expressions}
+}
@@ -0,0 +1,79 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/networkplumbing/go-nft/nft"
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+func TestConcat(t *testing.T) {
+	saddr := schema.Expression{Payload: &schema.Payload{Protocol: schema.PayloadProtocolIP4, Field: schema.PayloadFieldIPSAddr}}
+	dport := schema.Expression{Payload: &schema.Payload{Protocol: schema.PayloadProtocolTCP, Field: schema.PayloadFieldTCPDPort}}
+	address, port := "10.0.0.1", float64(22)
+
+	table := nft.NewTable(tableName, nft.FamilyIP)
+	vmap := nft.NewVerdictMap(table, mapName, nft.SetTypeIPv4Addr, []schema.MapElement{
+		nft.NewVerdictMapElement(nft.Concat(schema.Expression{String: &address}, schema.Expression{Float64: &port}), schema.Accept()),
+	})
+	vmap.Type = schema.StringList{string(nft.SetTypeIPv4Addr), string(nft.SetTypeInetService)}
+
+	tests := []struct {
+		name       string
+		value      interface{}
+		newValue   func() interface{}
+		serialized string
+	}{
+		{
+			name:       "concat lookup in a verdict map",
+			value:      nft.VerdictMapLookup(nft.Concat(saddr, dport), vmap),
+			newValue:   func() interface{} { return &schema.Statement{} },
+			serialized: `{"vmap":{"key":{"concat":[{"payload":{"protocol":"ip","field":"saddr"}},{"payload":{"protocol":"tcp","field":"dport"}}]},"data":"@test-map"}}`,
+		},
+		{
+			name:       "verdict map with a concatenated type",
+			value:      vmap,
+			newValue:   func() interface{} { return &schema.Map{} },
+			serialized: `{"family":"ip","table":"test-table","name":"test-map","type":["ipv4_addr","inet_service"],"map":"verdict","elem":[[{"concat":["10.0.0.1",22]},{"accept":null}]]}`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name+", check serialization", func(t *testing.T) {
+			serialized, err := json.Marshal(test.value)
+			assert.NoError(t, err)
+			assert.Equal(t, test.serialized, string(serialized))
+		})
+
+		t.Run(test.name+", check deserialization", func(t *testing.T) {
+			value := test.newValue()
+			assert.NoError(t, json.Unmarshal([]byte(test.serialized), value))
+			serialized, err := json.Marshal(value)
+			assert.NoError(t, err)
+			assert.Equal(t, test.serialized, string(serialized))
+		})
+	}
+}
@@ -21,6 +21,9 @@ package nft
 
 import (
 	"encoding/json"
+	"fmt"
+	"runtime"
+	"sync"
 
 	"github.com/networkplumbing/go-nft/nft/schema"
 )
@@ -36,19 +39,117 @@ func NewConfig() *Config {
 	return c
 }
 
+// Clone returns a deep copy of the nftables config.
+// Unlike the objects returned by the lookups (e.g. LookupChain), which point into the config,
+// the copy can be mutated without affecting the original config.
+func (c *Config) Clone() *Config {
+	clone := &Config{}
+	clone.Nftables = make([]schema.Nftable, len(c.Nftables))
+	for i, nftable := range c.Nftables {
+		clone.Nftables[i] = nftable.Clone()
+	}
+	return clone
+}
+
 // ToJSON returns the JSON encoding of the nftables config.
 func (c *Config) ToJSON() ([]byte, error) {
 	return json.Marshal(*c)
 }
 
+// parallelDecodeMinEntries is the minimal number of entries per decoding worker.
+// Smaller configs are decoded sequentially, as the workers overhead outweighs the gain.
+const parallelDecodeMinEntries = 256
+
 // FromJSON decodes the provided JSON-encoded data and populates the nftables config.
+// The entries of large configs (e.g. a listed ruleset) are decoded in parallel, keeping their order.
 func (c *Config) FromJSON(data []byte) error {
-	if err := json.Unmarshal(data, c); err != nil {
+	var root struct {
+		Nftables []json.RawMessage `json:"nftables"`
+	}
+	if err := json.Unmarshal(data, &root); err != nil {
 		return err
 	}
+
+	nftables := make([]schema.Nftable, len(root.Nftables))
+	if err := decodeEntries(root.Nftables, nftables); err != nil {
+		return err
+	}
+	c.Nftables = nftables
+	return nil
+}
+
+// decodeEntries decodes the raw entries into the nftables (of the same length),
+// using a worker per chunk of entries.
+// On failure, the error of the first failing entry is returned.
+func decodeEntries(entries []json.RawMessage, nftables []schema.Nftable) error {
+	workers := len(entries) / parallelDecodeMinEntries
+	if maxWorkers := runtime.GOMAXPROCS(0); workers > maxWorkers {
+		workers = maxWorkers
+	}
+	if workers < 2 {
+		return decodeEntriesChunk(entries, nftables)
+	}
+
+	chunkSize := (len(entries) + workers - 1) / workers
+	errs := make([]error, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		start, end := w*chunkSize, (w+1)*chunkSize
+		if end > len(entries) {
+			end = len(entries)
+		}
+		wg.Add(1)
+		go func(w, start, end int) {
+			defer wg.Done()
+			errs[w] = decodeEntriesChunk(entries[start:end], nftables[start:end])
+		}(w, start, end)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func decodeEntriesChunk(entries []json.RawMessage, nftables []schema.Nftable) error {
+	for i, entry := range entries {
+		if err := json.Unmarshal(entry, &nftables[i]); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// hasEquivalentObject reports if the config declares an object which is equivalent to the given one
+// (ignoring its handle and counter values).
+func (c *Config) hasEquivalentObject(object schema.Nftable) bool {
+	id, _ := objectIdentity(object)
+	key := objectKey(object)
+	for _, nftable := range c.Nftables {
+		if nftableID, isObject := objectIdentity(nftable); isObject && nftableID == id && objectKey(nftable) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// removeEntries removes the config entries which satisfy the predicate, keeping the order of the others.
+// It returns the number of removed entries.
+func (c *Config) removeEntries(predicate func(schema.Nftable) bool) int {
+	nftables := make([]schema.Nftable, 0, len(c.Nftables))
+	for _, nftable := range c.Nftables {
+		if !predicate(nftable) {
+			nftables = append(nftables, nftable)
+		}
+	}
+	removed := len(c.Nftables) - len(nftables)
+	c.Nftables = nftables
+	return removed
+}
+
 // FlushRuleset adds a command to the nftables config that erases all the configuration when applied.
 // It is commonly used as the first config instruction, followed by a declarative configuration.
 // When used, any previous configuration is flushed away before adding the new one.
@@ -57,3 +158,57 @@ func (c *Config) FromJSON(data []byte) error {
 func (c *Config) FlushRuleset() {
 	c.Nftables = append(c.Nftables, schema.Nftable{Flush: &schema.Objects{Ruleset: true}})
 }
+
+// FlushFamilyRuleset adds a command to the nftables config that erases the configuration
+// of the given family when applied, similar to FlushRuleset.
+// The tables of other families are not affected.
+func (c *Config) FlushFamilyRuleset(family AddressFamily) {
+	c.Nftables = append(c.Nftables, schema.Nftable{Flush: &schema.Objects{Ruleset: true, RulesetFamily: string(family)}})
+}
+
+// rawCommandVerbs are the command verbs (actions) of the nftables JSON schema.
+var rawCommandVerbs = map[string]bool{
+	"add": true, "create": true, "insert": true, "delete": true, "destroy": true,
+	"flush": true, "rename": true, "reset": true, "list": true, "replace": true,
+}
+
+// AddRawCommand appends the given raw command to the nftables config.
+// It is an escape hatch for commands which cannot be composed from the existing schema.
+// The command is validated to be a single well-formed command object (e.g. `{"add":{"element":{...}}}`),
+// of a known verb: add, create, insert, delete, destroy, flush, rename, reset, list or replace.
+// The command is copied, the caller may reuse its buffer.
+// Strict clients refuse to apply configs which include raw commands (see WithStrictRawCommands).
+func (c *Config) AddRawCommand(command json.RawMessage) error {
+	var dynamicStructure map[string]json.RawMessage
+	if err := json.Unmarshal(command, &dynamicStructure); err != nil {
+		return fmt.Errorf("invalid raw command: %v", err)
+	}
+	if len(dynamicStructure) != 1 {
+		return fmt.Errorf("invalid raw command: expecting a single command, found %d", len(dynamicStructure))
+	}
+	for name, value := range dynamicStructure {
+		if !rawCommandVerbs[name] {
+			return fmt.Errorf("invalid raw command %q: unknown command verb", name)
+		}
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(value, &object); err != nil || object == nil {
+			return fmt.Errorf("invalid raw command %q: expecting an object", name)
+		}
+	}
+
+	c.Nftables = append(c.Nftables, schema.Nftable{RowData: append(json.RawMessage(nil), command...)})
+	return nil
+}
+
+// RawCommandWarnings returns a warning for each raw command in the nftables config (see AddRawCommand).
+// It allows deployments to audit the usage of commands which bypass the schema,
+// strict deployments refuse them instead (see WithStrictRawCommands).
+func (c *Config) RawCommandWarnings() []string {
+	var warnings []string
+	for i, nftable := range c.Nftables {
+		if nftable.RowData != nil {
+			warnings = append(warnings, fmt.Sprintf("command %d is a raw command: %s", i, nftable.RowData))
+		}
+	}
+	return warnings
+}
@@ -20,12 +20,16 @@
 package nft_test
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"reflect"
 	"testing"
 
 	assert "github.com/stretchr/testify/require"
 
 	"github.com/networkplumbing/go-nft/nft"
+	"github.com/networkplumbing/go-nft/nft/corpus"
 	"github.com/networkplumbing/go-nft/nft/schema"
 )
 
@@ -68,4 +72,234 @@ func TestFlushRuleset(t *testing.T) {
 	serializedConfig, err := config.ToJSON()
 	assert.NoError(t, err)
 	assert.Equal(t, string(expected), string(serializedConfig))
+
+	deserializedConfig := nft.NewConfig()
+	assert.NoError(t, deserializedConfig.FromJSON(expected))
+	assert.Equal(t, config, deserializedConfig)
+
+	t.Run("Flush the ruleset of a family", func(t *testing.T) {
+		config := nft.NewConfig()
+		config.FlushFamilyRuleset(nft.FamilyIP6)
+
+		expected := []byte(`{"nftables":[{"flush":{"ruleset":{"family":"ip6"}}}]}`)
+		serializedConfig, err := config.ToJSON()
+		assert.NoError(t, err)
+		assert.Equal(t, string(expected), string(serializedConfig))
+
+		deserializedConfig := nft.NewConfig()
+		assert.NoError(t, deserializedConfig.FromJSON(expected))
+		assert.Equal(t, config, deserializedConfig)
+	})
+}
+
+func TestAddRawCommand(t *testing.T) {
+	const rawCommand = `{"add":{"element":{"family":"ip","table":"test-table","name":"myset","elem":[22]}}}`
+
+	t.Run("Add a raw command", func(t *testing.T) {
+		config := nft.NewConfig()
+		config.AddTable(nft.NewTable(tableName, nft.FamilyIP))
+		assert.NoError(t, config.AddRawCommand(json.RawMessage(rawCommand)))
+
+		expected := fmt.Sprintf(`{"nftables":[{"table":{"family":"ip","name":"test-table"}},%s]}`, rawCommand)
+		serializedConfig, err := config.ToJSON()
+		assert.NoError(t, err)
+		assert.Equal(t, expected, string(serializedConfig))
+
+		assert.Equal(t, []string{"command 1 is a raw command: " + rawCommand}, config.RawCommandWarnings())
+	})
+
+	t.Run("Add a raw command which is copied", func(t *testing.T) {
+		config := nft.NewConfig()
+		command := json.RawMessage(rawCommand)
+		assert.NoError(t, config.AddRawCommand(command))
+		copy(command, `{"flush"`)
+
+		assert.Equal(t, rawCommand, string(config.Nftables[0].RowData))
+	})
+
+	t.Run("Refuse to apply raw commands by a strict client", func(t *testing.T) {
+		config := nft.NewConfig()
+		assert.NoError(t, config.AddRawCommand(json.RawMessage(rawCommand)))
+
+		client := nft.NewClient(nft.WithStrictRawCommands(), nft.WithExecutable("/nonexistent/nft"))
+		err := client.ApplyConfig(config)
+		assert.True(t, errors.Is(err, nft.ErrRawCommands), err)
+		assert.EqualError(t, err, "nft: the config includes raw commands: command 0 is a raw command: "+rawCommand)
+
+		_, err = client.ApplyConfigResult(config)
+		assert.True(t, errors.Is(err, nft.ErrRawCommands), err)
+		_, err = client.ApplyConfigEcho(config)
+		assert.True(t, errors.Is(err, nft.ErrRawCommands), err)
+	})
+
+	invalidCommands := map[string]string{
+		"malformed JSON":       `{"add":`,
+		"not an object":        `["add"]`,
+		"multiple commands":    `{"add":{},"delete":{}}`,
+		"command with no body": `{"add":"table"}`,
+		"unknown command verb": `{"append":{"table":{"family":"ip","name":"test-table"}}}`,
+		"object with no verb":  `{"table":{"family":"ip","name":"test-table"}}`,
+	}
+	for name, command := range invalidCommands {
+		command := command
+		t.Run("Add an invalid raw command: "+name, func(t *testing.T) {
+			config := nft.NewConfig()
+			assert.Error(t, config.AddRawCommand(json.RawMessage(command)))
+			assert.Empty(t, config.Nftables)
+		})
+	}
+}
+
+func TestReadSetsAndCountersListing(t *testing.T) {
+	serializedConfig := []byte(`{"nftables":[` +
+		`{"set":{"family":"ip","table":"test-table","name":"ports","handle":3,"type":"inet_service","flags":["interval"]}},` +
+		`{"set":{"family":"ip","table":"test-table","name":"pairs","type":["ipv4_addr","inet_service"]}},` +
+		`{"counter":{"family":"ip","table":"test-table","name":"hits","handle":4,"packets":10,"bytes":840}}` +
+		`]}`)
+
+	config := nft.NewConfig()
+	assert.NoError(t, config.FromJSON(serializedConfig))
+
+	setHandle, counterHandle := 3, 4
+	expectedConfig := nft.NewConfig()
+	expectedConfig.Nftables = append(expectedConfig.Nftables,
+		schema.Nftable{Set: &schema.Set{
+			Family: schema.FamilyIP, Table: tableName, Name: "ports", Handle: &setHandle,
+			Type: schema.StringList{"inet_service"}, Flags: schema.StringList{"interval"},
+		}},
+		schema.Nftable{Set: &schema.Set{
+			Family: schema.FamilyIP, Table: tableName, Name: "pairs",
+			Type: schema.StringList{"ipv4_addr", "inet_service"},
+		}},
+		schema.Nftable{Counter: &schema.NamedCounter{
+			Family: schema.FamilyIP, Table: tableName, Name: "hits", Handle: &counterHandle, Packets: 10, Bytes: 840,
+		}},
+	)
+	assert.Equal(t, expectedConfig, config)
+}
+
+func TestReadLargeConfig(t *testing.T) {
+	const chainsCount = 2000
+
+	expectedConfig := nft.NewConfig()
+	expectedConfig.AddTable(nft.NewTable(tableName, nft.FamilyIP))
+	for i := 0; i < chainsCount; i++ {
+		expectedConfig.AddChain(nft.NewRegularChain(expectedConfig.Nftables[0].Table, fmt.Sprintf("chain%d", i)))
+	}
+	serializedConfig, err := expectedConfig.ToJSON()
+	assert.NoError(t, err)
+
+	t.Run("entries keep their order", func(t *testing.T) {
+		config := nft.NewConfig()
+		assert.NoError(t, config.FromJSON(serializedConfig))
+		assert.Equal(t, expectedConfig, config)
+	})
+
+	t.Run("invalid entry fails the decoding", func(t *testing.T) {
+		invalidConfig := []byte(string(serializedConfig[:len(serializedConfig)-2]) + `,{"chain":"bad"}]}`)
+		config := nft.NewConfig()
+		assert.Error(t, config.FromJSON(invalidConfig))
+	})
+}
+
+func TestReadIptablesNftRuleset(t *testing.T) {
+	serializedConfig := []byte(`{"nftables":[` +
+		`{"rule":{"family":"ip","table":"filter","chain":"INPUT","expr":[` +
+		`{"xt":{"type":"match","name":"conntrack","info":{"state":"ESTABLISHED"}}},` +
+		`{"counter":{"packets":0,"bytes":0}},` +
+		`{"xt":{"type":"target","name":"LOG"}}` +
+		`]}}` +
+		`]}`)
+
+	config := nft.NewConfig()
+	assert.NoError(t, config.FromJSON(serializedConfig))
+
+	expr := config.Nftables[0].Rule.Expr
+	assert.Len(t, expr, 3)
+	assert.Equal(t, &schema.Xt{Type: schema.XtTypeMatch, Name: "conntrack", Info: json.RawMessage(`{"state":"ESTABLISHED"}`)}, expr[0].Xt)
+	assert.Equal(t, &schema.Xt{Type: schema.XtTypeTarget, Name: "LOG"}, expr[2].Xt)
+
+	serialized, err := config.ToJSON()
+	assert.NoError(t, err)
+	assert.Equal(t, string(serializedConfig), string(serialized))
+}
+
+func TestCloneConfig(t *testing.T) {
+	entries, err := corpus.Entries()
+	assert.NoError(t, err)
+	for _, entry := range entries {
+		entry := entry
+		t.Run("Clone "+entry.Name, func(t *testing.T) {
+			config, err := entry.Config()
+			assert.NoError(t, err)
+
+			clone := config.Clone()
+
+			assert.Equal(t, config, clone)
+			assert.Empty(t, sharedReferences(reflect.ValueOf(config.Nftables), reflect.ValueOf(clone.Nftables)))
+		})
+	}
+
+	t.Run("Mutate a cloned rule", func(t *testing.T) {
+		table := nft.NewTable(tableName, nft.FamilyIP)
+		chain := nft.NewRegularChain(table, chainName)
+		config := nft.NewConfig()
+		iface := "eth0"
+		match := schema.Statement{Match: &schema.Match{
+			Op:    schema.OperEQ,
+			Left:  schema.Expression{Meta: &schema.Meta{Key: schema.MetaKeyIIFName}},
+			Right: schema.Expression{String: &iface},
+		}}
+		config.AddRule(nft.NewRule(table, chain, []schema.Statement{match}, nil, nil, "eth0"))
+
+		clone := config.Clone()
+		*clone.LookupRule(&schema.Rule{Family: "ip", Table: tableName, Chain: chainName})[0].Expr[0].Match.Right.String = "eth1"
+
+		assert.Equal(t, "eth0", *config.Nftables[0].Rule.Expr[0].Match.Right.String)
+	})
+
+	t.Run("Mutate a cloned forward statement", func(t *testing.T) {
+		table := nft.NewTable(tableName, nft.FamilyNETDEV)
+		chain := nft.NewRegularChain(table, chainName)
+		config := nft.NewConfig()
+		config.AddRule(nft.NewRule(table, chain, []schema.Statement{nft.ForwardTo("eth0")}, nil, nil, ""))
+
+		clone := config.Clone()
+		*clone.Nftables[0].Rule.Expr[0].Fwd.Dev.String = "eth1"
+
+		assert.Equal(t, "eth0", *config.Nftables[0].Rule.Expr[0].Fwd.Dev.String)
+	})
+}
+
+// sharedReferences returns the types of the pointers, slices and maps which are shared by the values.
+func sharedReferences(a, b reflect.Value) []string {
+	var shared []string
+	switch a.Kind() {
+	case reflect.Ptr:
+		if a.IsNil() || b.IsNil() {
+			return nil
+		}
+		if a.Pointer() == b.Pointer() {
+			shared = append(shared, a.Type().String())
+		}
+		return append(shared, sharedReferences(a.Elem(), b.Elem())...)
+	case reflect.Slice:
+		if a.Len() > 0 && b.Len() > 0 && a.Pointer() == b.Pointer() {
+			shared = append(shared, a.Type().String())
+		}
+		fallthrough
+	case reflect.Array:
+		for i := 0; i < a.Len() && i < b.Len(); i++ {
+			shared = append(shared, sharedReferences(a.Index(i), b.Index(i))...)
+		}
+	case reflect.Map:
+		if a.Len() > 0 && b.Len() > 0 && a.Pointer() == b.Pointer() {
+			shared = append(shared, a.Type().String())
+		}
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			shared = append(shared, sharedReferences(a.Field(i), b.Field(i))...)
+		}
+	}
+	return shared
 }
@@ -0,0 +1,158 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+const (
+	cmdCheck = "-c"
+
+	conntrackProbeTable = "go-nft-conntrack-probe"
+
+	// errRuleProcess prefixes the nft error of a rule which the kernel rejected.
+	errRuleProcess = "Could not process rule"
+)
+
+// conntrackUnsupportedErrors are the kernel errors of a rejected conntrack rule, when the kernel
+// lacks the ct expression (ENOENT) or connection tracking for the family (EOPNOTSUPP).
+var conntrackUnsupportedErrors = []string{"No such file or directory", "Operation not supported"}
+
+// conntrackKeys are the statement and expression keys which depend on connection tracking:
+// the ct expression, the ct helper, timeout and expectation assignments and the NAT statements.
+var conntrackKeys = []string{
+	schema.CtKey, "ct helper", "ct timeout", "ct expectation", "snat", "dnat", "masquerade", "redirect",
+}
+
+// ConntrackAvailable probes the system for connection tracking support,
+// by checking (without applying) a transaction which includes a rule with a conntrack match.
+// The kernel validates the transaction, loading the conntrack module on demand,
+// therefore no sysctl or module inspection is needed.
+// Connection tracking is reported as unavailable only when the kernel rejects the conntrack rule,
+// any other failure (e.g. the `nft` executable is missing or lacks permissions) is returned as an error.
+func ConntrackAvailable() (bool, error) {
+	return defaultExecutor.conntrackAvailable()
+}
+
+func (e *executor) conntrackAvailable() (bool, error) {
+	if _, err := exec.LookPath(e.executable()); err != nil {
+		return false, err
+	}
+
+	data, err := conntrackProbeConfig().ToJSON()
+	if err != nil {
+		return false, err
+	}
+	if _, stderr, err := e.runCommand(context.Background(), data, cmdCheck, cmdJSON, cmdFile, cmdStdin); err != nil {
+		if isConntrackUnsupported(stderr.String()) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// isConntrackUnsupported reports if the nft error output shows that the kernel rejected the probe rule
+// for lack of connection tracking support.
+func isConntrackUnsupported(stderr string) bool {
+	if !strings.Contains(stderr, errRuleProcess) {
+		return false
+	}
+	for _, unsupported := range conntrackUnsupportedErrors {
+		if strings.Contains(stderr, unsupported) {
+			return true
+		}
+	}
+	return false
+}
+
+func conntrackProbeConfig() *Config {
+	config := NewConfig()
+	table := NewTable(conntrackProbeTable, FamilyINET)
+	config.AddTable(table)
+	chain := NewRegularChain(table, conntrackProbeTable)
+	config.AddChain(chain)
+
+	established := "established"
+	config.AddRule(NewRule(table, chain, []schema.Statement{
+		{Match: &schema.Match{
+			Op:    schema.OperIN,
+			Left:  schema.Expression{Ct: &schema.Ct{Key: schema.CtKeyState}},
+			Right: schema.Expression{String: &established},
+		}},
+		{Verdict: schema.Accept()},
+	}, nil, nil, ""))
+	return config
+}
+
+// ConntrackReport lists the rules which have been omitted from the config,
+// as they depend on connection tracking.
+type ConntrackReport struct {
+	OmittedRules []*schema.Rule
+}
+
+// OmitConntrackRules removes from the config the rules which reference connection tracking
+// (e.g. `ct state` matches, `ct mark`, `ct helper` and `ct timeout` statements or NAT statements), allowing the config to be applied
+// on systems without conntrack support (see ConntrackAvailable).
+// Whole rules are omitted, as dropping only the conntrack statements would change the traffic the rule matches.
+// The omitted rules are returned in a report.
+func (c *Config) OmitConntrackRules() (*ConntrackReport, error) {
+	report := &ConntrackReport{}
+	nftables := make([]schema.Nftable, 0, len(c.Nftables))
+
+	for _, nftable := range c.Nftables {
+		if rule := addedRule(nftable); rule != nil {
+			usesConntrack, err := ruleUsesConntrack(rule)
+			if err != nil {
+				return nil, err
+			}
+			if usesConntrack {
+				report.OmittedRules = append(report.OmittedRules, rule)
+				continue
+			}
+		}
+		nftables = append(nftables, nftable)
+	}
+
+	c.Nftables = nftables
+	return report, nil
+}
+
+func ruleUsesConntrack(rule *schema.Rule) (bool, error) {
+	found := false
+	for _, statement := range rule.Expr {
+		err := walkStatementObjects(statement, func(object map[string]interface{}) {
+			for _, key := range conntrackKeys {
+				if _, exists := object[key]; exists {
+					found = true
+				}
+			}
+		})
+		if err != nil || found {
+			return found, err
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,109 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft_test
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/networkplumbing/go-nft/nft"
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+func TestOmitConntrackRules(t *testing.T) {
+	table := nft.NewTable(tableName, nft.FamilyINET)
+	chain := nft.NewRegularChain(table, chainName)
+	established := "established"
+	conntrackRule := nft.NewRule(table, chain, []schema.Statement{
+		{Match: &schema.Match{
+			Op:    schema.OperIN,
+			Left:  schema.Expression{Ct: &schema.Ct{Key: schema.CtKeyState}},
+			Right: schema.Expression{String: &established},
+		}},
+		{Verdict: schema.Accept()},
+	}, nil, nil, "established")
+	natRule := nft.NewRule(table, chain, []schema.Statement{
+		nft.DNATTo(net.ParseIP("10.0.0.1"), 8080),
+	}, nil, nil, "nat")
+	helperRule := nft.NewRule(table, chain, []schema.Statement{
+		nft.SetCtHelper(&schema.NamedCtHelper{Family: table.Family, Table: table.Name, Name: "ftp"}),
+	}, nil, nil, "helper")
+	hostRule := nft.NewRule(table, chain, []schema.Statement{
+		nft.MatchPacketType(nft.PacketTypeHost),
+		{Verdict: schema.Accept()},
+	}, nil, nil, "host")
+
+	config := nft.NewConfig()
+	config.AddTable(table)
+	config.AddChain(chain)
+	config.AddRule(conntrackRule)
+	config.AddRule(natRule)
+	config.AddRule(helperRule)
+	config.AddRule(hostRule)
+
+	t.Run("Omit the rules which reference conntrack", func(t *testing.T) {
+		report, err := config.OmitConntrackRules()
+		assert.NoError(t, err)
+		assert.Equal(t, []*schema.Rule{conntrackRule, natRule, helperRule}, report.OmittedRules)
+
+		expectedConfig := nft.NewConfig()
+		expectedConfig.AddTable(table)
+		expectedConfig.AddChain(chain)
+		expectedConfig.AddRule(hostRule)
+		assert.Equal(t, expectedConfig, config)
+	})
+
+	t.Run("Omit the rules which reference conntrack from a config without such rules", func(t *testing.T) {
+		report, err := config.OmitConntrackRules()
+		assert.NoError(t, err)
+		assert.Empty(t, report.OmittedRules)
+		assert.Len(t, config.Nftables, 3)
+	})
+}
+
+func TestConntrackAvailable(t *testing.T) {
+	probe := func(t *testing.T, script string) (bool, error) {
+		executable := filepath.Join(t.TempDir(), "fake-nft")
+		assert.NoError(t, os.WriteFile(executable, []byte(script), 0o755))
+		return nft.NewClient(nft.WithExecutable(executable)).ConntrackAvailable()
+	}
+
+	t.Run("Probe a kernel without conntrack support", func(t *testing.T) {
+		available, err := probe(t, `#!/bin/sh
+echo "Error: Could not process rule: No such file or directory" >&2
+exit 1
+`)
+		assert.NoError(t, err)
+		assert.False(t, available)
+	})
+
+	t.Run("Probe fails for another reason", func(t *testing.T) {
+		available, err := probe(t, `#!/bin/sh
+echo "Operation not permitted (you must be root)" >&2
+exit 1
+`)
+		assert.Error(t, err)
+		assert.False(t, available)
+	})
+}
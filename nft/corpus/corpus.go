@@ -0,0 +1,80 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+// Package corpus provides a corpus of representative nftables configs,
+// in their canonical JSON serialization as produced by the nft package.
+//
+// The configs are synthetic fixtures: they are written by hand, not generated from the listing of a `nft` executable,
+// therefore they are not guaranteed to match the output of any nft version.
+// They pin the library serialization only.
+//
+// Downstream projects may use the corpus in their regression tests,
+// verifying that their usage of the library remains compatible with its serialization.
+package corpus
+
+import (
+	"embed"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/networkplumbing/go-nft/nft"
+)
+
+const fixturesDir = "synthetic"
+
+//go:embed synthetic/*.json
+var fixtures embed.FS
+
+// Entry is a corpus entry, a named synthetic config in its canonical JSON serialization.
+type Entry struct {
+	Name string
+	JSON []byte
+}
+
+// Config decodes the entry JSON into a config.
+func (e Entry) Config() (*nft.Config, error) {
+	config := nft.NewConfig()
+	if err := config.FromJSON(e.JSON); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Entries returns the corpus entries, sorted by their names.
+func Entries() ([]Entry, error) {
+	files, err := fixtures.ReadDir(fixturesDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	for _, file := range files {
+		data, err := fixtures.ReadFile(path.Join(fixturesDir, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, Entry{
+			Name: strings.TrimSuffix(file.Name(), path.Ext(file.Name())),
+			JSON: []byte(strings.TrimSpace(string(data))),
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries, nil
+}
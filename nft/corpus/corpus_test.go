@@ -0,0 +1,45 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package corpus_test
+
+import (
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/networkplumbing/go-nft/nft/corpus"
+)
+
+func TestCorpus(t *testing.T) {
+	entries, err := corpus.Entries()
+	assert.NoError(t, err)
+	assert.NotEmpty(t, entries)
+
+	for _, entry := range entries {
+		entry := entry
+		t.Run(entry.Name+", check serialization round trip", func(t *testing.T) {
+			config, err := entry.Config()
+			assert.NoError(t, err)
+			serialized, err := config.ToJSON()
+			assert.NoError(t, err)
+			assert.Equal(t, string(entry.JSON), string(serialized))
+		})
+	}
+}
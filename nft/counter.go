@@ -0,0 +1,117 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft
+
+import (
+	"context"
+
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+// NewCounter returns an anonymous counter statement, counting the packets and bytes which matched the rule.
+// The counter values are populated when the rule is read from the system (see ReadConfig).
+func NewCounter() schema.Statement {
+	return schema.Statement{Counter: &schema.Counter{}}
+}
+
+// NewCounterReference returns a counter statement which updates the given named counter.
+func NewCounterReference(counter *schema.NamedCounter) schema.Statement {
+	return schema.Statement{Counter: &schema.Counter{Name: counter.Name}}
+}
+
+// CounterValue holds the packets and bytes counted by rules.
+type CounterValue struct {
+	Packets int
+	Bytes   int
+}
+
+// RuleCountersByComment returns the counter values of the given table rules, keyed by the rule comment.
+// Rules without a comment or without a counter statement are skipped, while values of rules
+// which share a comment are summed.
+// Rules which reference a named counter, use the named counter values (when found in the config).
+func (c *Config) RuleCountersByComment(table *schema.Table) map[string]CounterValue {
+	counters := map[string]CounterValue{}
+	for _, nftable := range c.Nftables {
+		rule := nftable.Rule
+		if rule == nil || rule.Comment == "" || rule.Family != table.Family || rule.Table != table.Name {
+			continue
+		}
+		for _, statement := range rule.Expr {
+			if statement.Counter == nil {
+				continue
+			}
+			value, found := c.counterValue(table, statement.Counter)
+			if !found {
+				continue
+			}
+			sum := counters[rule.Comment]
+			sum.Packets += value.Packets
+			sum.Bytes += value.Bytes
+			counters[rule.Comment] = sum
+		}
+	}
+	return counters
+}
+
+// ReadRuleCountersByComment reads the given table from the system and returns its rule counter values,
+// keyed by the rule comment (see Config.RuleCountersByComment).
+// The system is expected to have the `nft` executable deployed and nftables enabled in the kernel.
+func ReadRuleCountersByComment(table *schema.Table) (map[string]CounterValue, error) {
+	return defaultExecutor.readRuleCountersByComment(table)
+}
+
+func (e *executor) readRuleCountersByComment(table *schema.Table) (map[string]CounterValue, error) {
+	config, err := e.readConfig(context.Background(), cmdTable, table.Family, table.Name)
+	if err != nil {
+		return nil, err
+	}
+	return config.RuleCountersByComment(table), nil
+}
+
+func (c *Config) counterValue(table *schema.Table, counter *schema.Counter) (CounterValue, bool) {
+	if counter.Name == "" {
+		return CounterValue{Packets: counter.Packets, Bytes: counter.Bytes}, true
+	}
+	for _, nftable := range c.Nftables {
+		if named := nftable.Counter; named != nil &&
+			named.Family == table.Family && named.Table == table.Name && named.Name == counter.Name {
+			return CounterValue{Packets: named.Packets, Bytes: named.Bytes}, true
+		}
+	}
+	return CounterValue{}, false
+}
+
+// withoutCounterValues returns the rule statements, with the anonymous counter values reset.
+// It allows comparing rules regardless of the traffic they counted.
+func withoutCounterValues(statements []schema.Statement) []schema.Statement {
+	var reset []schema.Statement
+	for i, statement := range statements {
+		if c := statement.Counter; c != nil && (c.Packets != 0 || c.Bytes != 0) {
+			if reset == nil {
+				reset = append([]schema.Statement(nil), statements...)
+			}
+			reset[i].Counter = &schema.Counter{Name: c.Name}
+		}
+	}
+	if reset == nil {
+		return statements
+	}
+	return reset
+}
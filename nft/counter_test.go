@@ -0,0 +1,131 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/networkplumbing/go-nft/nft"
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+func TestCounterStatement(t *testing.T) {
+	testCounterStatementSerialization(t)
+	testReadRuleWithCounterValues(t)
+	testRuleCountersByComment(t)
+}
+
+func testCounterStatementSerialization(t *testing.T) {
+	tests := []struct {
+		name                string
+		statement           schema.Statement
+		serializedStatement string
+	}{
+		{
+			name:                "anonymous counter",
+			statement:           nft.NewCounter(),
+			serializedStatement: `{"counter":{"packets":0,"bytes":0}}`,
+		},
+		{
+			name:                "anonymous counter with values",
+			statement:           schema.Statement{Counter: &schema.Counter{Packets: 5, Bytes: 420}},
+			serializedStatement: `{"counter":{"packets":5,"bytes":420}}`,
+		},
+		{
+			name:                "named counter reference",
+			statement:           nft.NewCounterReference(&schema.NamedCounter{Name: "mycounter"}),
+			serializedStatement: `{"counter":"mycounter"}`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name+", check serialization", func(t *testing.T) {
+			serialized, err := json.Marshal(test.statement)
+			assert.NoError(t, err)
+			assert.Equal(t, test.serializedStatement, string(serialized))
+		})
+
+		t.Run(test.name+", check deserialization", func(t *testing.T) {
+			var statement schema.Statement
+			assert.NoError(t, json.Unmarshal([]byte(test.serializedStatement), &statement))
+			assert.Equal(t, test.statement, statement)
+		})
+	}
+
+	t.Run("anonymous counter without values, check deserialization", func(t *testing.T) {
+		var statement schema.Statement
+		assert.NoError(t, json.Unmarshal([]byte(`{"counter":null}`), &statement))
+		assert.Equal(t, nft.NewCounter(), statement)
+	})
+}
+
+func testReadRuleWithCounterValues(t *testing.T) {
+	table := nft.NewTable(tableName, nft.FamilyIP)
+	chain := nft.NewRegularChain(table, chainName)
+	rule := nft.NewRule(table, chain, []schema.Statement{nft.NewCounter(), {Verdict: schema.Accept()}}, nil, nil, "")
+
+	const handle = 4
+	serializedConfig := fmt.Sprintf(
+		`{"nftables":[{"rule":{"family":"ip","table":%q,"chain":%q,"handle":%d,"expr":[{"counter":{"packets":5,"bytes":420}},{"accept":null}]}}]}`,
+		tableName, chainName, handle,
+	)
+	config := nft.NewConfig()
+	assert.NoError(t, config.FromJSON([]byte(serializedConfig)))
+
+	t.Run("Read a rule with counter values", func(t *testing.T) {
+		counter := config.Nftables[0].Rule.Expr[0].Counter
+		assert.Equal(t, &schema.Counter{Packets: 5, Bytes: 420}, counter)
+	})
+
+	t.Run("Lookup a read rule regardless of its counter values", func(t *testing.T) {
+		foundRules := config.LookupRule(rule)
+		assert.Len(t, foundRules, 1)
+		assert.Equal(t, handle, *foundRules[0].Handle)
+	})
+}
+
+func testRuleCountersByComment(t *testing.T) {
+	serializedConfig := fmt.Sprintf(`{"nftables":[
+		{"counter":{"family":"ip","table":%[1]q,"name":"shared","packets":7,"bytes":700}},
+		{"rule":{"family":"ip","table":%[1]q,"chain":%[2]q,"handle":1,"expr":[{"counter":{"packets":1,"bytes":100}}],"comment":"web"}},
+		{"rule":{"family":"ip","table":%[1]q,"chain":%[2]q,"handle":2,"expr":[{"counter":{"packets":2,"bytes":200}}],"comment":"web"}},
+		{"rule":{"family":"ip","table":%[1]q,"chain":%[2]q,"handle":3,"expr":[{"counter":"shared"}],"comment":"named"}},
+		{"rule":{"family":"ip","table":%[1]q,"chain":%[2]q,"handle":4,"expr":[{"counter":"missing"}],"comment":"missing"}},
+		{"rule":{"family":"ip","table":%[1]q,"chain":%[2]q,"handle":5,"expr":[{"counter":{"packets":3,"bytes":300}}]}},
+		{"rule":{"family":"ip","table":%[1]q,"chain":%[2]q,"handle":6,"expr":[{"accept":null}],"comment":"no counter"}},
+		{"rule":{"family":"ip6","table":%[1]q,"chain":%[2]q,"handle":7,"expr":[{"counter":{"packets":4,"bytes":400}}],"comment":"other table"}}
+	]}`, tableName, chainName)
+
+	config := nft.NewConfig()
+	assert.NoError(t, config.FromJSON([]byte(serializedConfig)))
+
+	t.Run("Read rule counters by comment", func(t *testing.T) {
+		counters := config.RuleCountersByComment(nft.NewTable(tableName, nft.FamilyIP))
+		assert.Equal(t, map[string]nft.CounterValue{
+			"web":   {Packets: 3, Bytes: 300},
+			"named": {Packets: 7, Bytes: 700},
+		}, counters)
+	})
+}
@@ -0,0 +1,141 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+// TrafficCase declares the expected verdict of a packet which traverses a chain.
+type TrafficCase struct {
+	Name   string
+	Packet Packet
+	// Verdict is the expected verdict, either accept or drop.
+	Verdict string
+}
+
+// TrafficCaseResult reports the outcome of a traffic case check.
+type TrafficCaseResult struct {
+	Case   TrafficCase
+	Passed bool
+	// Result is the evaluation outcome, it is nil when the evaluation failed.
+	Result *EvalResult
+	Err    error
+}
+
+// String returns a human readable summary of the result, including the path of the matching rules.
+func (r TrafficCaseResult) String() string {
+	status := "PASS"
+	if !r.Passed {
+		status = "FAIL"
+	}
+	if r.Err != nil {
+		return fmt.Sprintf("%s %s: %v", status, r.Case.Name, r.Err)
+	}
+
+	var path []string
+	for _, ref := range r.Result.Path {
+		step := fmt.Sprintf("%s[%d]", ref.Chain, ref.Position)
+		if ref.Comment != "" {
+			step += fmt.Sprintf("(%s)", ref.Comment)
+		}
+		path = append(path, step)
+	}
+	return fmt.Sprintf(
+		"%s %s: expected %s, got %s, path: %s",
+		status, r.Case.Name, r.Case.Verdict, r.Result.Verdict, strings.Join(path, " -> "),
+	)
+}
+
+// CheckTraffic evaluates each traffic case against the given chain and reports
+// if the resulting verdict is the expected one.
+// Each result includes the path of the matching rules, to ease pinpointing the rule
+// which caused a case to fail.
+func (c *Config) CheckTraffic(chain *schema.Chain, cases []TrafficCase) []TrafficCaseResult {
+	var results []TrafficCaseResult
+	for _, tc := range cases {
+		result, err := c.Evaluate(chain, tc.Packet)
+		results = append(results, TrafficCaseResult{
+			Case:   tc,
+			Passed: err == nil && result.Verdict == tc.Verdict,
+			Result: result,
+			Err:    err,
+		})
+	}
+	return results
+}
+
+// ParsePacket parses a traffic description in the form `SRC[:PORT]->DST[:PORT][/PROTOCOL]`.
+// IPv6 addresses with a port should be enclosed in brackets (e.g. `[2001:db8::1]:443`).
+// A `*` port is considered unknown.
+// Example: `10.0.0.5:*->203.0.113.1:443/tcp`
+func ParsePacket(description string) (Packet, error) {
+	var packet Packet
+
+	traffic := description
+	if i := strings.LastIndex(traffic, "/"); i >= 0 {
+		packet.Protocol = traffic[i+1:]
+		traffic = traffic[:i]
+	}
+
+	endpoints := strings.Split(traffic, "->")
+	if len(endpoints) != 2 {
+		return Packet{}, fmt.Errorf("invalid traffic description %q: expecting SRC->DST", description)
+	}
+
+	var err error
+	if packet.SrcIP, packet.SrcPort, err = parseEndpoint(endpoints[0]); err != nil {
+		return Packet{}, fmt.Errorf("invalid traffic description %q: %v", description, err)
+	}
+	if packet.DstIP, packet.DstPort, err = parseEndpoint(endpoints[1]); err != nil {
+		return Packet{}, fmt.Errorf("invalid traffic description %q: %v", description, err)
+	}
+
+	return packet, nil
+}
+
+func parseEndpoint(endpoint string) (string, int, error) {
+	endpoint = strings.TrimSpace(endpoint)
+	host, port := endpoint, ""
+	if strings.HasPrefix(endpoint, "[") || strings.Count(endpoint, ":") == 1 {
+		var err error
+		if host, port, err = net.SplitHostPort(endpoint); err != nil {
+			return "", 0, err
+		}
+	}
+
+	if net.ParseIP(host) == nil {
+		return "", 0, fmt.Errorf("invalid address: %q", host)
+	}
+
+	if port == "" || port == "*" {
+		return host, 0, nil
+	}
+	portNumber, err := strconv.Atoi(port)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid port: %q", port)
+	}
+	return host, portNumber, nil
+}
@@ -0,0 +1,69 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft_test
+
+import (
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/networkplumbing/go-nft/nft"
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+func TestCheckTraffic(t *testing.T) {
+	config, inputChain := buildEvaluationConfig()
+
+	sshPacket, err := nft.ParsePacket("192.0.2.1:*->203.0.113.1:22/tcp")
+	assert.NoError(t, err)
+	httpsPacket, err := nft.ParsePacket("192.0.2.1:*->203.0.113.1:443/tcp")
+	assert.NoError(t, err)
+
+	results := config.CheckTraffic(inputChain, []nft.TrafficCase{
+		{Name: "ssh is allowed", Packet: sshPacket, Verdict: schema.VerdictAccept},
+		{Name: "external https is allowed", Packet: httpsPacket, Verdict: schema.VerdictAccept},
+	})
+	assert.Len(t, results, 2)
+
+	assert.True(t, results[0].Passed)
+	assert.Equal(t, "PASS ssh is allowed: expected accept, got accept, path: input[1](to services) -> services[0](ssh)", results[0].String())
+
+	assert.False(t, results[1].Passed)
+	assert.Equal(t, "FAIL external https is allowed: expected accept, got drop, path: input[1](to services)", results[1].String())
+}
+
+func TestParsePacket(t *testing.T) {
+	t.Run("Parse IPv4 traffic description", func(t *testing.T) {
+		packet, err := nft.ParsePacket("10.0.0.5:*->203.0.113.1:443/tcp")
+		assert.NoError(t, err)
+		assert.Equal(t, nft.Packet{Protocol: "tcp", SrcIP: "10.0.0.5", DstIP: "203.0.113.1", DstPort: 443}, packet)
+	})
+
+	t.Run("Parse IPv6 traffic description", func(t *testing.T) {
+		packet, err := nft.ParsePacket("2001:db8::1->[2001:db8::2]:53/udp")
+		assert.NoError(t, err)
+		assert.Equal(t, nft.Packet{Protocol: "udp", SrcIP: "2001:db8::1", DstIP: "2001:db8::2", DstPort: 53}, packet)
+	})
+
+	t.Run("Parse invalid traffic description", func(t *testing.T) {
+		_, err := nft.ParsePacket("10.0.0.5:80/tcp")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,82 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft
+
+import (
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+type CtHelperProtocol string
+
+// Conntrack Helper Protocols
+const (
+	CtHelperProtocolTCP CtHelperProtocol = schema.CtHelperProtocolTCP
+	CtHelperProtocolUDP CtHelperProtocol = schema.CtHelperProtocolUDP
+)
+
+// NewCtHelper returns a new schema conntrack helper object structure, which rules may assign to connections.
+// The helper type is the name of the kernel helper (e.g. `ftp`, `sip`), using the given layer 4 protocol.
+// The layer 3 protocol defaults to the table family, set the L3Proto field to override it.
+func NewCtHelper(table *schema.Table, name string, helperType string, protocol CtHelperProtocol) *schema.NamedCtHelper {
+	return &schema.NamedCtHelper{
+		Family:   table.Family,
+		Table:    table.Name,
+		Name:     name,
+		Type:     helperType,
+		Protocol: string(protocol),
+	}
+}
+
+// SetCtHelper returns a statement which assigns the given conntrack helper to the connection (`ct helper set <name>`).
+// The statement is commonly applied to the new connections of the helper service (e.g. `tcp dport 21`),
+// in a chain with a priority after the conntrack one.
+func SetCtHelper(helper *schema.NamedCtHelper) schema.Statement {
+	return schema.Statement{CtHelper: helper.Name}
+}
+
+// AddCtHelper appends the given conntrack helper object to the nftable config.
+// The helper is added without an explicit action (`add`).
+func (c *Config) AddCtHelper(helper *schema.NamedCtHelper) {
+	nftable := schema.Nftable{CtHelper: helper}
+	c.Nftables = append(c.Nftables, nftable)
+}
+
+// DeleteCtHelper appends a given conntrack helper object to the nftable config
+// with the `delete` action.
+// Attempting to delete a non-existing helper, results with a failure when the config is applied.
+// The helper must not be referenced by any rule.
+func (c *Config) DeleteCtHelper(helper *schema.NamedCtHelper) {
+	nftable := schema.Nftable{Delete: &schema.Objects{CtHelper: helper}}
+	c.Nftables = append(c.Nftables, nftable)
+}
+
+// LookupCtHelper searches the configuration for a matching conntrack helper object and returns it.
+// The helper is matched by the table and helper name.
+// Mutating the returned helper will result in mutating the configuration.
+func (c *Config) LookupCtHelper(toFind *schema.NamedCtHelper) *schema.NamedCtHelper {
+	for _, nftable := range c.Nftables {
+		if helper := nftable.CtHelper; helper != nil {
+			if helper.Table == toFind.Table && helper.Family == toFind.Family && helper.Name == toFind.Name {
+				return helper
+			}
+		}
+	}
+	return nil
+}
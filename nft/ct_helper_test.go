@@ -0,0 +1,109 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/networkplumbing/go-nft/nft"
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+const ctHelperName = "test-ct-helper"
+
+func TestCtHelper(t *testing.T) {
+	testCtHelperObjectActions(t)
+	testCtHelperLookup(t)
+	testCtHelperStatement(t)
+}
+
+func testCtHelperObjectActions(t *testing.T) {
+	table := nft.NewTable(tableName, nft.FamilyINET)
+	helper := nft.NewCtHelper(table, ctHelperName, "ftp", nft.CtHelperProtocolTCP)
+	helper.L3Proto = string(nft.FamilyIP)
+	helperArgs := fmt.Sprintf(`"family":"inet","table":%q,"name":%q,"type":"ftp","protocol":"tcp","l3proto":"ip"`, tableName, ctHelperName)
+
+	actions := map[string]func(*nft.Config){
+		"add":    func(c *nft.Config) { c.AddCtHelper(helper) },
+		"delete": func(c *nft.Config) { c.DeleteCtHelper(helper) },
+	}
+	expected := map[string]string{
+		"add":    fmt.Sprintf(`{"nftables":[{"ct helper":{%s}}]}`, helperArgs),
+		"delete": fmt.Sprintf(`{"nftables":[{"delete":{"ct helper":{%s}}}]}`, helperArgs),
+	}
+
+	for action, actionFunc := range actions {
+		action, actionFunc := action, actionFunc
+		t.Run(fmt.Sprintf("%s ct helper", action), func(t *testing.T) {
+			config := nft.NewConfig()
+			actionFunc(config)
+
+			serialized, err := config.ToJSON()
+			assert.NoError(t, err)
+			assert.Equal(t, expected[action], string(serialized))
+		})
+	}
+
+	t.Run("Read ct helper", func(t *testing.T) {
+		var deserializedConfig nft.Config
+		assert.NoError(t, json.Unmarshal([]byte(expected["add"]), &deserializedConfig))
+
+		expectedConfig := nft.NewConfig()
+		expectedConfig.AddCtHelper(helper)
+		assert.Equal(t, expectedConfig, &deserializedConfig)
+	})
+}
+
+func testCtHelperLookup(t *testing.T) {
+	config := nft.NewConfig()
+	table := nft.NewTable(tableName, nft.FamilyIP)
+	helper := nft.NewCtHelper(table, ctHelperName, "sip", nft.CtHelperProtocolUDP)
+	config.AddCtHelper(helper)
+
+	t.Run("Lookup an existing ct helper", func(t *testing.T) {
+		assert.Equal(t, helper, config.LookupCtHelper(&schema.NamedCtHelper{Family: table.Family, Table: table.Name, Name: ctHelperName}))
+	})
+
+	t.Run("Lookup a missing ct helper", func(t *testing.T) {
+		assert.Nil(t, config.LookupCtHelper(&schema.NamedCtHelper{Family: table.Family, Table: table.Name, Name: "ct-helper-na"}))
+	})
+}
+
+func testCtHelperStatement(t *testing.T) {
+	table := nft.NewTable(tableName, nft.FamilyIP)
+	statement := nft.SetCtHelper(nft.NewCtHelper(table, ctHelperName, "ftp", nft.CtHelperProtocolTCP))
+	serializedStatement := fmt.Sprintf(`{"ct helper":%q}`, ctHelperName)
+
+	t.Run("Ct helper statement, check serialization", func(t *testing.T) {
+		serialized, err := json.Marshal(statement)
+		assert.NoError(t, err)
+		assert.Equal(t, serializedStatement, string(serialized))
+	})
+
+	t.Run("Ct helper statement, check deserialization", func(t *testing.T) {
+		var deserializedStatement schema.Statement
+		assert.NoError(t, json.Unmarshal([]byte(serializedStatement), &deserializedStatement))
+		assert.Equal(t, statement, deserializedStatement)
+	})
+}
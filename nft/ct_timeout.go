@@ -0,0 +1,78 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft
+
+import (
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+// NewCtTimeout returns a new schema conntrack timeout policy object structure,
+// which rules may assign to connections.
+// The protocol is the layer 4 protocol name (e.g. `tcp`, `udp`), the policy maps its connection states
+// (e.g. schema.CtTimeoutStateEstablished) to their timeout in seconds, states which are not included
+// keep the system default timeout.
+// The layer 3 protocol defaults to the table family, set the L3Proto field to override it.
+func NewCtTimeout(table *schema.Table, name string, protocol string, policy map[string]int) *schema.NamedCtTimeout {
+	return &schema.NamedCtTimeout{
+		Family:   table.Family,
+		Table:    table.Name,
+		Name:     name,
+		Protocol: protocol,
+		Policy:   policy,
+	}
+}
+
+// SetCtTimeout returns a statement which assigns the given conntrack timeout policy to the connection
+// (`ct timeout set <name>`).
+// The statement must be applied before the connection is confirmed, in a chain with a priority
+// before the conntrack one (e.g. raw).
+func SetCtTimeout(timeout *schema.NamedCtTimeout) schema.Statement {
+	return schema.Statement{CtTimeout: timeout.Name}
+}
+
+// AddCtTimeout appends the given conntrack timeout policy object to the nftable config.
+// The policy is added without an explicit action (`add`).
+func (c *Config) AddCtTimeout(timeout *schema.NamedCtTimeout) {
+	nftable := schema.Nftable{CtTimeout: timeout}
+	c.Nftables = append(c.Nftables, nftable)
+}
+
+// DeleteCtTimeout appends a given conntrack timeout policy object to the nftable config
+// with the `delete` action.
+// Attempting to delete a non-existing policy, results with a failure when the config is applied.
+// The policy must not be referenced by any rule.
+func (c *Config) DeleteCtTimeout(timeout *schema.NamedCtTimeout) {
+	nftable := schema.Nftable{Delete: &schema.Objects{CtTimeout: timeout}}
+	c.Nftables = append(c.Nftables, nftable)
+}
+
+// LookupCtTimeout searches the configuration for a matching conntrack timeout policy object and returns it.
+// The policy is matched by the table and policy name.
+// Mutating the returned policy will result in mutating the configuration.
+func (c *Config) LookupCtTimeout(toFind *schema.NamedCtTimeout) *schema.NamedCtTimeout {
+	for _, nftable := range c.Nftables {
+		if timeout := nftable.CtTimeout; timeout != nil {
+			if timeout.Table == toFind.Table && timeout.Family == toFind.Family && timeout.Name == toFind.Name {
+				return timeout
+			}
+		}
+	}
+	return nil
+}
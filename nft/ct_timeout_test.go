@@ -0,0 +1,113 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/networkplumbing/go-nft/nft"
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+const ctTimeoutName = "test-ct-timeout"
+
+func TestCtTimeout(t *testing.T) {
+	testCtTimeoutObjectActions(t)
+	testCtTimeoutLookup(t)
+	testCtTimeoutStatement(t)
+}
+
+func testCtTimeoutObjectActions(t *testing.T) {
+	table := nft.NewTable(tableName, nft.FamilyIP)
+	timeout := nft.NewCtTimeout(table, ctTimeoutName, "tcp", map[string]int{
+		schema.CtTimeoutStateEstablished: 300,
+		schema.CtTimeoutStateClose:       10,
+	})
+	timeoutArgs := fmt.Sprintf(
+		`"family":"ip","table":%q,"name":%q,"protocol":"tcp","policy":{"close":10,"established":300}`, tableName, ctTimeoutName,
+	)
+
+	actions := map[string]func(*nft.Config){
+		"add":    func(c *nft.Config) { c.AddCtTimeout(timeout) },
+		"delete": func(c *nft.Config) { c.DeleteCtTimeout(timeout) },
+	}
+	expected := map[string]string{
+		"add":    fmt.Sprintf(`{"nftables":[{"ct timeout":{%s}}]}`, timeoutArgs),
+		"delete": fmt.Sprintf(`{"nftables":[{"delete":{"ct timeout":{%s}}}]}`, timeoutArgs),
+	}
+
+	for action, actionFunc := range actions {
+		action, actionFunc := action, actionFunc
+		t.Run(fmt.Sprintf("%s ct timeout", action), func(t *testing.T) {
+			config := nft.NewConfig()
+			actionFunc(config)
+
+			serialized, err := config.ToJSON()
+			assert.NoError(t, err)
+			assert.Equal(t, expected[action], string(serialized))
+		})
+	}
+
+	t.Run("Read ct timeout", func(t *testing.T) {
+		var deserializedConfig nft.Config
+		assert.NoError(t, json.Unmarshal([]byte(expected["add"]), &deserializedConfig))
+
+		expectedConfig := nft.NewConfig()
+		expectedConfig.AddCtTimeout(timeout)
+		assert.Equal(t, expectedConfig, &deserializedConfig)
+	})
+}
+
+func testCtTimeoutLookup(t *testing.T) {
+	config := nft.NewConfig()
+	table := nft.NewTable(tableName, nft.FamilyIP)
+	timeout := nft.NewCtTimeout(table, ctTimeoutName, "udp", map[string]int{schema.CtTimeoutStateReplied: 60})
+	config.AddCtTimeout(timeout)
+
+	t.Run("Lookup an existing ct timeout", func(t *testing.T) {
+		assert.Equal(t, timeout, config.LookupCtTimeout(&schema.NamedCtTimeout{Family: table.Family, Table: table.Name, Name: ctTimeoutName}))
+	})
+
+	t.Run("Lookup a missing ct timeout", func(t *testing.T) {
+		assert.Nil(t, config.LookupCtTimeout(&schema.NamedCtTimeout{Family: table.Family, Table: table.Name, Name: "ct-timeout-na"}))
+	})
+}
+
+func testCtTimeoutStatement(t *testing.T) {
+	table := nft.NewTable(tableName, nft.FamilyIP)
+	statement := nft.SetCtTimeout(nft.NewCtTimeout(table, ctTimeoutName, "tcp", nil))
+	serializedStatement := fmt.Sprintf(`{"ct timeout":%q}`, ctTimeoutName)
+
+	t.Run("Ct timeout statement, check serialization", func(t *testing.T) {
+		serialized, err := json.Marshal(statement)
+		assert.NoError(t, err)
+		assert.Equal(t, serializedStatement, string(serialized))
+	})
+
+	t.Run("Ct timeout statement, check deserialization", func(t *testing.T) {
+		var deserializedStatement schema.Statement
+		assert.NoError(t, json.Unmarshal([]byte(serializedStatement), &deserializedStatement))
+		assert.Equal(t, statement, deserializedStatement)
+	})
+}
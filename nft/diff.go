@@ -0,0 +1,431 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+package nft
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+// Object kinds, as named in the nftables JSON schema.
+const (
+	kindTable     = "table"
+	kindChain     = "chain"
+	kindSet       = "set"
+	kindMap       = "map"
+	kindFlowtable = "flowtable"
+	kindCounter   = "counter"
+	kindLimit     = "limit"
+	kindQuota     = "quota"
+	kindSynproxy  = "synproxy"
+	kindCtHelper  = "ct helper"
+	kindCtTimeout = "ct timeout"
+)
+
+// Diff returns the config which transforms the current config (commonly read from the system using ReadConfig)
+// into the desired one, when applied.
+// Both configs are expected to be declarative, listing their objects (tables, chains, rules, sets, etc)
+// without explicit actions. Entries with actions (and the metainfo entries) are ignored.
+// Objects are compared regardless of their handles and stateful values (e.g. counter values and quota used bytes),
+// rules are compared per chain in their normalized and canonical form (see NormalizeStatements and
+// CanonicalizeStatements).
+// Base chains are compared in the form nft lists them: without a policy, a chain has the accept policy,
+// and a priority name is resolved to its numeric value.
+//
+// The resulting config includes the following operations, in order:
+//   - Rules which are no longer desired are deleted by their handle.
+//   - Objects which are no longer desired are deleted, a table is deleted with all its content.
+//     Chains are flushed before they are deleted.
+//   - Objects which changed are replaced, i.e. deleted and added again.
+//     Sets and maps which differ only by their elements are flushed and re-populated instead.
+//     Tables which changed (e.g. their flags) and chains which differ only by their policy
+//     are added again, updating them in place.
+//     Other chain changes (e.g. the hook or priority), which nft cannot update, replace the chain:
+//     it is flushed, deleted and added again with its desired rules.
+//   - Missing objects are added.
+//   - Missing rules are added at their desired position, following the preceding existing rule,
+//     or inserted before the first existing rule of the chain.
+//     When the position cannot be expressed (e.g. an existing rule has no handle),
+//     the chain is flushed and all its desired rules are added.
+//
+// An empty config is returned when the current config is already in the desired state.
+// Replacing an object which is referenced by unchanged rules (e.g. a set or a jump target chain),
+// results with a failure when the config is applied.
+func Diff(current, desired *Config) *Config {
+	currentObjects, desiredObjects := collectDeclaredObjects(current), collectDeclaredObjects(desired)
+	ruleDeletions, flushes, chainDeletions, deletions, tableDeletions := NewConfig(), NewConfig(), NewConfig(), NewConfig(), NewConfig()
+	tableAdditions, additions, chainAdditions, ruleAdditions := NewConfig(), NewConfig(), NewConfig(), NewConfig()
+
+	removedTables := map[objectID]bool{}
+	recreatedChains := map[objectID]bool{}
+	for _, id := range currentObjects.ids {
+		if _, isDesired := desiredObjects.objects[id]; !isDesired && id.kind == kindTable {
+			removedTables[id] = true
+			tableDeletions.DeleteTable(currentObjects.objects[id].Table)
+		}
+	}
+
+	for _, id := range currentObjects.ids {
+		if removedTables[id.tableID()] {
+			continue
+		}
+		currentObject := currentObjects.objects[id]
+		desiredObject, isDesired := desiredObjects.objects[id]
+		switch {
+		case id.kind == kindTable:
+			if isDesired && objectKey(currentObject) != objectKey(desiredObject) {
+				tableAdditions.Nftables = append(tableAdditions.Nftables, withoutHandles(desiredObject))
+			}
+		case isDesired && objectKey(currentObject) == objectKey(desiredObject):
+		case isDesired && (id.kind == kindSet || id.kind == kindMap) &&
+			objectKey(withoutElements(currentObject)) == objectKey(withoutElements(desiredObject)):
+			flushes.Nftables = append(flushes.Nftables, schema.Nftable{Flush: objectsOf(withoutElements(currentObject))})
+			additions.Nftables = append(additions.Nftables, withoutHandles(desiredObject))
+		case id.kind == kindChain && isDesired && isChainUpdatable(currentObject.Chain, desiredObject.Chain):
+			chainAdditions.Nftables = append(chainAdditions.Nftables, withoutHandles(desiredObject))
+		case id.kind == kindChain:
+			flushes.FlushChain(currentObject.Chain)
+			chainDeletions.DeleteChain(currentObject.Chain)
+			if isDesired {
+				recreatedChains[id] = true
+				chainAdditions.Nftables = append(chainAdditions.Nftables, withoutHandles(desiredObject))
+			}
+		default:
+			deletions.Nftables = append(deletions.Nftables, schema.Nftable{Delete: objectsOf(currentObject)})
+			if isDesired {
+				additions.Nftables = append(additions.Nftables, withoutHandles(desiredObject))
+			}
+		}
+	}
+
+	for _, id := range desiredObjects.ids {
+		if _, exists := currentObjects.objects[id]; exists {
+			continue
+		}
+		nftable := withoutHandles(desiredObjects.objects[id])
+		switch id.kind {
+		case kindTable:
+			tableAdditions.Nftables = append(tableAdditions.Nftables, nftable)
+		case kindChain:
+			recreatedChains[id] = true
+			chainAdditions.Nftables = append(chainAdditions.Nftables, nftable)
+		default:
+			additions.Nftables = append(additions.Nftables, nftable)
+		}
+	}
+
+	for _, id := range desiredObjects.ruleChains {
+		desiredRules := desiredObjects.rules[id]
+		if recreatedChains[id] || removedTables[id.tableID()] {
+			for _, rule := range desiredRules {
+				ruleAdditions.AddRule(withoutRuleHandle(rule))
+			}
+			continue
+		}
+		diffChainRules(ruleDeletions, flushes, ruleAdditions, id, currentObjects.rules[id], desiredRules)
+	}
+	for _, id := range currentObjects.ruleChains {
+		_, isDesired := desiredObjects.rules[id]
+		_, isCurrentChain := currentObjects.objects[id]
+		_, isDesiredChain := desiredObjects.objects[id]
+		isRemovedChain := isCurrentChain && !isDesiredChain
+		if !isDesired && !isRemovedChain && !recreatedChains[id] && !removedTables[id.tableID()] {
+			diffChainRules(ruleDeletions, flushes, ruleAdditions, id, currentObjects.rules[id], nil)
+		}
+	}
+
+	config := NewConfig()
+	for _, stage := range []*Config{
+		ruleDeletions, flushes, chainDeletions, deletions, tableDeletions, tableAdditions, additions, chainAdditions, ruleAdditions,
+	} {
+		config.Nftables = append(config.Nftables, stage.Nftables...)
+	}
+	return config
+}
+
+// diffChainRules adds the operations which transform the current rules of a chain into the desired ones.
+// The rules which exist in both, in the same relative order, are kept.
+func diffChainRules(ruleDeletions, flushes, ruleAdditions *Config, chain objectID, currentRules, desiredRules []*schema.Rule) {
+	currentKeys, desiredKeys := make([]string, len(currentRules)), make([]string, len(desiredRules))
+	for i, rule := range currentRules {
+		currentKeys[i] = ruleKey(rule)
+	}
+	for i, rule := range desiredRules {
+		desiredKeys[i] = ruleKey(rule)
+	}
+	// kept maps the desired rules positions to the positions of the kept current rules.
+	kept := longestCommonSubsequence(currentKeys, desiredKeys)
+
+	isKept := make([]bool, len(currentRules))
+	for _, i := range kept {
+		if i >= 0 {
+			isKept[i] = true
+		}
+	}
+	positionable := true
+	for i, rule := range currentRules {
+		if !isKept[i] && rule.Handle == nil {
+			positionable = false
+		}
+	}
+
+	// Each group of missing rules is added after its preceding kept rule.
+	// The rules are added in reverse order, as each is positioned right after the kept rule.
+	// The group which precedes the first kept rule is inserted before it, in order.
+	// When no rule is kept, the missing rules are appended in order.
+	positioned := NewConfig()
+	var previous *schema.Rule
+	var group []*schema.Rule
+	flushGroup := func(next *schema.Rule) {
+		for i := range group {
+			switch {
+			case previous != nil:
+				rule := withoutRuleHandle(group[len(group)-1-i])
+				rule.Index, rule.Handle = nil, previous.Handle
+				positioned.AddRule(rule)
+			case next != nil:
+				rule := withoutRuleHandle(group[i])
+				rule.Index, rule.Handle = nil, next.Handle
+				positioned.InsertRule(rule)
+			default:
+				positioned.AddRule(withoutRuleHandle(group[i]))
+			}
+		}
+		group = nil
+	}
+	for j, rule := range desiredRules {
+		if i := kept[j]; i >= 0 {
+			if currentRules[i].Handle == nil {
+				positionable = false
+			}
+			flushGroup(currentRules[i])
+			previous = currentRules[i]
+			continue
+		}
+		group = append(group, rule)
+	}
+	flushGroup(nil)
+
+	if !positionable {
+		flushes.FlushChain(&schema.Chain{Family: chain.family, Table: chain.table, Name: chain.name})
+		for _, rule := range desiredRules {
+			ruleAdditions.AddRule(withoutRuleHandle(rule))
+		}
+		return
+	}
+	for i, rule := range currentRules {
+		if !isKept[i] {
+			ruleDeletions.DeleteRule(rule)
+		}
+	}
+	ruleAdditions.Nftables = append(ruleAdditions.Nftables, positioned.Nftables...)
+}
+
+// isChainUpdatable reports if the current chain can be updated in place to the desired one (using `add chain`),
+// i.e. they differ only by their policy.
+func isChainUpdatable(current, desired *schema.Chain) bool {
+	currentChain := *withChainDefaults(withoutHandles(schema.Nftable{Chain: current})).Chain
+	desiredChain := *withChainDefaults(withoutHandles(schema.Nftable{Chain: desired})).Chain
+	currentChain.Policy, desiredChain.Policy = "", ""
+	return objectKey(schema.Nftable{Chain: &currentChain}) == objectKey(schema.Nftable{Chain: &desiredChain})
+}
+
+// longestCommonSubsequence returns for each of the b elements the position of the matching a element
+// in the longest common subsequence, or -1 when it is not part of it.
+func longestCommonSubsequence(a, b []string) []int {
+	lengths := make([][]int, len(a)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			case lengths[i+1][j] >= lengths[i][j+1]:
+				lengths[i][j] = lengths[i+1][j]
+			default:
+				lengths[i][j] = lengths[i][j+1]
+			}
+		}
+	}
+
+	matches := make([]int, len(b))
+	for j := range matches {
+		matches[j] = -1
+	}
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] == b[j]:
+			matches[j] = i
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return matches
+}
+
+// objectID identifies a declared object by its kind, family, table and name.
+// A table is identified by its kind, family and table (with an empty name).
+type objectID struct {
+	kind, family, table, name string
+}
+
+func (id objectID) String() string {
+	if id.kind == kindTable {
+		return fmt.Sprintf("table %s %s", id.family, id.table)
+	}
+	return fmt.Sprintf("%s %s %s %s", id.kind, id.family, id.table, id.name)
+}
+
+func (id objectID) tableID() objectID {
+	return objectID{kind: kindTable, family: id.family, table: id.table}
+}
+
+// ruleChainID returns the identity of the rule chain.
+func ruleChainID(rule *schema.Rule) objectID {
+	return objectID{kind: kindChain, family: rule.Family, table: rule.Table, name: rule.Chain}
+}
+
+// declaredObjects are the objects declared by a config, in their declaration order.
+// The rules are grouped by their chain.
+type declaredObjects struct {
+	ids        []objectID
+	objects    map[objectID]schema.Nftable
+	ruleChains []objectID
+	rules      map[objectID][]*schema.Rule
+}
+
+func collectDeclaredObjects(config *Config) declaredObjects {
+	declared := declaredObjects{objects: map[objectID]schema.Nftable{}, rules: map[objectID][]*schema.Rule{}}
+	for _, nftable := range config.Nftables {
+		if rule := nftable.Rule; rule != nil {
+			id := ruleChainID(rule)
+			if _, exists := declared.rules[id]; !exists {
+				declared.ruleChains = append(declared.ruleChains, id)
+			}
+			declared.rules[id] = append(declared.rules[id], rule)
+			continue
+		}
+		id, isObject := objectIdentity(nftable)
+		if !isObject {
+			continue
+		}
+		if _, exists := declared.objects[id]; !exists {
+			declared.ids = append(declared.ids, id)
+		}
+		declared.objects[id] = nftable
+	}
+	return declared
+}
+
+// objectIdentity returns the identity of an object entry.
+// It reports false for other entries (e.g. rules, commands and metainfo).
+func objectIdentity(nftable schema.Nftable) (objectID, bool) {
+	switch {
+	case nftable.Table != nil:
+		return objectID{kind: kindTable, family: nftable.Table.Family, table: nftable.Table.Name}, true
+	case nftable.Chain != nil:
+		return objectID{kindChain, nftable.Chain.Family, nftable.Chain.Table, nftable.Chain.Name}, true
+	case nftable.Set != nil:
+		return objectID{kindSet, nftable.Set.Family, nftable.Set.Table, nftable.Set.Name}, true
+	case nftable.Map != nil:
+		return objectID{kindMap, nftable.Map.Family, nftable.Map.Table, nftable.Map.Name}, true
+	case nftable.Flowtable != nil:
+		return objectID{kindFlowtable, nftable.Flowtable.Family, nftable.Flowtable.Table, nftable.Flowtable.Name}, true
+	case nftable.Counter != nil:
+		return objectID{kindCounter, nftable.Counter.Family, nftable.Counter.Table, nftable.Counter.Name}, true
+	case nftable.Limit != nil:
+		return objectID{kindLimit, nftable.Limit.Family, nftable.Limit.Table, nftable.Limit.Name}, true
+	case nftable.Quota != nil:
+		return objectID{kindQuota, nftable.Quota.Family, nftable.Quota.Table, nftable.Quota.Name}, true
+	case nftable.Synproxy != nil:
+		return objectID{kindSynproxy, nftable.Synproxy.Family, nftable.Synproxy.Table, nftable.Synproxy.Name}, true
+	case nftable.CtHelper != nil:
+		return objectID{kindCtHelper, nftable.CtHelper.Family, nftable.CtHelper.Table, nftable.CtHelper.Name}, true
+	case nftable.CtTimeout != nil:
+		return objectID{kindCtTimeout, nftable.CtTimeout.Family, nftable.CtTimeout.Table, nftable.CtTimeout.Name}, true
+	}
+	return objectID{}, false
+}
+
+// objectsOf returns the object of the entry, to be used with an action.
+func objectsOf(nftable schema.Nftable) *schema.Objects {
+	return &schema.Objects{
+		Table:     nftable.Table,
+		Chain:     nftable.Chain,
+		Rule:      nftable.Rule,
+		Set:       nftable.Set,
+		Map:       nftable.Map,
+		Flowtable: nftable.Flowtable,
+		Counter:   nftable.Counter,
+		Limit:     nftable.Limit,
+		Quota:     nftable.Quota,
+		Synproxy:  nftable.Synproxy,
+		CtHelper:  nftable.CtHelper,
+		CtTimeout: nftable.CtTimeout,
+	}
+}
+
+// objectKey returns the comparison key of an object entry, ignoring its handle and stateful values.
+// Chains are compared in the form nft lists them (see withChainDefaults).
+func objectKey(nftable schema.Nftable) string {
+	return entryKey(withChainDefaults(withoutHandles(nftable)))
+}
+
+// withoutElements returns a copy of a set or map entry, excluding its elements.
+func withoutElements(nftable schema.Nftable) schema.Nftable {
+	if nftable.Set != nil {
+		set := *nftable.Set
+		set.Elem = nil
+		nftable.Set = &set
+	}
+	if nftable.Map != nil {
+		m := *nftable.Map
+		m.Elem = nil
+		nftable.Map = &m
+	}
+	return nftable
+}
+
+// ruleKey returns the comparison key of a rule, ignoring its handle, index and stateful values
+// (see withoutStatefulValues).
+// The statements are compared in their normalized and canonical form.
+func ruleKey(rule *schema.Rule) string {
+	r := *withoutStatefulValues(schema.Nftable{Rule: rule}).Rule
+	r.Handle, r.Index = nil, nil
+	r.Expr = NormalizeStatements(r.Expr)
+	if canonical, err := CanonicalizeStatements(r.Expr); err == nil {
+		r.Expr = canonical
+	}
+	data, _ := json.Marshal(r)
+	return string(data)
+}
+
+func withoutRuleHandle(rule *schema.Rule) *schema.Rule {
+	r := *rule
+	r.Handle = nil
+	return &r
+}
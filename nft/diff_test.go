@@ -0,0 +1,153 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+package nft_test
+
+import (
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/networkplumbing/go-nft/nft"
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+func TestDiff(t *testing.T) {
+	table := nft.NewTable(tableName, nft.FamilyIP)
+	chain := nft.NewRegularChain(table, chainName)
+	rule := func(comment string, handle int) *schema.Rule {
+		var h *int
+		if handle != 0 {
+			h = &handle
+		}
+		return nft.NewRule(table, chain, []schema.Statement{nft.NewCounter(), {Verdict: schema.Accept()}}, h, nil, comment)
+	}
+	newConfig := func(chain *schema.Chain, set *schema.Set, rules ...*schema.Rule) *nft.Config {
+		config := nft.NewConfig()
+		config.AddTable(table)
+		config.AddChain(chain)
+		if set != nil {
+			config.AddSet(set)
+		}
+		for _, r := range rules {
+			config.AddRule(r)
+		}
+		return config
+	}
+
+	t.Run("Diff identical configs", func(t *testing.T) {
+		current := newConfig(chain, nil, rule("a", 1), rule("b", 2))
+		current.Nftables[2].Rule.Expr[0].Counter.Packets = 5
+		desired := newConfig(chain, nil, rule("a", 0), rule("b", 0))
+
+		assert.Empty(t, nft.Diff(current, desired).Nftables)
+	})
+
+	t.Run("Diff added and removed tables", func(t *testing.T) {
+		otherTable := nft.NewTable("other-table", nft.FamilyIP)
+		current := newConfig(chain, nil)
+		current.AddTable(otherTable)
+		newTable := nft.NewTable("new-table", nft.FamilyINET)
+		desired := newConfig(chain, nil)
+		desired.AddTable(newTable)
+
+		expected := nft.NewConfig()
+		expected.DeleteTable(otherTable)
+		expected.AddTable(newTable)
+		assert.Equal(t, expected, nft.Diff(current, desired))
+	})
+
+	t.Run("Diff rules", func(t *testing.T) {
+		current := newConfig(chain, nil, rule("a", 1), rule("b", 2), rule("c", 3))
+		desired := newConfig(chain, nil, rule("a", 0), rule("x", 0), rule("z", 0), rule("c", 0), rule("y", 0))
+
+		expected := nft.NewConfig()
+		expected.DeleteRule(rule("b", 2))
+		expected.AddRule(rule("z", 1))
+		expected.AddRule(rule("x", 1))
+		expected.AddRule(rule("y", 3))
+		assert.Equal(t, expected, nft.Diff(current, desired))
+	})
+
+	t.Run("Diff rules added before the existing rules", func(t *testing.T) {
+		current := newConfig(chain, nil, rule("a", 1))
+		desired := newConfig(chain, nil, rule("x", 0), rule("a", 0))
+
+		expected := nft.NewConfig()
+		expected.InsertRule(rule("x", 1))
+		assert.Equal(t, expected, nft.Diff(current, desired))
+	})
+
+	t.Run("Diff rules with stateful values", func(t *testing.T) {
+		statefulRule := func(handle int) *schema.Rule {
+			r := rule("a", handle)
+			r.Expr = append([]schema.Statement{nft.NewQuota(1000, false), nft.NewLast()}, r.Expr...)
+			return r
+		}
+		current := newConfig(chain, nil, statefulRule(1))
+		current.Nftables[2].Rule.Expr[0].Quota.Used = 500
+		used := 300
+		current.Nftables[2].Rule.Expr[1].Last.Used = &used
+		desired := newConfig(chain, nil, statefulRule(0))
+
+		assert.Empty(t, nft.Diff(current, desired).Nftables)
+	})
+
+	t.Run("Diff base chains in their listed form", func(t *testing.T) {
+		ctype, hook, prio := nft.TypeFilter, nft.HookInput, 0
+		policy := nft.PolicyAccept
+		current := newConfig(nft.NewChain(table, chainName, &ctype, &hook, &prio, &policy), nil)
+		desiredChain := nft.ChainWithPriorityName(nft.NewChain(table, chainName, &ctype, &hook, nil, nil), nft.PriorityNameFilter)
+		desired := newConfig(desiredChain, nil)
+
+		assert.Empty(t, nft.Diff(current, desired).Nftables)
+	})
+
+	t.Run("Diff chain policy in place", func(t *testing.T) {
+		ctype, hook, prio := nft.TypeFilter, nft.HookInput, 0
+		policy, desiredPolicy := nft.PolicyAccept, nft.PolicyDrop
+		current := newConfig(nft.NewChain(table, chainName, &ctype, &hook, &prio, &policy), nil, rule("a", 1))
+		desiredChain := nft.NewChain(table, chainName, &ctype, &hook, &prio, &desiredPolicy)
+		desired := newConfig(desiredChain, nil, rule("a", 0))
+
+		expected := nft.NewConfig()
+		expected.AddChain(desiredChain)
+		assert.Equal(t, expected, nft.Diff(current, desired))
+	})
+
+	t.Run("Diff changed chain and set", func(t *testing.T) {
+		address1, address2 := "10.0.0.1", "10.0.0.2"
+		elements := []schema.Expression{{String: &address1}}
+		set := nft.NewSet(table, setName, nft.SetTypeIPv4Addr, nil, elements)
+		current := newConfig(chain, set, rule("a", 1))
+
+		ctype, hook, prio, policy := nft.TypeFilter, nft.HookInput, 0, nft.PolicyDrop
+		baseChain := nft.NewChain(table, chainName, &ctype, &hook, &prio, &policy)
+		desiredSet := nft.NewSet(table, setName, nft.SetTypeIPv4Addr, nil, append(elements, schema.Expression{String: &address2}))
+		desired := newConfig(baseChain, desiredSet, rule("a", 0))
+
+		expected := nft.NewConfig()
+		expected.FlushChain(chain)
+		expected.FlushSet(nft.NewSet(table, setName, nft.SetTypeIPv4Addr, nil, nil))
+		expected.DeleteChain(chain)
+		expected.AddSet(desiredSet)
+		expected.AddChain(baseChain)
+		expected.AddRule(rule("a", 0))
+		assert.Equal(t, expected, nft.Diff(current, desired))
+	})
+}
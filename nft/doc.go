@@ -43,6 +43,10 @@
 //
 // For full setup example, see the integration test: tests/config_test.go
 //
+// The net/netip helpers (e.g. `AddrExpression`, `PrefixExpression` and `MatchSrcPrefix`)
+// require Go 1.18 or later, which introduced the net/netip package.
+// They are not built by older Go versions.
+//
 // The nft package is dependent on the `nft` binary and the kernel nftables
 // support.
 package nft
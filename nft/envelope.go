@@ -0,0 +1,105 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ConfigVersion is the version of the config serialization, as stored in the envelope
+// created by ToEnvelope.
+// It is increased on schema changes which affect the serialized config,
+// together with a migration from the previous version.
+const ConfigVersion = 1
+
+// legacyConfigVersion is the version of configs which have been persisted
+// without an envelope (e.g. using ToJSON).
+const legacyConfigVersion = 0
+
+// ConfigMigration converts the serialized config of the version it is registered for,
+// to the serialized config of the following version.
+type ConfigMigration func(config json.RawMessage) (json.RawMessage, error)
+
+// configMigrations holds the library migrations, keyed by the version they migrate from.
+var configMigrations = map[int]ConfigMigration{
+	legacyConfigVersion: func(config json.RawMessage) (json.RawMessage, error) { return config, nil },
+}
+
+type configEnvelope struct {
+	Version *int            `json:"version"`
+	Config  json.RawMessage `json:"config"`
+}
+
+// ToEnvelope returns the JSON encoding of the nftables config,
+// wrapped in a versioned envelope: `{"version":1,"config":{"nftables":[...]}}`.
+// It is intended for persisting the config, which can be loaded by later versions of the library using FromEnvelope.
+func (c *Config) ToEnvelope() ([]byte, error) {
+	config, err := c.ToJSON()
+	if err != nil {
+		return nil, err
+	}
+	version := ConfigVersion
+	return json.Marshal(configEnvelope{Version: &version, Config: config})
+}
+
+// FromEnvelope decodes a config encoded by ToEnvelope, migrating it from older versions as needed.
+// Configs encoded by ToJSON (without an envelope) are accepted as well.
+func FromEnvelope(data []byte) (*Config, error) {
+	return FromEnvelopeWithMigrations(data, nil)
+}
+
+// FromEnvelopeWithMigrations decodes a config encoded by ToEnvelope, similar to FromEnvelope.
+// The given migrations are keyed by the version they migrate from and take precedence over the library ones,
+// allowing applications to adjust their persisted configs as well.
+func FromEnvelopeWithMigrations(data []byte, migrations map[int]ConfigMigration) (*Config, error) {
+	var envelope configEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, fmt.Errorf("invalid config envelope: %v", err)
+	}
+
+	version, config := legacyConfigVersion, json.RawMessage(data)
+	if envelope.Version != nil {
+		version, config = *envelope.Version, envelope.Config
+	}
+	if version > ConfigVersion {
+		return nil, fmt.Errorf("unsupported config version %d, expecting up to %d", version, ConfigVersion)
+	}
+
+	for ; version < ConfigVersion; version++ {
+		migration, exists := migrations[version]
+		if !exists {
+			migration, exists = configMigrations[version]
+		}
+		if !exists {
+			return nil, fmt.Errorf("missing migration of config version %d", version)
+		}
+		var err error
+		if config, err = migration(config); err != nil {
+			return nil, fmt.Errorf("failed to migrate config version %d: %v", version, err)
+		}
+	}
+
+	c := NewConfig()
+	if err := c.FromJSON(config); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
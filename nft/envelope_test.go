@@ -0,0 +1,78 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/networkplumbing/go-nft/nft"
+)
+
+func TestConfigEnvelope(t *testing.T) {
+	config := nft.NewConfig()
+	config.AddTable(nft.NewTable(tableName, nft.FamilyIP))
+	serializedConfig := fmt.Sprintf(`{"nftables":[{"table":{"family":"ip","name":%q}}]}`, tableName)
+
+	t.Run("Serialize a config to an envelope", func(t *testing.T) {
+		serialized, err := config.ToEnvelope()
+		assert.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf(`{"version":1,"config":%s}`, serializedConfig), string(serialized))
+	})
+
+	t.Run("Deserialize a config from an envelope", func(t *testing.T) {
+		deserializedConfig, err := nft.FromEnvelope([]byte(fmt.Sprintf(`{"version":1,"config":%s}`, serializedConfig)))
+		assert.NoError(t, err)
+		assert.Equal(t, config, deserializedConfig)
+	})
+
+	t.Run("Deserialize a config persisted without an envelope", func(t *testing.T) {
+		deserializedConfig, err := nft.FromEnvelope([]byte(serializedConfig))
+		assert.NoError(t, err)
+		assert.Equal(t, config, deserializedConfig)
+	})
+
+	t.Run("Deserialize a config with an application migration", func(t *testing.T) {
+		migrations := map[int]nft.ConfigMigration{
+			0: func(data json.RawMessage) (json.RawMessage, error) {
+				return json.RawMessage(fmt.Sprintf(`{"nftables":[{"table":{"family":"ip","name":%q}}]}`, tableName)), nil
+			},
+		}
+		deserializedConfig, err := nft.FromEnvelopeWithMigrations([]byte(`{"nftables":[]}`), migrations)
+		assert.NoError(t, err)
+		assert.Equal(t, config, deserializedConfig)
+	})
+
+	t.Run("Deserialize a config with a failing migration", func(t *testing.T) {
+		migrations := map[int]nft.ConfigMigration{
+			0: func(data json.RawMessage) (json.RawMessage, error) { return nil, fmt.Errorf("boom") },
+		}
+		_, err := nft.FromEnvelopeWithMigrations([]byte(serializedConfig), migrations)
+		assert.EqualError(t, err, "failed to migrate config version 0: boom")
+	})
+
+	t.Run("Deserialize a config from an envelope of a newer version", func(t *testing.T) {
+		_, err := nft.FromEnvelope([]byte(fmt.Sprintf(`{"version":2,"config":%s}`, serializedConfig)))
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,106 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+package nft
+
+import (
+	"fmt"
+
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+// Equal reports if the configs are semantically equal.
+// It is commonly used by reconcile loops, comparing the desired config with the one read from the system.
+// See Differences for the comparison semantics.
+func Equal(a, b *Config) bool {
+	return len(Differences(a, b)) == 0
+}
+
+// Differences returns a description of each difference between the configs, or nil when they are equal.
+//
+// The configs are compared semantically:
+//   - Objects (tables, chains, sets, etc) are compared regardless of their order in the config,
+//     ignoring their handles and stateful values (e.g. counter values).
+//     Base chains are compared in their listed form, with the default accept policy and resolved priority names.
+//   - Rules are compared in their relative order per chain, ignoring their handles, indexes and stateful values
+//     (e.g. counter values, quota used bytes and last used times).
+//     The statements are compared in their normalized and canonical form (see NormalizeStatements and
+//     CanonicalizeStatements).
+//   - Entries with actions (e.g. deletions) are compared in their relative order.
+//   - The metainfo entries are ignored.
+func Differences(a, b *Config) []string {
+	objectsA, objectsB := collectDeclaredObjects(a), collectDeclaredObjects(b)
+	var differences []string
+
+	for _, id := range objectsA.ids {
+		objectB, exists := objectsB.objects[id]
+		switch {
+		case !exists:
+			differences = append(differences, fmt.Sprintf("%s: only in the first config", id))
+		case objectKey(objectsA.objects[id]) != objectKey(objectB):
+			differences = append(differences, fmt.Sprintf("%s: declared differently", id))
+		}
+	}
+	for _, id := range objectsB.ids {
+		if _, exists := objectsA.objects[id]; !exists {
+			differences = append(differences, fmt.Sprintf("%s: only in the second config", id))
+		}
+	}
+
+	for _, id := range objectsA.ruleChains {
+		if position, differ := rulesDifference(objectsA.rules[id], objectsB.rules[id]); differ {
+			differences = append(differences, fmt.Sprintf("%s: rules differ at position %d", id, position))
+		}
+	}
+	for _, id := range objectsB.ruleChains {
+		if _, exists := objectsA.rules[id]; !exists {
+			differences = append(differences, fmt.Sprintf("%s: rules differ at position 0", id))
+		}
+	}
+
+	commandsA, commandsB := commandEntries(a), commandEntries(b)
+	for i := 0; i < len(commandsA) || i < len(commandsB); i++ {
+		if i >= len(commandsA) || i >= len(commandsB) || objectKey(commandsA[i]) != objectKey(commandsB[i]) {
+			differences = append(differences, fmt.Sprintf("command %d: differs", i))
+			break
+		}
+	}
+	return differences
+}
+
+// rulesDifference returns the position of the first differing rule, reporting if the rules differ.
+func rulesDifference(rulesA, rulesB []*schema.Rule) (int, bool) {
+	for i := 0; i < len(rulesA) || i < len(rulesB); i++ {
+		if i >= len(rulesA) || i >= len(rulesB) || ruleKey(rulesA[i]) != ruleKey(rulesB[i]) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// commandEntries returns the config entries which are neither objects, rules nor metainfo.
+func commandEntries(config *Config) []schema.Nftable {
+	var commands []schema.Nftable
+	for _, nftable := range config.Nftables {
+		if _, isObject := objectIdentity(nftable); isObject || nftable.Rule != nil || nftable.Metainfo != nil {
+			continue
+		}
+		commands = append(commands, nftable)
+	}
+	return commands
+}
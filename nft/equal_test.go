@@ -0,0 +1,110 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+package nft_test
+
+import (
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/networkplumbing/go-nft/nft"
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+func TestEqual(t *testing.T) {
+	table := nft.NewTable(tableName, nft.FamilyIP)
+	chain := nft.NewRegularChain(table, chainName)
+	set := nft.NewSet(table, setName, nft.SetTypeIPv4Addr, nil, nil)
+	acceptRule := func(comment string) *schema.Rule {
+		return nft.NewRule(table, chain, []schema.Statement{nft.NewCounter(), {Verdict: schema.Accept()}}, nil, nil, comment)
+	}
+
+	config := nft.NewConfig()
+	config.AddTable(table)
+	config.AddChain(chain)
+	config.AddRule(acceptRule("first"))
+	config.AddRule(acceptRule("second"))
+	config.AddSet(set)
+
+	t.Run("Equal configs", func(t *testing.T) {
+		// The listed form orders the objects differently, with handles and counter values.
+		listing := nft.NewConfig()
+		listing.Nftables = append(listing.Nftables, schema.Nftable{Metainfo: &schema.Metainfo{Version: "1.0.2"}})
+		listing.AddTable(table)
+		listing.AddSet(set)
+		listing.AddChain(chain)
+		for i, comment := range []string{"first", "second"} {
+			rule := acceptRule(comment)
+			handle := i + 4
+			rule.Handle = &handle
+			rule.Expr[0].Counter = &schema.Counter{Packets: 3, Bytes: 180}
+			listing.AddRule(rule)
+		}
+
+		assert.True(t, nft.Equal(config, listing))
+		assert.Empty(t, nft.Differences(config, listing))
+	})
+
+	t.Run("Equal configs in their listed form", func(t *testing.T) {
+		ctype, hook := nft.TypeFilter, nft.HookInput
+		baseChain := nft.ChainWithPriorityName(nft.NewChain(table, "base-chain", &ctype, &hook, nil, nil), nft.PriorityNameFilter)
+		statefulRule := func() *schema.Rule {
+			statements := []schema.Statement{nft.NewQuota(1000, false), nft.NewLast(), {Verdict: schema.Accept()}}
+			return nft.NewRule(table, baseChain, statements, nil, nil, "stateful")
+		}
+		desired := nft.NewConfig()
+		desired.AddTable(table)
+		desired.AddChain(baseChain)
+		desired.AddRule(statefulRule())
+
+		// nft lists the base chain with the accept policy and the numeric priority, and the rule stateful values.
+		prio, policy := nft.PriorityFilter, nft.PolicyAccept
+		listing := nft.NewConfig()
+		listing.AddTable(table)
+		listing.AddChain(nft.NewChain(table, "base-chain", &ctype, &hook, &prio, &policy))
+		rule := statefulRule()
+		used := 1500
+		rule.Expr[0].Quota.Used = 600
+		rule.Expr[1].Last.Used = &used
+		listing.AddRule(rule)
+
+		assert.Empty(t, nft.Differences(desired, listing))
+	})
+
+	t.Run("Different configs", func(t *testing.T) {
+		other := nft.NewConfig()
+		other.AddTable(table)
+		changedChain := nft.NewRegularChain(table, chainName)
+		changedChain.Comment = "changed"
+		other.AddChain(changedChain)
+		other.AddRule(acceptRule("second"))
+		other.AddRule(acceptRule("first"))
+		other.AddMap(nft.NewVerdictMap(table, mapName, nft.SetTypeIPv4Addr, nil))
+		other.FlushSet(set)
+
+		assert.False(t, nft.Equal(config, other))
+		assert.Equal(t, []string{
+			"chain ip test-table test-chain: declared differently",
+			"set ip test-table test-set: only in the first config",
+			"map ip test-table test-map: only in the second config",
+			"chain ip test-table test-chain: rules differ at position 0",
+			"command 0: differs",
+		}, nft.Differences(config, other))
+	})
+}
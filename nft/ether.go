@@ -0,0 +1,88 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+const macAddressLen = 6
+
+// EtherType is an Ethernet frame payload protocol, as matched by `ether type` and `vlan type`.
+// The types are encoded by name, as nft lists them.
+type EtherType string
+
+// Ether Types
+const (
+	EtherTypeIPv4 EtherType = "ip"
+	EtherTypeIPv6 EtherType = "ip6"
+	EtherTypeARP  EtherType = "arp"
+	EtherTypeVLAN EtherType = "vlan"
+)
+
+// MACAddress returns an expression of the given Ethernet (EUI-48) address, as accepted by net.ParseMAC.
+// The address is normalized to the nft representation (e.g. `52:54:00:12:34:56`).
+func MACAddress(mac string) (schema.Expression, error) {
+	hwAddr, err := net.ParseMAC(mac)
+	if err != nil {
+		return schema.Expression{}, err
+	}
+	if len(hwAddr) != macAddressLen {
+		return schema.Expression{}, fmt.Errorf("invalid ethernet address %q", mac)
+	}
+	address := hwAddr.String()
+	return schema.Expression{String: &address}, nil
+}
+
+// EtherPayload returns an expression of the given Ethernet header field (e.g. `ether saddr`).
+func EtherPayload(field string) schema.Expression {
+	return schema.Expression{Payload: &schema.Payload{
+		Protocol: schema.PayloadProtocolEther,
+		Field:    field,
+	}}
+}
+
+// MatchEtherAddress returns a match statement of the Ethernet source or destination address
+// (e.g. `ether saddr 52:54:00:12:34:56`).
+// It is commonly used in bridge family chains to filter the traffic of a specific interface, like a VM NIC.
+func MatchEtherAddress(field AddressField, mac string) (schema.Statement, error) {
+	address, err := MACAddress(mac)
+	if err != nil {
+		return schema.Statement{}, err
+	}
+	return schema.Statement{Match: &schema.Match{
+		Op:    schema.OperEQ,
+		Left:  EtherPayload(string(field)),
+		Right: address,
+	}}, nil
+}
+
+// MatchEtherType returns a match statement of the Ethernet frame payload protocol (e.g. `ether type arp`).
+func MatchEtherType(etherType EtherType) schema.Statement {
+	value := string(etherType)
+	return schema.Statement{Match: &schema.Match{
+		Op:    schema.OperEQ,
+		Left:  EtherPayload(schema.PayloadFieldEtherType),
+		Right: schema.Expression{String: &value},
+	}}
+}
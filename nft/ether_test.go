@@ -0,0 +1,96 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/networkplumbing/go-nft/nft"
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+func TestMACAddress(t *testing.T) {
+	t.Run("Normalize address", func(t *testing.T) {
+		address, err := nft.MACAddress("52-54-00-AB-CD-EF")
+		assert.NoError(t, err)
+		assert.Equal(t, "52:54:00:ab:cd:ef", *address.String)
+	})
+
+	t.Run("Invalid address", func(t *testing.T) {
+		_, err := nft.MACAddress("52:54:00")
+		assert.Error(t, err)
+	})
+
+	t.Run("Non EUI-48 address", func(t *testing.T) {
+		_, err := nft.MACAddress("02:00:5e:10:00:00:00:01")
+		assert.Error(t, err)
+	})
+}
+
+func TestMatchEtherAddress(t *testing.T) {
+	const serialized = `{"match":{"op":"==","left":{"payload":{"protocol":"ether","field":"saddr"}},"right":"52:54:00:12:34:56"}}`
+
+	statement, err := nft.MatchEtherAddress(nft.AddressFieldSource, "52:54:00:12:34:56")
+	assert.NoError(t, err)
+
+	t.Run("check serialization", func(t *testing.T) {
+		serializedStatement, err := json.Marshal(statement)
+		assert.NoError(t, err)
+		assert.Equal(t, serialized, string(serializedStatement))
+	})
+
+	t.Run("check deserialization", func(t *testing.T) {
+		var deserialized schema.Statement
+		assert.NoError(t, json.Unmarshal([]byte(serialized), &deserialized))
+		assert.Equal(t, statement, deserialized)
+	})
+
+	t.Run("ether type expression", func(t *testing.T) {
+		serializedExpression, err := json.Marshal(nft.EtherPayload(schema.PayloadFieldEtherType))
+		assert.NoError(t, err)
+		assert.Equal(t, `{"payload":{"protocol":"ether","field":"type"}}`, string(serializedExpression))
+	})
+}
+
+func TestMatchEtherType(t *testing.T) {
+	etherTypes := []nft.EtherType{nft.EtherTypeIPv4, nft.EtherTypeIPv6, nft.EtherTypeARP, nft.EtherTypeVLAN}
+	for _, etherType := range etherTypes {
+		etherType := etherType
+		serialized := fmt.Sprintf(
+			`{"match":{"op":"==","left":{"payload":{"protocol":"ether","field":"type"}},"right":%q}}`, etherType,
+		)
+
+		t.Run("Match ether type "+string(etherType)+", check serialization", func(t *testing.T) {
+			serializedStatement, err := json.Marshal(nft.MatchEtherType(etherType))
+			assert.NoError(t, err)
+			assert.Equal(t, serialized, string(serializedStatement))
+		})
+
+		t.Run("Match ether type "+string(etherType)+", check deserialization", func(t *testing.T) {
+			var deserialized schema.Statement
+			assert.NoError(t, json.Unmarshal([]byte(serialized), &deserialized))
+			assert.Equal(t, nft.MatchEtherType(etherType), deserialized)
+		})
+	}
+}
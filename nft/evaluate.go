@@ -0,0 +1,428 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+// The kernel limits the jump stack depth, evaluation follows the same limit.
+const maxJumpDepth = 16
+
+// Packet describes the traffic properties which are used to evaluate a configuration.
+// Unset fields (empty strings or zero ports) are considered unknown and never satisfy a match.
+type Packet struct {
+	IIFName  string
+	OIFName  string
+	Protocol string
+	SrcIP    string
+	DstIP    string
+	SrcPort  int
+	DstPort  int
+}
+
+// RuleRef identifies a rule which matched a packet during an evaluation.
+type RuleRef struct {
+	Chain string
+	// Position is the rule position in the chain, starting from 0.
+	Position int
+	Comment  string
+}
+
+// EvalResult is the outcome of a packet evaluation.
+type EvalResult struct {
+	// Verdict is the final verdict, either accept or drop.
+	Verdict string
+	// Path lists the rules which matched the packet, in the order they have been traversed.
+	Path []RuleRef
+}
+
+// Evaluate traverses the given chain with the packet and returns the resulting verdict,
+// accompanied with the path of the matching rules.
+// The rules are evaluated in the order they appear in the configuration.
+// When no terminal verdict is reached, the chain policy is used (accept by default).
+// Only a subset of the expressions is supported (interface names, L4 protocol, addresses and ports),
+// a rule which uses other expressions results with an error.
+func (c *Config) Evaluate(chain *schema.Chain, packet Packet) (*EvalResult, error) {
+	e := evaluator{config: c, packet: packet, result: &EvalResult{}}
+	verdict, err := e.evalChain(chain.Family, chain.Table, chain.Name, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if verdict == "" {
+		verdict = schema.PolicyAccept
+		baseChain := c.LookupChain(&schema.Chain{Family: chain.Family, Table: chain.Table, Name: chain.Name})
+		if baseChain != nil && baseChain.Policy != "" {
+			verdict = baseChain.Policy
+		}
+	}
+	e.result.Verdict = verdict
+
+	return e.result, nil
+}
+
+type evaluator struct {
+	config *Config
+	packet Packet
+	result *EvalResult
+}
+
+// evalChain returns the terminal verdict reached in the chain,
+// or an empty string when the evaluation should continue at the calling chain.
+func (e *evaluator) evalChain(family, table, chain string, depth int) (string, error) {
+	if depth > maxJumpDepth {
+		return "", fmt.Errorf("chain %q: maximum jump depth exceeded", chain)
+	}
+
+	position := 0
+	for _, nftable := range e.config.Nftables {
+		rule := nftable.Rule
+		if rule == nil || rule.Family != family || rule.Table != table || rule.Chain != chain {
+			continue
+		}
+		rulePosition := position
+		position++
+
+		matched, verdict, err := e.evalRule(rule)
+		if err != nil {
+			return "", fmt.Errorf("chain %q rule %d: %v", chain, rulePosition, err)
+		}
+		if !matched {
+			continue
+		}
+		e.result.Path = append(e.result.Path, RuleRef{Chain: chain, Position: rulePosition, Comment: rule.Comment})
+
+		if verdict == nil {
+			continue
+		}
+		switch {
+		case verdict.Accept:
+			return schema.VerdictAccept, nil
+		case verdict.Drop:
+			return schema.VerdictDrop, nil
+		case verdict.Return:
+			return "", nil
+		case verdict.Jump != nil:
+			v, err := e.evalChain(family, table, verdict.Jump.Target, depth+1)
+			if err != nil || v != "" {
+				return v, err
+			}
+		case verdict.Goto != nil:
+			return e.evalChain(family, table, verdict.Goto.Target, depth+1)
+		}
+	}
+
+	return "", nil
+}
+
+// evalRule reports if the rule matches the packet and the verdict of the rule (if any).
+// Statements following a verdict are unreachable and therefore ignored.
+func (e *evaluator) evalRule(rule *schema.Rule) (bool, *schema.Verdict, error) {
+	for i := range rule.Expr {
+		statement := &rule.Expr[i]
+		if statement.Match != nil {
+			matched, err := e.evalMatch(statement.Match)
+			if err != nil || !matched {
+				return false, nil, err
+			}
+		}
+		if isVerdict(statement.Verdict) {
+			return true, &statement.Verdict, nil
+		}
+	}
+	return true, nil, nil
+}
+
+func isVerdict(v schema.Verdict) bool {
+	return v.Accept || v.Continue || v.Drop || v.Return || v.Jump != nil || v.Goto != nil
+}
+
+func (e *evaluator) evalMatch(match *schema.Match) (bool, error) {
+	protocol, field, err := matchKey(match.Left)
+	if err != nil {
+		return false, err
+	}
+
+	switch protocol {
+	case schema.PayloadProtocolIP4, schema.PayloadProtocolIP6:
+		switch field {
+		case schema.PayloadFieldIPSAddr:
+			return matchAddress(protocol, e.packet.SrcIP, match)
+		case schema.PayloadFieldIPDAddr:
+			return matchAddress(protocol, e.packet.DstIP, match)
+		case schema.PayloadFieldIP4Protocol, schema.PayloadFieldIP6NextHdr:
+			if !isAddressOfProtocol(protocol, e.packet.SrcIP) {
+				return false, nil
+			}
+			return matchProtocol(e.packet.Protocol, match)
+		}
+	case schema.PayloadProtocolTCP, schema.PayloadProtocolUDP:
+		if e.packet.Protocol != protocol {
+			return false, nil
+		}
+		// The TCP and UDP port fields share the same names.
+		switch field {
+		case schema.PayloadFieldTCPSPort:
+			return matchPort(e.packet.SrcPort, match)
+		case schema.PayloadFieldTCPDPort:
+			return matchPort(e.packet.DstPort, match)
+		}
+	case schema.MetaKey:
+		switch field {
+		case schema.MetaKeyIIFName:
+			return matchName(e.packet.IIFName, match)
+		case schema.MetaKeyOIFName:
+			return matchName(e.packet.OIFName, match)
+		case schema.MetaKeyL4Proto:
+			return matchProtocol(e.packet.Protocol, match)
+		}
+	}
+
+	return false, fmt.Errorf("unsupported match expression: %s %s", protocol, field)
+}
+
+// matchKey returns the protocol and field of the left side match expression.
+// Meta expressions are returned with the `meta` protocol and the meta key as the field.
+func matchKey(left schema.Expression) (string, string, error) {
+	if p := left.Payload; p != nil {
+		return p.Protocol, p.Field, nil
+	}
+	if m := left.Meta; m != nil {
+		return schema.MetaKey, m.Key, nil
+	}
+	if left.RowData != nil {
+		var expression schema.Expression
+		if err := json.Unmarshal(left.RowData, &expression); err == nil && expression.Meta != nil {
+			return schema.MetaKey, expression.Meta.Key, nil
+		}
+	}
+	data, _ := json.Marshal(left)
+	return "", "", fmt.Errorf("unsupported left expression: %s", data)
+}
+
+func matchAddress(protocol string, address string, match *schema.Match) (bool, error) {
+	if !isAddressOfProtocol(protocol, address) {
+		return false, nil
+	}
+	ip := net.ParseIP(address)
+
+	network, err := rightNetwork(match.Right)
+	if err != nil {
+		return false, err
+	}
+
+	switch match.Op {
+	case schema.OperEQ:
+		return network.Contains(ip), nil
+	case schema.OperNEQ:
+		return !network.Contains(ip), nil
+	}
+	return false, fmt.Errorf("unsupported address match operator: %q", match.Op)
+}
+
+// isAddressOfProtocol reports if the address is valid and belongs to the IP protocol (ip or ip6).
+func isAddressOfProtocol(protocol string, address string) bool {
+	ip := net.ParseIP(address)
+	return ip != nil && (ip.To4() != nil) == (protocol == schema.PayloadProtocolIP4)
+}
+
+func rightNetwork(right schema.Expression) (*net.IPNet, error) {
+	var addr string
+	prefixLen := -1
+	switch {
+	case right.String != nil:
+		addr = *right.String
+		if strings.Contains(addr, "/") {
+			_, network, err := net.ParseCIDR(addr)
+			return network, err
+		}
+	case right.RowData != nil:
+		var prefix struct {
+			Prefix *struct {
+				Addr string `json:"addr"`
+				Len  int    `json:"len"`
+			} `json:"prefix"`
+		}
+		if err := json.Unmarshal(right.RowData, &prefix); err != nil || prefix.Prefix == nil {
+			return nil, fmt.Errorf("unsupported address expression: %s", right.RowData)
+		}
+		addr, prefixLen = prefix.Prefix.Addr, prefix.Prefix.Len
+	default:
+		return nil, fmt.Errorf("unsupported address expression")
+	}
+
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid address: %q", addr)
+	}
+	bits := 8 * net.IPv6len
+	if ip4 := ip.To4(); ip4 != nil {
+		ip, bits = ip4, 8*net.IPv4len
+	}
+	if prefixLen < 0 {
+		prefixLen = bits
+	}
+	mask := net.CIDRMask(prefixLen, bits)
+	if mask == nil {
+		return nil, fmt.Errorf("invalid prefix length: %d", prefixLen)
+	}
+	return &net.IPNet{IP: ip.Mask(mask), Mask: mask}, nil
+}
+
+func matchPort(port int, match *schema.Match) (bool, error) {
+	if port == 0 {
+		return false, nil
+	}
+
+	if set := match.Right.Set; set != nil {
+		return matchPortSet(port, set, match.Op)
+	}
+	if r := match.Right.Range; r != nil {
+		return matchPortRange(port, r, match.Op)
+	}
+
+	value, err := portValue(match.Right)
+	if err != nil {
+		return false, err
+	}
+
+	switch match.Op {
+	case schema.OperEQ:
+		return port == value, nil
+	case schema.OperNEQ:
+		return port != value, nil
+	case schema.OperLS:
+		return port < value, nil
+	case schema.OperGR:
+		return port > value, nil
+	case schema.OperLSE:
+		return port <= value, nil
+	case schema.OperGRE:
+		return port >= value, nil
+	}
+	return false, fmt.Errorf("unsupported port match operator: %q", match.Op)
+}
+
+// matchPortSet reports if the port is (or is not) one of the anonymous set elements.
+func matchPortSet(port int, set []schema.Expression, op string) (bool, error) {
+	found := false
+	for _, element := range set {
+		matched, err := matchPort(port, &schema.Match{Op: schema.OperEQ, Right: element})
+		if err != nil {
+			return false, err
+		}
+		found = found || matched
+	}
+
+	switch op {
+	case schema.OperEQ, schema.OperIN:
+		return found, nil
+	case schema.OperNEQ:
+		return !found, nil
+	}
+	return false, fmt.Errorf("unsupported port set match operator: %q", op)
+}
+
+// matchPortRange reports if the port is (or is not) in the inclusive range.
+func matchPortRange(port int, r *[2]schema.Expression, op string) (bool, error) {
+	from, err := portValue(r[0])
+	if err != nil {
+		return false, err
+	}
+	to, err := portValue(r[1])
+	if err != nil {
+		return false, err
+	}
+	inRange := from <= port && port <= to
+
+	switch op {
+	case schema.OperEQ, schema.OperIN:
+		return inRange, nil
+	case schema.OperNEQ:
+		return !inRange, nil
+	}
+	return false, fmt.Errorf("unsupported port range match operator: %q", op)
+}
+
+func portValue(expression schema.Expression) (int, error) {
+	switch {
+	case expression.Float64 != nil:
+		return int(*expression.Float64), nil
+	case expression.String != nil:
+		v, err := strconv.Atoi(*expression.String)
+		if err != nil {
+			return 0, fmt.Errorf("unsupported port value: %q", *expression.String)
+		}
+		return v, nil
+	}
+	return 0, fmt.Errorf("unsupported port expression")
+}
+
+// matchProtocol compares the transport protocol with a protocol given by name or number (e.g. `tcp` or 6).
+func matchProtocol(protocol string, match *schema.Match) (bool, error) {
+	if protocol == "" {
+		return false, nil
+	}
+	value, known := protocolNumber(match.Right)
+	if !known {
+		return false, fmt.Errorf("unsupported protocol expression")
+	}
+	number, known := protocolNumber(schema.Expression{String: &protocol})
+	equal := known && number == value
+
+	switch match.Op {
+	case schema.OperEQ:
+		return equal, nil
+	case schema.OperNEQ:
+		return !equal, nil
+	}
+	return false, fmt.Errorf("unsupported protocol match operator: %q", match.Op)
+}
+
+// matchName compares names, supporting the nft trailing wildcard notation (e.g. `eth*`).
+func matchName(name string, match *schema.Match) (bool, error) {
+	if name == "" {
+		return false, nil
+	}
+	if match.Right.String == nil {
+		return false, fmt.Errorf("unsupported name expression")
+	}
+
+	value := *match.Right.String
+	equal := name == value
+	if strings.HasSuffix(value, "*") {
+		equal = strings.HasPrefix(name, strings.TrimSuffix(value, "*"))
+	}
+
+	switch match.Op {
+	case schema.OperEQ:
+		return equal, nil
+	case schema.OperNEQ:
+		return !equal, nil
+	}
+	return false, fmt.Errorf("unsupported name match operator: %q", match.Op)
+}
@@ -0,0 +1,213 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/networkplumbing/go-nft/nft"
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+func TestEvaluate(t *testing.T) {
+	config, inputChain := buildEvaluationConfig()
+
+	t.Run("Evaluate packet accepted by a jump target rule", func(t *testing.T) {
+		result, err := config.Evaluate(inputChain, nft.Packet{
+			Protocol: schema.PayloadProtocolTCP, SrcIP: "192.0.2.1", DstIP: "203.0.113.1", DstPort: 22,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, schema.VerdictAccept, result.Verdict)
+		assert.Equal(t, []nft.RuleRef{
+			{Chain: "input", Position: 1, Comment: "to services"},
+			{Chain: "services", Position: 0, Comment: "ssh"},
+		}, result.Path)
+	})
+
+	t.Run("Evaluate packet matched by address prefix", func(t *testing.T) {
+		result, err := config.Evaluate(inputChain, nft.Packet{
+			Protocol: schema.PayloadProtocolTCP, SrcIP: "10.1.2.3", DstIP: "203.0.113.1", DstPort: 443,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, schema.VerdictAccept, result.Verdict)
+		assert.Equal(t, "https from internal", result.Path[len(result.Path)-1].Comment)
+	})
+
+	t.Run("Evaluate packet falling to the chain policy", func(t *testing.T) {
+		result, err := config.Evaluate(inputChain, nft.Packet{
+			Protocol: schema.PayloadProtocolTCP, SrcIP: "192.0.2.1", DstIP: "203.0.113.1", DstPort: 443,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, schema.VerdictDrop, result.Verdict)
+		assert.Equal(t, []nft.RuleRef{{Chain: "input", Position: 1, Comment: "to services"}}, result.Path)
+	})
+
+	t.Run("Evaluate packet matched by interface name", func(t *testing.T) {
+		result, err := config.Evaluate(inputChain, nft.Packet{IIFName: "lo"})
+		assert.NoError(t, err)
+		assert.Equal(t, schema.VerdictAccept, result.Verdict)
+		assert.Equal(t, []nft.RuleRef{{Chain: "input", Position: 0, Comment: "loopback"}}, result.Path)
+	})
+
+	t.Run("Evaluate packet matched by an anonymous port set", func(t *testing.T) {
+		c := nft.NewConfig()
+		table := nft.NewTable(tableName, nft.FamilyIP)
+		chain := nft.NewRegularChain(table, chainName)
+		http, https := float64(80), "443"
+		c.AddRule(nft.NewRule(table, chain, []schema.Statement{
+			{Match: &schema.Match{
+				Op:    schema.OperEQ,
+				Left:  schema.Expression{Payload: &schema.Payload{Protocol: schema.PayloadProtocolTCP, Field: schema.PayloadFieldTCPDPort}},
+				Right: nft.AnonymousSet(schema.Expression{Float64: &http}, schema.Expression{String: &https}),
+			}},
+			{Verdict: schema.Drop()},
+		}, nil, nil, "web"))
+
+		result, err := c.Evaluate(chain, nft.Packet{Protocol: schema.PayloadProtocolTCP, DstPort: 443})
+		assert.NoError(t, err)
+		assert.Equal(t, schema.VerdictDrop, result.Verdict)
+
+		result, err = c.Evaluate(chain, nft.Packet{Protocol: schema.PayloadProtocolTCP, DstPort: 22})
+		assert.NoError(t, err)
+		assert.Empty(t, result.Path)
+	})
+
+	t.Run("Evaluate packet matched by a port range", func(t *testing.T) {
+		c := nft.NewConfig()
+		table := nft.NewTable(tableName, nft.FamilyIP)
+		chain := nft.NewRegularChain(table, chainName)
+		c.AddRule(nft.NewRule(table, chain, []schema.Statement{
+			{Match: &schema.Match{
+				Op:    schema.OperEQ,
+				Left:  schema.Expression{Payload: &schema.Payload{Protocol: schema.PayloadProtocolTCP, Field: schema.PayloadFieldTCPDPort}},
+				Right: nft.NumberRange(1024, 65535),
+			}},
+			{Verdict: schema.Drop()},
+		}, nil, nil, "unprivileged"))
+
+		result, err := c.Evaluate(chain, nft.Packet{Protocol: schema.PayloadProtocolTCP, DstPort: 1024})
+		assert.NoError(t, err)
+		assert.Equal(t, schema.VerdictDrop, result.Verdict)
+
+		result, err = c.Evaluate(chain, nft.Packet{Protocol: schema.PayloadProtocolTCP, DstPort: 443})
+		assert.NoError(t, err)
+		assert.Empty(t, result.Path)
+	})
+
+	t.Run("Evaluate packet matched by a protocol number", func(t *testing.T) {
+		c := nft.NewConfig()
+		table := nft.NewTable(tableName, nft.FamilyIP)
+		chain := nft.NewRegularChain(table, chainName)
+		tcp, udp := float64(6), "udp"
+		c.AddRule(nft.NewRule(table, chain, []schema.Statement{
+			{Match: &schema.Match{
+				Op:    schema.OperEQ,
+				Left:  schema.Expression{Meta: &schema.Meta{Key: schema.MetaKeyL4Proto}},
+				Right: schema.Expression{Float64: &tcp},
+			}},
+			{Verdict: schema.Drop()},
+		}, nil, nil, "tcp"))
+		c.AddRule(nft.NewRule(table, chain, []schema.Statement{
+			{Match: &schema.Match{
+				Op:    schema.OperNEQ,
+				Left:  schema.Expression{Payload: &schema.Payload{Protocol: schema.PayloadProtocolIP4, Field: schema.PayloadFieldIP4Protocol}},
+				Right: schema.Expression{String: &udp},
+			}},
+			{Verdict: schema.Accept()},
+		}, nil, nil, "not udp"))
+
+		result, err := c.Evaluate(chain, nft.Packet{Protocol: schema.PayloadProtocolTCP, SrcIP: "192.0.2.1"})
+		assert.NoError(t, err)
+		assert.Equal(t, schema.VerdictDrop, result.Verdict)
+
+		result, err = c.Evaluate(chain, nft.Packet{Protocol: "icmp", SrcIP: "192.0.2.1"})
+		assert.NoError(t, err)
+		assert.Equal(t, schema.VerdictAccept, result.Verdict)
+		assert.Equal(t, "not udp", result.Path[len(result.Path)-1].Comment)
+	})
+
+	t.Run("Evaluate rule with unsupported expression", func(t *testing.T) {
+		c := nft.NewConfig()
+		table := nft.NewTable(tableName, nft.FamilyIP)
+		chain := nft.NewRegularChain(table, chainName)
+		c.AddRule(nft.NewRule(table, chain, []schema.Statement{{Match: &schema.Match{
+			Op:    schema.OperEQ,
+			Left:  schema.Expression{RowData: json.RawMessage(`{"foo":"boo"}`)},
+			Right: schema.Expression{String: &ifaceLo},
+		}}}, nil, nil, ""))
+
+		_, err := c.Evaluate(chain, nft.Packet{IIFName: "lo"})
+		assert.Error(t, err)
+	})
+}
+
+var ifaceLo = "lo"
+
+func buildEvaluationConfig() (*nft.Config, *schema.Chain) {
+	config := nft.NewConfig()
+	table := nft.NewTable(tableName, nft.FamilyIP)
+	config.AddTable(table)
+
+	ctype, hook, prio, policy := nft.TypeFilter, nft.HookInput, 0, nft.PolicyDrop
+	inputChain := nft.NewChain(table, "input", &ctype, &hook, &prio, &policy)
+	config.AddChain(inputChain)
+	servicesChain := nft.NewRegularChain(table, "services")
+	config.AddChain(servicesChain)
+
+	config.AddRule(nft.NewRule(table, inputChain, []schema.Statement{
+		{Match: &schema.Match{
+			Op:    schema.OperEQ,
+			Left:  schema.Expression{RowData: json.RawMessage(`{"meta":{"key":"iifname"}}`)},
+			Right: schema.Expression{String: &ifaceLo},
+		}},
+		{Verdict: schema.Accept()},
+	}, nil, nil, "loopback"))
+	config.AddRule(nft.NewRule(table, inputChain, []schema.Statement{
+		{Verdict: schema.Verdict{Jump: &schema.ToTarget{Target: servicesChain.Name}}},
+	}, nil, nil, "to services"))
+
+	sshPort, httpsPort, internalNet := float64(22), float64(443), "10.0.0.0/8"
+	config.AddRule(nft.NewRule(table, servicesChain, []schema.Statement{
+		{Match: &schema.Match{
+			Op:    schema.OperEQ,
+			Left:  schema.Expression{Payload: &schema.Payload{Protocol: schema.PayloadProtocolTCP, Field: schema.PayloadFieldTCPDPort}},
+			Right: schema.Expression{Float64: &sshPort},
+		}},
+		{Verdict: schema.Accept()},
+	}, nil, nil, "ssh"))
+	config.AddRule(nft.NewRule(table, servicesChain, []schema.Statement{
+		{Match: &schema.Match{
+			Op:    schema.OperEQ,
+			Left:  schema.Expression{Payload: &schema.Payload{Protocol: schema.PayloadProtocolIP4, Field: schema.PayloadFieldIPSAddr}},
+			Right: schema.Expression{String: &internalNet},
+		}},
+		{Match: &schema.Match{
+			Op:    schema.OperEQ,
+			Left:  schema.Expression{Payload: &schema.Payload{Protocol: schema.PayloadProtocolTCP, Field: schema.PayloadFieldTCPDPort}},
+			Right: schema.Expression{Float64: &httpsPort},
+		}},
+		{Verdict: schema.Accept()},
+	}, nil, nil, "https from internal"))
+
+	return config, inputChain
+}
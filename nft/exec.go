@@ -21,32 +21,122 @@ package nft
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"io"
+	"os"
 	"os/exec"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/networkplumbing/go-nft/nft/schema"
 )
 
 const (
-	cmdBin     = "nft"
-	cmdFile    = "-f"
-	cmdJSON    = "-j"
-	cmdList    = "list"
-	cmdRuleset = "ruleset"
-	cmdStdin   = "-"
+	cmdBin      = "nft"
+	cmdFile     = "-f"
+	cmdJSON     = "-j"
+	cmdList     = "list"
+	cmdRuleset  = "ruleset"
+	cmdSet      = "set"
+	cmdSets     = "sets"
+	cmdCounters = "counters"
+	cmdTable    = "table"
+	cmdStdin    = "-"
+	cmdVersion  = "--version"
+	cmdEcho     = "--echo"
+	cmdHandle   = "--handle"
+
+	nsenterBin = "nsenter"
+	nsenterNet = "--net="
 )
 
+// executor runs the `nft` executable, as configured by the client options.
+// The zero value runs the `nft` executable found in the PATH, with the process environment.
+type executor struct {
+	path string
+	args []string
+	env  []string
+	// netns is the path of the network namespace (e.g. `/var/run/netns/pod`) in which `nft` is executed.
+	netns string
+}
+
+// defaultExecutor is the executor of the package level operations.
+var defaultExecutor = &executor{}
+
+// inNetNS returns a copy of the executor, which executes `nft` in the given network namespace.
+func (e executor) inNetNS(nsPath string) *executor {
+	e.netns = nsPath
+	return &e
+}
+
 // ReadConfig loads the nftables configuration from the system and
 // returns it as a nftables config structure.
 // The system is expected to have the `nft` executable deployed and nftables enabled in the kernel.
 func ReadConfig() (*Config, error) {
-	stdout, err := execCommand(nil, cmdJSON, cmdList, cmdRuleset)
+	return ReadConfigContext(context.Background())
+}
+
+// ReadConfigContext loads the nftables configuration from the system, similar to ReadConfig.
+// The `nft` execution is killed when the context is done before it completes,
+// allowing callers to enforce deadlines and cancellation.
+func ReadConfigContext(ctx context.Context) (*Config, error) {
+	return defaultExecutor.readConfig(ctx, cmdRuleset)
+}
+
+// ReadSets loads the sets of the given table from the system and
+// returns them as a nftables config structure.
+// The set elements are not listed.
+// Listing a single object kind avoids the parsing of the whole ruleset.
+// The system is expected to have the `nft` executable deployed and nftables enabled in the kernel.
+func ReadSets(table *schema.Table) (*Config, error) {
+	return defaultExecutor.readConfig(context.Background(), cmdSets, cmdTable, table.Family, table.Name)
+}
+
+// ReadCounters loads the named counters of the given table from the system and
+// returns them as a nftables config structure.
+// Listing a single object kind avoids the parsing of the whole ruleset.
+// The system is expected to have the `nft` executable deployed and nftables enabled in the kernel.
+func ReadCounters(table *schema.Table) (*Config, error) {
+	return defaultExecutor.readConfig(context.Background(), cmdCounters, cmdTable, table.Family, table.Name)
+}
+
+// DeleteRulesWhere deletes from the system the rules of the given table and chain which satisfy the predicate,
+// in a single transaction (see Config.DeleteRulesWhere).
+// Only the given table is read from the system.
+// It returns the number of deleted rules.
+// The system is expected to have the `nft` executable deployed and nftables enabled in the kernel.
+func DeleteRulesWhere(table *schema.Table, chain *schema.Chain, predicate func(*schema.Rule) bool) (int, error) {
+	return defaultExecutor.deleteRulesWhere(table, chain, predicate)
+}
+
+func (e *executor) deleteRulesWhere(table *schema.Table, chain *schema.Chain, predicate func(*schema.Rule) bool) (int, error) {
+	current, err := e.readConfig(context.Background(), cmdTable, table.Family, table.Name)
+	if err != nil {
+		return 0, err
+	}
+
+	config := NewConfig()
+	deleted := config.DeleteRulesWhere(current, table, chain, predicate)
+	if deleted == 0 {
+		return 0, nil
+	}
+	if err := e.applyConfig(context.Background(), config); err != nil {
+		return 0, err
+	}
+	return deleted, nil
+}
+
+func (e *executor) readConfig(ctx context.Context, listArgs ...string) (*Config, error) {
+	stdout, _, err := e.runCommand(ctx, nil, append([]string{cmdJSON, cmdList}, listArgs...)...)
 	if err != nil {
 		return nil, err
 	}
 
 	config := NewConfig()
 	if err := config.FromJSON(stdout.Bytes()); err != nil {
-		return nil, fmt.Errorf("failed to list ruleset: %v", err)
+		return nil, fmt.Errorf("failed to list %s: %v", strings.Join(listArgs, " "), err)
 	}
 
 	return config, nil
@@ -54,21 +144,183 @@ func ReadConfig() (*Config, error) {
 
 // ApplyConfig applies the given nftables config on the system.
 // The system is expected to have the `nft` executable deployed and nftables enabled in the kernel.
+// On failure, the first failed command is identified by an ApplyError.
 func ApplyConfig(c *Config) error {
+	return ApplyConfigContext(context.Background(), c)
+}
+
+// ApplyConfigContext applies the given nftables config on the system, similar to ApplyConfig.
+// The `nft` execution is killed when the context is done before it completes,
+// allowing callers to enforce deadlines and cancellation.
+// The returned error then wraps the context error (e.g. context.DeadlineExceeded), see errors.Is.
+// The kernel applies the config in a single transaction, therefore a killed execution
+// applied either the whole config or none of it, but the caller cannot tell which happened:
+// the config may have been committed before the execution was killed.
+// Reading the config back from the system (see ReadConfig) reveals its state.
+func ApplyConfigContext(ctx context.Context, c *Config) error {
+	return defaultExecutor.applyConfig(ctx, c)
+}
+
+func (e *executor) applyConfig(ctx context.Context, c *Config) error {
 	data, err := c.ToJSON()
 	if err != nil {
 		return err
 	}
 
-	if _, err := execCommand(data, cmdJSON, cmdFile, cmdStdin); err != nil {
-		return err
+	if _, stderr, err := e.runCommand(ctx, data, cmdJSON, cmdFile, cmdStdin); err != nil {
+		return e.applyError(ctx, c, stderr, err)
 	}
 
 	return nil
 }
 
-func execCommand(input []byte, args ...string) (*bytes.Buffer, error) {
-	cmd := exec.Command(cmdBin, args...)
+// ApplyConfigEcho applies the given nftables config on the system, similar to ApplyConfig,
+// and returns the applied commands as echoed by `nft`, with the kernel assigned handles populated.
+// nft echoes the given entries in their given form and order, adding the handles to the created objects and rules,
+// therefore each echoed entry is at the position of the given entry it describes (the metainfo entries are removed).
+// It allows callers to later delete or replace exactly the objects and rules they have created.
+// The given config is not mutated.
+// The system is expected to have the `nft` executable deployed and nftables enabled in the kernel.
+func ApplyConfigEcho(c *Config) (*Config, error) {
+	return ApplyConfigEchoContext(context.Background(), c)
+}
+
+// ApplyConfigEchoContext applies the given nftables config on the system and returns the applied commands,
+// similar to ApplyConfigEcho.
+// The `nft` execution is killed when the context is done before it completes (see ApplyConfigContext).
+func ApplyConfigEchoContext(ctx context.Context, c *Config) (*Config, error) {
+	return defaultExecutor.applyConfigEcho(ctx, c)
+}
+
+func (e *executor) applyConfigEcho(ctx context.Context, c *Config) (*Config, error) {
+	data, err := c.ToJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	stdout, stderr, err := e.runCommand(ctx, data, cmdJSON, cmdEcho, cmdHandle, cmdFile, cmdStdin)
+	if err != nil {
+		return nil, e.applyError(ctx, c, stderr, err)
+	}
+
+	echoed := NewConfig()
+	if stdout.Len() == 0 {
+		return echoed, nil
+	}
+	listed := NewConfig()
+	if err := listed.FromJSON(stdout.Bytes()); err != nil {
+		return nil, fmt.Errorf("failed to parse the echoed config: %v", err)
+	}
+	for _, nftable := range listed.Nftables {
+		if nftable.Metainfo == nil {
+			echoed.Nftables = append(echoed.Nftables, nftable)
+		}
+	}
+	return echoed, nil
+}
+
+// ReadConfigInNetNS loads the nftables configuration of the given network namespace,
+// similar to ReadConfig.
+// The namespace is given by its path, e.g. `/var/run/netns/<name>` or `/proc/<pid>/ns/net`.
+// The system is expected to have the `nsenter` executable deployed, in addition to the `nft` executable.
+func ReadConfigInNetNS(nsPath string) (*Config, error) {
+	return defaultExecutor.inNetNS(nsPath).readConfig(context.Background(), cmdRuleset)
+}
+
+// ApplyConfigInNetNS applies the given nftables config in the given network namespace,
+// similar to ApplyConfig (see ReadConfigInNetNS).
+// It is commonly used by CNI plugins to program the per-pod namespaces.
+func ApplyConfigInNetNS(nsPath string, c *Config) error {
+	return defaultExecutor.inNetNS(nsPath).applyConfig(context.Background(), c)
+}
+
+// ApplyResult describes a successful application of a config on the system.
+// It does not include the kernel assigned handles of the created objects and rules, see ApplyConfigEcho.
+type ApplyResult struct {
+	// Commands is the number of commands in the applied config.
+	Commands int
+	// Duration is the time it took to apply the config.
+	Duration time.Duration
+	// NftVersion is the version of the `nft` executable which applied the config (e.g. "v1.0.2").
+	NftVersion string
+	// Warnings lists the config raw commands (see RawCommandWarnings) and the warnings reported by `nft`.
+	Warnings []string
+}
+
+// ApplyConfigResult applies the given nftables config on the system, similar to ApplyConfig,
+// and on success returns the details of the application.
+// The system is expected to have the `nft` executable deployed and nftables enabled in the kernel.
+func ApplyConfigResult(c *Config) (*ApplyResult, error) {
+	return defaultExecutor.applyConfigResult(c)
+}
+
+func (e *executor) applyConfigResult(c *Config) (*ApplyResult, error) {
+	data, err := c.ToJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := e.nftVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	_, stderr, err := e.runCommand(context.Background(), data, cmdJSON, cmdFile, cmdStdin)
+	if err != nil {
+		return nil, e.applyError(context.Background(), c, stderr, err)
+	}
+
+	result := &ApplyResult{
+		Commands:   len(c.Nftables),
+		Duration:   time.Since(start),
+		NftVersion: version,
+		Warnings:   c.RawCommandWarnings(),
+	}
+	for _, line := range strings.Split(stderr.String(), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			result.Warnings = append(result.Warnings, line)
+		}
+	}
+	return result, nil
+}
+
+// nftVersions caches the versions of the `nft` executables, per executor configuration (see nftVersionKey),
+// sparing an execution per applied config.
+var nftVersions sync.Map
+
+// nftVersion returns the version of the `nft` executable, as reported by it (e.g. `nftables v1.0.2 (Lester Gooch)`).
+// The version is read once per executor configuration.
+func (e *executor) nftVersion() (string, error) {
+	key := e.nftVersionKey()
+	if version, cached := nftVersions.Load(key); cached {
+		return version.(string), nil
+	}
+
+	stdout, err := e.execCommand(nil, cmdVersion)
+	if err != nil {
+		return "", err
+	}
+	fields := strings.Fields(stdout.String())
+	if len(fields) < 2 {
+		return "", fmt.Errorf("failed to parse the nft version: %q", stdout.String())
+	}
+	nftVersions.Store(key, fields[1])
+	return fields[1], nil
+}
+
+// nftVersionKey identifies the executor configuration which determines the `nft` executable that is run.
+func (e *executor) nftVersionKey() string {
+	return strings.Join(append([]string{e.executable(), e.netns}, e.env...), "\x00")
+}
+
+func (e *executor) execCommand(input []byte, args ...string) (*bytes.Buffer, error) {
+	stdout, _, err := e.runCommand(context.Background(), input, args...)
+	return stdout, err
+}
+
+func (e *executor) runCommand(ctx context.Context, input []byte, args ...string) (*bytes.Buffer, *bytes.Buffer, error) {
+	cmd := e.command(ctx, args...)
 
 	var stdout, stderr bytes.Buffer
 	cmd.Stderr = &stderr
@@ -81,11 +333,74 @@ func execCommand(input []byte, args ...string) (*bytes.Buffer, error) {
 	}
 
 	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf(
-			"failed to execute %s %s: %v stdin:'%s' stdout:'%s' stderr:'%s'",
+		// The process error of a killed command (e.g. `signal: killed`) does not reveal the reason.
+		// The context error is wrapped, allowing callers to check it using errors.Is.
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			err = ctxErr
+		}
+		return nil, &stderr, fmt.Errorf(
+			"failed to execute %s %s: %w stdin:'%s' stdout:'%s' stderr:'%s'",
 			cmd.Path, strings.Join(cmd.Args, " "), err, string(input), stdout.String(), stderr.String(),
 		)
 	}
 
-	return &stdout, nil
+	return &stdout, &stderr, nil
+}
+
+// execCommandStream starts the command and returns its stdout stream, allowing it to be consumed
+// while the command is running.
+// The returned wait function must be called once the stream is no longer needed,
+// it releases the command resources and reports its execution error (if any).
+// The command is killed when the context is done before it completes.
+func (e *executor) execCommandStream(ctx context.Context, args ...string) (io.Reader, func() error, error) {
+	cmd := e.command(ctx, args...)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, nil, fmt.Errorf("failed to execute %s %s: %v", cmd.Path, strings.Join(cmd.Args, " "), err)
+	}
+
+	wait := func() error {
+		// Drain the unconsumed output, allowing the command to complete.
+		_, _ = io.Copy(io.Discard, stdout)
+		if err := cmd.Wait(); err != nil {
+			return fmt.Errorf(
+				"failed to execute %s %s: %v stderr:'%s'", cmd.Path, strings.Join(cmd.Args, " "), err, stderr.String(),
+			)
+		}
+		return nil
+	}
+	return stdout, wait, nil
+}
+
+// command returns the `nft` command of the given arguments, preceded by the executor extra arguments.
+// The executor environment variables are added to the process environment.
+// When a network namespace is set, the command is executed by `nsenter`, entering the namespace first.
+// Unlike switching the namespace of the calling thread (using setns), it requires no OS thread locking
+// and cannot leave the calling process in the target namespace.
+func (e *executor) command(ctx context.Context, args ...string) *exec.Cmd {
+	args = append(append([]string(nil), e.args...), args...)
+	var cmd *exec.Cmd
+	if e.netns != "" {
+		cmd = exec.CommandContext(ctx, nsenterBin, append([]string{nsenterNet + e.netns, "--", e.executable()}, args...)...)
+	} else {
+		cmd = exec.CommandContext(ctx, e.executable(), args...)
+	}
+	if len(e.env) > 0 {
+		cmd.Env = append(os.Environ(), e.env...)
+	}
+	return cmd
+}
+
+func (e *executor) executable() string {
+	if e.path != "" {
+		return e.path
+	}
+	return cmdBin
 }
@@ -0,0 +1,347 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+// Package firewall provides a high-level firewall on top of the nft package,
+// for users who prefer zone based semantics (in the spirit of firewalld) over the nftables internals.
+//
+// A Firewall is composed of zones, which group the incoming traffic by interfaces or source addresses,
+// the services which are allowed or denied in each zone and port forwards.
+// It compiles to a single inet family table, which is owned by the firewall:
+//   - The `input` chain accepts the established traffic and the loopback traffic,
+//     and jumps to the zone chain matching the traffic interface or source address.
+//     The traffic of no zone is accepted, unless a default zone exists.
+//   - A `zone-<name>` chain per zone, applying the zone services and the zone target to the remaining traffic.
+//   - The `prerouting` chain (only when port forwards exist), translating the destination of the forwarded ports.
+package firewall
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/networkplumbing/go-nft/nft"
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+type Action string
+
+// Actions
+const (
+	ActionAllow Action = "allow"
+	ActionDeny  Action = "deny"
+)
+
+const (
+	inputChainName      = "input"
+	preroutingChainName = "prerouting"
+	zoneChainPrefix     = "zone-"
+	loopbackInterface   = "lo"
+)
+
+// Zone is a group of incoming traffic, identified by the input interfaces or the source addresses
+// (IP addresses or CIDR prefixes, of any IP version).
+// A zone with neither interfaces nor sources is the default zone, matching the traffic of no other zone.
+type Zone struct {
+	Name       string
+	Interfaces []string
+	Sources    []string
+	// Target is the action applied to the zone traffic which matches no service, deny by default.
+	Target Action
+}
+
+// PortForward forwards the traffic of a (tcp or udp) port to another address and (optional) port.
+// The forward applies to the traffic of the given zone, or to all the traffic when no zone is given.
+type PortForward struct {
+	Zone      string
+	Service   nft.Service
+	ToAddress net.IP
+	// ToPort is the translated port, a zero port keeps the original port.
+	ToPort int
+}
+
+// Firewall is a zone based firewall, compiled to an nftables table of the given name.
+type Firewall struct {
+	name         string
+	zones        []*zone
+	portForwards []PortForward
+}
+
+type zone struct {
+	Zone
+	services []serviceRule
+}
+
+type serviceRule struct {
+	service nft.Service
+	action  Action
+}
+
+// New returns an empty firewall, compiled to an inet family table of the given name.
+func New(name string) *Firewall {
+	return &Firewall{name: name}
+}
+
+// AddZone adds the zone to the firewall.
+// It fails when a zone with the same name already exists, or when the zone is invalid
+// (e.g. a source which is neither an IP address nor a CIDR prefix).
+func (f *Firewall) AddZone(z Zone) error {
+	if z.Name == "" {
+		return fmt.Errorf("zone name is missing")
+	}
+	if f.lookupZone(z.Name) != nil {
+		return fmt.Errorf("zone %q: already exists", z.Name)
+	}
+	if z.Target == "" {
+		z.Target = ActionDeny
+	}
+	if z.Target != ActionAllow && z.Target != ActionDeny {
+		return fmt.Errorf("zone %q: unsupported target %q", z.Name, z.Target)
+	}
+	for _, source := range z.Sources {
+		if sourceAddress(source) == nil {
+			return fmt.Errorf("zone %q: invalid source %q", z.Name, source)
+		}
+	}
+	if isDefaultZone(z) {
+		for _, existing := range f.zones {
+			if isDefaultZone(existing.Zone) {
+				return fmt.Errorf("zone %q: default zone %q already exists", z.Name, existing.Name)
+			}
+		}
+	}
+	z.Interfaces = append([]string(nil), z.Interfaces...)
+	z.Sources = append([]string(nil), z.Sources...)
+	f.zones = append(f.zones, &zone{Zone: z})
+	return nil
+}
+
+// AllowService allows the traffic of the service in the zone.
+func (f *Firewall) AllowService(zoneName string, service nft.Service) error {
+	return f.addService(zoneName, service, ActionAllow)
+}
+
+// DenyService denies the traffic of the service in the zone.
+// It is useful in zones which allow their traffic by default.
+func (f *Firewall) DenyService(zoneName string, service nft.Service) error {
+	return f.addService(zoneName, service, ActionDeny)
+}
+
+// AddPortForward adds the port forward to the firewall.
+func (f *Firewall) AddPortForward(forward PortForward) error {
+	if forward.Zone != "" && f.lookupZone(forward.Zone) == nil {
+		return fmt.Errorf("port forward: zone %q does not exist", forward.Zone)
+	}
+	if err := validateService(forward.Service); err != nil {
+		return fmt.Errorf("port forward: %v", err)
+	}
+	if forward.ToAddress == nil {
+		return fmt.Errorf("port forward: target address is missing")
+	}
+	if forward.ToPort < 0 || forward.ToPort > 65535 {
+		return fmt.Errorf("port forward: invalid target port %d", forward.ToPort)
+	}
+	f.portForwards = append(f.portForwards, forward)
+	return nil
+}
+
+// Config returns the nftables configuration of the firewall.
+// The configuration adds the firewall table, chains and rules.
+// It fails when a port forward cannot apply to its zone, i.e. the zone is matched only by sources
+// of the other IP version than the target address.
+// To replace a previously applied firewall, its table should be deleted first (e.g. using Config.DeleteTable).
+func (f *Firewall) Config() (*nft.Config, error) {
+	config := nft.NewConfig()
+	table := nft.NewTable(f.name, nft.FamilyINET)
+	config.AddTable(table)
+
+	input := newBaseChain(table, inputChainName, nft.TypeFilter, nft.HookInput, nft.PriorityFilter)
+	config.AddChain(input)
+	config.AddRule(nft.NewRule(table, input, []schema.Statement{matchEstablished(), nft.Accept()}, nil, nil, ""))
+	config.AddRule(nft.NewRule(table, input, []schema.Statement{nft.MatchIIFName(loopbackInterface), nft.Accept()}, nil, nil, ""))
+
+	var defaultZone *zone
+	for _, z := range f.zones {
+		chain := nft.NewRegularChain(table, zoneChainPrefix+z.Name)
+		config.AddChain(chain)
+		for _, rule := range z.services {
+			statements := []schema.Statement{matchService(rule.service), verdictOf(rule.action)}
+			config.AddRule(nft.NewRule(table, chain, statements, nil, nil, ""))
+		}
+		config.AddRule(nft.NewRule(table, chain, []schema.Statement{verdictOf(z.Target)}, nil, nil, ""))
+
+		if isDefaultZone(z.Zone) {
+			defaultZone = z
+			continue
+		}
+		rules, err := zoneRules(table, input, z.Zone, []schema.Statement{nft.Jump(chain)})
+		if err != nil {
+			return nil, err
+		}
+		addRules(config, rules)
+	}
+	if defaultZone != nil {
+		chain := nft.NewRegularChain(table, zoneChainPrefix+defaultZone.Name)
+		config.AddRule(nft.NewRule(table, input, []schema.Statement{nft.Jump(chain)}, nil, nil, ""))
+	}
+
+	if len(f.portForwards) > 0 {
+		prerouting := newBaseChain(table, preroutingChainName, nft.TypeNAT, nft.HookPreRouting, nft.PriorityNATDest)
+		config.AddChain(prerouting)
+		for _, forward := range f.portForwards {
+			statements := []schema.Statement{matchService(forward.Service), nft.DNATTo(forward.ToAddress, forward.ToPort)}
+			z := Zone{}
+			if forward.Zone != "" {
+				z = f.lookupZone(forward.Zone).Zone
+			}
+			// The translation is specific to the target address version,
+			// therefore the sources of the other version are not forwarded.
+			if sources := sourcesOfVersion(z.Sources, forward.ToAddress.To4() != nil); len(sources) < len(z.Sources) {
+				if len(sources) == 0 && len(z.Interfaces) == 0 {
+					return nil, fmt.Errorf("port forward to %s: zone %q has no source of the target address version",
+						forward.ToAddress, z.Name)
+				}
+				z.Sources = sources
+			}
+			rules, err := zoneRules(table, prerouting, z, statements)
+			if err != nil {
+				return nil, err
+			}
+			addRules(config, rules)
+		}
+	}
+
+	return config, nil
+}
+
+func (f *Firewall) addService(zoneName string, service nft.Service, action Action) error {
+	z := f.lookupZone(zoneName)
+	if z == nil {
+		return fmt.Errorf("zone %q: does not exist", zoneName)
+	}
+	if err := validateService(service); err != nil {
+		return fmt.Errorf("zone %q: %v", zoneName, err)
+	}
+	z.services = append(z.services, serviceRule{service: service, action: action})
+	return nil
+}
+
+func (f *Firewall) lookupZone(name string) *zone {
+	for _, z := range f.zones {
+		if z.Name == name {
+			return z
+		}
+	}
+	return nil
+}
+
+func isDefaultZone(z Zone) bool {
+	return len(z.Interfaces) == 0 && len(z.Sources) == 0
+}
+
+// zoneRules returns the rules which match the zone traffic, followed by the given statements.
+// The zone interfaces and sources are matched by separate rules,
+// while the traffic of a default zone is matched unconditionally.
+func zoneRules(table *schema.Table, chain *schema.Chain, z Zone, statements []schema.Statement) ([]*schema.Rule, error) {
+	if isDefaultZone(z) {
+		return []*schema.Rule{nft.NewRule(table, chain, statements, nil, nil, "")}, nil
+	}
+
+	var rules []*schema.Rule
+	if len(z.Interfaces) > 0 {
+		match := nft.MatchIIFName(z.Interfaces[0])
+		if len(z.Interfaces) > 1 {
+			var interfaces []schema.Expression
+			for _, iface := range z.Interfaces {
+				iface := iface
+				interfaces = append(interfaces, schema.Expression{String: &iface})
+			}
+			match.Match.Right = nft.AnonymousSet(interfaces...)
+		}
+		rules = append(rules, nft.NewRule(table, chain, append([]schema.Statement{match}, statements...), nil, nil, ""))
+	}
+	if len(z.Sources) > 0 {
+		sourceRules, err := nft.NewAddressRules(table, chain, nft.AddressFieldSource, z.Sources, statements, "")
+		if err != nil {
+			return nil, fmt.Errorf("zone %q: %v", z.Name, err)
+		}
+		rules = append(rules, sourceRules...)
+	}
+	return rules, nil
+}
+
+func sourcesOfVersion(sources []string, ipv4 bool) []string {
+	var filtered []string
+	for _, source := range sources {
+		if (sourceAddress(source).To4() != nil) == ipv4 {
+			filtered = append(filtered, source)
+		}
+	}
+	return filtered
+}
+
+// sourceAddress returns the address of the source, an IP address or a CIDR prefix,
+// or nil when the source is invalid.
+func sourceAddress(source string) net.IP {
+	if ip, _, err := net.ParseCIDR(source); err == nil {
+		return ip
+	}
+	return net.ParseIP(source)
+}
+
+func addRules(config *nft.Config, rules []*schema.Rule) {
+	for _, rule := range rules {
+		config.AddRule(rule)
+	}
+}
+
+func newBaseChain(table *schema.Table, name string, ctype nft.ChainType, hook nft.ChainHook, prio int) *schema.Chain {
+	policy := nft.PolicyAccept
+	return nft.NewChain(table, name, &ctype, &hook, &prio, &policy)
+}
+
+func validateService(service nft.Service) error {
+	if service.Protocol != schema.PayloadProtocolTCP && service.Protocol != schema.PayloadProtocolUDP {
+		return fmt.Errorf("unsupported protocol %q", service.Protocol)
+	}
+	if service.Port < 1 || service.Port > 65535 {
+		return fmt.Errorf("invalid port %d", service.Port)
+	}
+	return nil
+}
+
+func matchService(service nft.Service) schema.Statement {
+	return nft.MatchPort(service.DestinationPort(), nft.Port(service.Port))
+}
+
+// matchEstablished returns a match statement of the established and related traffic
+// (`ct state established,related`).
+func matchEstablished() schema.Statement {
+	established, related := "established", "related"
+	return schema.Statement{Match: &schema.Match{
+		Op:    schema.OperEQ,
+		Left:  schema.Expression{Ct: &schema.Ct{Key: schema.CtKeyState}},
+		Right: nft.AnonymousSet(schema.Expression{String: &established}, schema.Expression{String: &related}),
+	}}
+}
+
+func verdictOf(action Action) schema.Statement {
+	if action == ActionAllow {
+		return nft.Accept()
+	}
+	return nft.Drop()
+}
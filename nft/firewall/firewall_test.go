@@ -0,0 +1,162 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package firewall_test
+
+import (
+	"encoding/json"
+	"net"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/networkplumbing/go-nft/nft"
+	"github.com/networkplumbing/go-nft/nft/firewall"
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+const firewallName = "test-firewall"
+
+var (
+	ssh    = nft.Service{Protocol: schema.PayloadProtocolTCP, Port: 22}
+	telnet = nft.Service{Protocol: schema.PayloadProtocolTCP, Port: 23}
+	web    = nft.Service{Protocol: schema.PayloadProtocolTCP, Port: 8080}
+)
+
+func TestFirewall(t *testing.T) {
+	t.Run("Empty firewall", func(t *testing.T) {
+		config, err := firewall.New(firewallName).Config()
+		assert.NoError(t, err)
+		assert.NoError(t, config.Validate())
+
+		assert.Equal(t, []string{"input"}, chainNames(config))
+		assert.Equal(t, []string{
+			`[{"match":{"op":"==","left":{"ct":{"key":"state"}},"right":{"set":["established","related"]}}},{"accept":null}]`,
+			`[{"match":{"op":"==","left":{"meta":{"key":"iifname"}},"right":"lo"}},{"accept":null}]`,
+		}, chainRules(t, config, "input"))
+	})
+
+	t.Run("Zones with services and port forwards", func(t *testing.T) {
+		fw := firewall.New(firewallName)
+		assert.NoError(t, fw.AddZone(firewall.Zone{Name: "public", Interfaces: []string{"eth0", "eth1"}}))
+		assert.NoError(t, fw.AddZone(firewall.Zone{
+			Name:    "trusted",
+			Sources: []string{"10.0.0.0/8", "2001:db8::/32"},
+			Target:  firewall.ActionAllow,
+		}))
+		assert.NoError(t, fw.AddZone(firewall.Zone{Name: "default"}))
+		assert.NoError(t, fw.AllowService("public", ssh))
+		assert.NoError(t, fw.DenyService("trusted", telnet))
+		assert.NoError(t, fw.AddPortForward(firewall.PortForward{
+			Zone:      "trusted",
+			Service:   web,
+			ToAddress: net.ParseIP("10.0.0.2"),
+			ToPort:    80,
+		}))
+
+		config, err := fw.Config()
+		assert.NoError(t, err)
+		assert.NoError(t, config.Validate())
+
+		assert.Equal(t, []string{"input", "zone-public", "zone-trusted", "zone-default", "prerouting"}, chainNames(config))
+		assert.Equal(t, []string{
+			`[{"match":{"op":"==","left":{"ct":{"key":"state"}},"right":{"set":["established","related"]}}},{"accept":null}]`,
+			`[{"match":{"op":"==","left":{"meta":{"key":"iifname"}},"right":"lo"}},{"accept":null}]`,
+			`[{"match":{"op":"==","left":{"meta":{"key":"iifname"}},"right":{"set":["eth0","eth1"]}}},` +
+				`{"jump":{"target":"zone-public"}}]`,
+			`[{"match":{"op":"==","left":{"payload":{"protocol":"ip","field":"saddr"}},` +
+				`"right":{"prefix":{"addr":"10.0.0.0","len":8}}}},{"jump":{"target":"zone-trusted"}}]`,
+			`[{"match":{"op":"==","left":{"payload":{"protocol":"ip6","field":"saddr"}},` +
+				`"right":{"prefix":{"addr":"2001:db8::","len":32}}}},{"jump":{"target":"zone-trusted"}}]`,
+			`[{"jump":{"target":"zone-default"}}]`,
+		}, chainRules(t, config, "input"))
+		assert.Equal(t, []string{
+			`[{"match":{"op":"==","left":{"payload":{"protocol":"tcp","field":"dport"}},"right":22}},{"accept":null}]`,
+			`[{"drop":null}]`,
+		}, chainRules(t, config, "zone-public"))
+		assert.Equal(t, []string{
+			`[{"match":{"op":"==","left":{"payload":{"protocol":"tcp","field":"dport"}},"right":23}},{"drop":null}]`,
+			`[{"accept":null}]`,
+		}, chainRules(t, config, "zone-trusted"))
+		assert.Equal(t, []string{`[{"drop":null}]`}, chainRules(t, config, "zone-default"))
+		assert.Equal(t, []string{
+			`[{"match":{"op":"==","left":{"payload":{"protocol":"ip","field":"saddr"}},` +
+				`"right":{"prefix":{"addr":"10.0.0.0","len":8}}}},` +
+				`{"match":{"op":"==","left":{"payload":{"protocol":"tcp","field":"dport"}},"right":8080}},` +
+				`{"dnat":{"addr":"10.0.0.2","family":"ip","port":80}}]`,
+		}, chainRules(t, config, "prerouting"))
+	})
+
+	t.Run("Invalid zones, services and port forwards", func(t *testing.T) {
+		fw := firewall.New(firewallName)
+		assert.NoError(t, fw.AddZone(firewall.Zone{Name: "public", Interfaces: []string{"eth0"}}))
+		assert.NoError(t, fw.AddZone(firewall.Zone{Name: "default"}))
+
+		assert.Error(t, fw.AddZone(firewall.Zone{}))
+		assert.Error(t, fw.AddZone(firewall.Zone{Name: "public", Interfaces: []string{"eth1"}}))
+		assert.Error(t, fw.AddZone(firewall.Zone{Name: "other-default"}))
+		assert.Error(t, fw.AddZone(firewall.Zone{Name: "reject", Interfaces: []string{"eth2"}, Target: "reject"}))
+
+		assert.Error(t, fw.AllowService("missing", ssh))
+		assert.Error(t, fw.AllowService("public", nft.Service{Protocol: "icmp", Port: 22}))
+		assert.Error(t, fw.DenyService("public", nft.Service{Protocol: schema.PayloadProtocolUDP, Port: 0}))
+
+		assert.Error(t, fw.AddPortForward(firewall.PortForward{Zone: "missing", Service: web, ToAddress: net.ParseIP("10.0.0.2")}))
+		assert.Error(t, fw.AddPortForward(firewall.PortForward{Service: web}))
+		assert.Error(t, fw.AddPortForward(firewall.PortForward{Service: web, ToAddress: net.ParseIP("10.0.0.2"), ToPort: 65536}))
+	})
+
+	t.Run("Invalid zone source", func(t *testing.T) {
+		fw := firewall.New(firewallName)
+		assert.EqualError(t, fw.AddZone(firewall.Zone{Name: "public", Sources: []string{"10.0.0"}}),
+			`zone "public": invalid source "10.0.0"`)
+	})
+
+	t.Run("Port forward to a zone of sources of the other IP version", func(t *testing.T) {
+		fw := firewall.New(firewallName)
+		assert.NoError(t, fw.AddZone(firewall.Zone{Name: "trusted", Sources: []string{"2001:db8::/32"}}))
+		assert.NoError(t, fw.AddPortForward(firewall.PortForward{
+			Zone:      "trusted",
+			Service:   web,
+			ToAddress: net.ParseIP("10.0.0.2"),
+		}))
+
+		_, err := fw.Config()
+		assert.EqualError(t, err, `port forward to 10.0.0.2: zone "trusted" has no source of the target address version`)
+	})
+}
+
+func chainNames(config *nft.Config) []string {
+	var names []string
+	for _, chain := range config.Chains(nil) {
+		names = append(names, chain.Name)
+	}
+	return names
+}
+
+func chainRules(t *testing.T, config *nft.Config, chainName string) []string {
+	table := nft.NewTable(firewallName, nft.FamilyINET)
+	var rules []string
+	for _, rule := range config.LookupRulesInChain(table, nft.NewRegularChain(table, chainName)) {
+		serialized, err := json.Marshal(rule.Expr)
+		assert.NoError(t, err)
+		rules = append(rules, string(serialized))
+	}
+	return rules
+}
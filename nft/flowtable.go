@@ -0,0 +1,79 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft
+
+import (
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+// NewFlowtable returns a new schema flowtable structure,
+// bound to the ingress hook of the given devices.
+func NewFlowtable(table *schema.Table, name string, prio int, devices []string) *schema.Flowtable {
+	return &schema.Flowtable{
+		Family: table.Family,
+		Table:  table.Name,
+		Name:   name,
+		Hook:   schema.HookIngress,
+		Prio:   &prio,
+		Dev:    devices,
+	}
+}
+
+// AddFlowtable appends the given flowtable to the nftable config.
+// The flowtable is added without an explicit action (`add`).
+func (c *Config) AddFlowtable(flowtable *schema.Flowtable) {
+	nftable := schema.Nftable{Flowtable: flowtable}
+	c.Nftables = append(c.Nftables, nftable)
+}
+
+// DeleteFlowtable appends a given flowtable to the nftable config
+// with the `delete` action.
+// Attempting to delete a non-existing flowtable, results with a failure when the config is applied.
+func (c *Config) DeleteFlowtable(flowtable *schema.Flowtable) {
+	nftable := schema.Nftable{Delete: &schema.Objects{Flowtable: flowtable}}
+	c.Nftables = append(c.Nftables, nftable)
+}
+
+// LookupFlowtable searches the configuration for a matching flowtable and returns it.
+// The flowtable is matched first by the table and flowtable name.
+// Other matching fields are optional.
+// The devices are compared regardless of their order, therefore a flowtable which lost
+// a device binding (e.g. the interface has been removed) no longer matches.
+// Mutating the returned flowtable will result in mutating the configuration.
+func (c *Config) LookupFlowtable(toFind *schema.Flowtable) *schema.Flowtable {
+	for _, nftable := range c.Nftables {
+		if flowtable := nftable.Flowtable; flowtable != nil {
+			match := flowtable.Table == toFind.Table && flowtable.Family == toFind.Family && flowtable.Name == toFind.Name
+			if h := toFind.Hook; match && h != "" {
+				match = flowtable.Hook == h
+			}
+			if p := toFind.Prio; match && p != nil {
+				match = flowtable.Prio != nil && *flowtable.Prio == *p
+			}
+			if d := toFind.Dev; match && len(d) > 0 {
+				match = areDevicesEqual(flowtable.Dev, d)
+			}
+			if match {
+				return flowtable
+			}
+		}
+	}
+	return nil
+}
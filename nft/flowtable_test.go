@@ -0,0 +1,103 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/networkplumbing/go-nft/nft"
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+const flowtableName = "test-flowtable"
+
+func TestFlowtable(t *testing.T) {
+	testFlowtableSerialization(t)
+	testFlowtableLookup(t)
+}
+
+func testFlowtableSerialization(t *testing.T) {
+	table := nft.NewTable(tableName, nft.FamilyINET)
+	flowtable := nft.NewFlowtable(table, flowtableName, 0, []string{"eth0", "eth1"})
+	flowtableArgs := fmt.Sprintf(
+		`"family":"inet","table":%q,"name":%q,"hook":"ingress","prio":0,"dev":["eth0","eth1"]`, tableName, flowtableName,
+	)
+
+	t.Run("Add flowtable, check serialization", func(t *testing.T) {
+		config := nft.NewConfig()
+		config.AddFlowtable(flowtable)
+
+		serialized, err := config.ToJSON()
+		assert.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf(`{"nftables":[{"flowtable":{%s}}]}`, flowtableArgs), string(serialized))
+	})
+
+	t.Run("Add flowtable, check deserialization", func(t *testing.T) {
+		var deserializedConfig nft.Config
+		serializedConfig := fmt.Sprintf(`{"nftables":[{"flowtable":{%s}}]}`, flowtableArgs)
+		assert.NoError(t, json.Unmarshal([]byte(serializedConfig), &deserializedConfig))
+
+		expectedConfig := nft.NewConfig()
+		expectedConfig.AddFlowtable(flowtable)
+		assert.Equal(t, expectedConfig, &deserializedConfig)
+	})
+
+	t.Run("Delete flowtable", func(t *testing.T) {
+		config := nft.NewConfig()
+		config.DeleteFlowtable(flowtable)
+
+		serialized, err := config.ToJSON()
+		assert.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf(`{"nftables":[{"delete":{"flowtable":{%s}}}]}`, flowtableArgs), string(serialized))
+	})
+}
+
+func testFlowtableLookup(t *testing.T) {
+	config := nft.NewConfig()
+	table := nft.NewTable(tableName, nft.FamilyINET)
+	config.AddTable(table)
+	flowtable := nft.NewFlowtable(table, flowtableName, 0, []string{"eth0", "eth1"})
+	config.AddFlowtable(flowtable)
+
+	t.Run("Lookup an existing flowtable", func(t *testing.T) {
+		assert.Equal(t, flowtable, config.LookupFlowtable(flowtable))
+	})
+
+	t.Run("Lookup an existing flowtable by name", func(t *testing.T) {
+		toFind := &schema.Flowtable{Family: table.Family, Table: table.Name, Name: flowtableName}
+		assert.Equal(t, flowtable, config.LookupFlowtable(toFind))
+	})
+
+	t.Run("Lookup an existing flowtable with its devices in a different order", func(t *testing.T) {
+		assert.Equal(t, flowtable, config.LookupFlowtable(nft.NewFlowtable(table, flowtableName, 0, []string{"eth1", "eth0"})))
+	})
+
+	t.Run("Lookup a flowtable which lost a device", func(t *testing.T) {
+		assert.Nil(t, config.LookupFlowtable(nft.NewFlowtable(table, flowtableName, 0, []string{"eth0", "eth1", "eth2"})))
+	})
+
+	t.Run("Lookup a missing flowtable", func(t *testing.T) {
+		assert.Nil(t, config.LookupFlowtable(nft.NewFlowtable(table, "flowtable-na", 0, []string{"eth0", "eth1"})))
+	})
+}
@@ -0,0 +1,48 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft
+
+import (
+	"net"
+
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+// ForwardTo returns a forward statement (`fwd to <device>`), forwarding the packet to the given device.
+// It is supported in the netdev family (e.g. in an ingress chain).
+func ForwardTo(device string) schema.Statement {
+	return schema.Statement{Fwd: &schema.Fwd{Dev: schema.Expression{String: &device}}}
+}
+
+// ForwardToAddress returns a forward statement (`fwd ip to <address> device <device>`),
+// forwarding the packet to the neighbour of the given address on the given device.
+// The statement address family is set according to the address.
+// It is supported in the netdev family (e.g. in an ingress chain).
+func ForwardToAddress(device string, ip net.IP) schema.Statement {
+	addr, family := ip.String(), schema.FamilyIP6
+	if ip.To4() != nil {
+		family = schema.FamilyIP
+	}
+	return schema.Statement{Fwd: &schema.Fwd{
+		Dev:    schema.Expression{String: &device},
+		Family: family,
+		Addr:   &schema.Expression{String: &addr},
+	}}
+}
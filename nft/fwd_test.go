@@ -0,0 +1,65 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft_test
+
+import (
+	"encoding/json"
+	"net"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/networkplumbing/go-nft/nft"
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+func TestFwd(t *testing.T) {
+	tests := []struct {
+		name                string
+		statement           schema.Statement
+		serializedStatement string
+	}{
+		{
+			name:                "forward to a device",
+			statement:           nft.ForwardTo("eth1"),
+			serializedStatement: `{"fwd":{"dev":"eth1"}}`,
+		},
+		{
+			name:                "forward to an address on a device",
+			statement:           nft.ForwardToAddress("eth1", net.ParseIP("2001:db8::2")),
+			serializedStatement: `{"fwd":{"dev":"eth1","family":"ip6","addr":"2001:db8::2"}}`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name+", check serialization", func(t *testing.T) {
+			serialized, err := json.Marshal(test.statement)
+			assert.NoError(t, err)
+			assert.Equal(t, test.serializedStatement, string(serialized))
+		})
+
+		t.Run(test.name+", check deserialization", func(t *testing.T) {
+			var statement schema.Statement
+			assert.NoError(t, json.Unmarshal([]byte(test.serializedStatement), &statement))
+			assert.Equal(t, test.statement, statement)
+		})
+	}
+}
@@ -0,0 +1,104 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft
+
+import (
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+type ICMPType string
+
+// ICMP (IPv4) Types
+const (
+	ICMPTypeEchoReply              ICMPType = schema.ICMPTypeEchoReply
+	ICMPTypeDestinationUnreachable ICMPType = schema.ICMPTypeDestinationUnreachable
+	ICMPTypeSourceQuench           ICMPType = schema.ICMPTypeSourceQuench
+	ICMPTypeRedirect               ICMPType = schema.ICMPTypeRedirect
+	ICMPTypeEchoRequest            ICMPType = schema.ICMPTypeEchoRequest
+	ICMPTypeRouterAdvertisement    ICMPType = schema.ICMPTypeRouterAdvertisement
+	ICMPTypeRouterSolicitation     ICMPType = schema.ICMPTypeRouterSolicitation
+	ICMPTypeTimeExceeded           ICMPType = schema.ICMPTypeTimeExceeded
+	ICMPTypeParameterProblem       ICMPType = schema.ICMPTypeParameterProblem
+	ICMPTypeTimestampRequest       ICMPType = schema.ICMPTypeTimestampRequest
+	ICMPTypeTimestampReply         ICMPType = schema.ICMPTypeTimestampReply
+)
+
+type ICMPv6Type string
+
+// ICMPv6 Types
+const (
+	ICMPv6TypeDestinationUnreachable ICMPv6Type = schema.ICMPv6TypeDestinationUnreachable
+	ICMPv6TypePacketTooBig           ICMPv6Type = schema.ICMPv6TypePacketTooBig
+	ICMPv6TypeTimeExceeded           ICMPv6Type = schema.ICMPv6TypeTimeExceeded
+	ICMPv6TypeParameterProblem       ICMPv6Type = schema.ICMPv6TypeParameterProblem
+	ICMPv6TypeEchoRequest            ICMPv6Type = schema.ICMPv6TypeEchoRequest
+	ICMPv6TypeEchoReply              ICMPv6Type = schema.ICMPv6TypeEchoReply
+	ICMPv6TypeMLDListenerQuery       ICMPv6Type = schema.ICMPv6TypeMLDListenerQuery
+	ICMPv6TypeMLDListenerReport      ICMPv6Type = schema.ICMPv6TypeMLDListenerReport
+	ICMPv6TypeMLDListenerDone        ICMPv6Type = schema.ICMPv6TypeMLDListenerDone
+	ICMPv6TypeNDRouterSolicit        ICMPv6Type = schema.ICMPv6TypeNDRouterSolicit
+	ICMPv6TypeNDRouterAdvert         ICMPv6Type = schema.ICMPv6TypeNDRouterAdvert
+	ICMPv6TypeNDNeighborSolicit      ICMPv6Type = schema.ICMPv6TypeNDNeighborSolicit
+	ICMPv6TypeNDNeighborAdvert       ICMPv6Type = schema.ICMPv6TypeNDNeighborAdvert
+	ICMPv6TypeNDRedirect             ICMPv6Type = schema.ICMPv6TypeNDRedirect
+)
+
+// MatchICMPType returns a match statement of the ICMP type (`icmp type`).
+// Multiple types are matched using an anonymous set (e.g. `icmp type { echo-request, echo-reply }`).
+func MatchICMPType(types ...ICMPType) schema.Statement {
+	names := make([]string, 0, len(types))
+	for _, t := range types {
+		names = append(names, string(t))
+	}
+	return matchICMPType(schema.PayloadProtocolICMP, names)
+}
+
+// MatchICMPv6Type returns a match statement of the ICMPv6 type (`icmpv6 type`).
+// It is commonly used to allow the neighbor discovery messages, required for IPv6 to function.
+// Multiple types are matched using an anonymous set.
+func MatchICMPv6Type(types ...ICMPv6Type) schema.Statement {
+	names := make([]string, 0, len(types))
+	for _, t := range types {
+		names = append(names, string(t))
+	}
+	return matchICMPType(schema.PayloadProtocolICMPv6, names)
+}
+
+func matchICMPType(protocol string, names []string) schema.Statement {
+	var right schema.Expression
+	if len(names) == 1 {
+		right = schema.Expression{String: &names[0]}
+	} else {
+		set := make([]schema.Expression, 0, len(names))
+		for i := range names {
+			set = append(set, schema.Expression{String: &names[i]})
+		}
+		right = schema.Expression{Set: set}
+	}
+
+	return schema.Statement{Match: &schema.Match{
+		Op: schema.OperEQ,
+		Left: schema.Expression{Payload: &schema.Payload{
+			Protocol: protocol,
+			Field:    schema.PayloadFieldICMPType,
+		}},
+		Right: right,
+	}}
+}
@@ -0,0 +1,74 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/networkplumbing/go-nft/nft"
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+func TestMatchICMPType(t *testing.T) {
+	tests := []struct {
+		name                string
+		statement           schema.Statement
+		serializedStatement string
+	}{
+		{
+			name:                "ICMP echo request",
+			statement:           nft.MatchICMPType(nft.ICMPTypeEchoRequest),
+			serializedStatement: `{"match":{"op":"==","left":{"payload":{"protocol":"icmp","field":"type"}},"right":"echo-request"}}`,
+		},
+		{
+			name:                "ICMP echo request and reply",
+			statement:           nft.MatchICMPType(nft.ICMPTypeEchoRequest, nft.ICMPTypeEchoReply),
+			serializedStatement: `{"match":{"op":"==","left":{"payload":{"protocol":"icmp","field":"type"}},"right":{"set":["echo-request","echo-reply"]}}}`,
+		},
+		{
+			name: "ICMPv6 neighbor discovery",
+			statement: nft.MatchICMPv6Type(
+				nft.ICMPv6TypeNDNeighborSolicit,
+				nft.ICMPv6TypeNDNeighborAdvert,
+				nft.ICMPv6TypeNDRouterAdvert,
+			),
+			serializedStatement: `{"match":{"op":"==","left":{"payload":{"protocol":"icmpv6","field":"type"}},` +
+				`"right":{"set":["nd-neighbor-solicit","nd-neighbor-advert","nd-router-advert"]}}}`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name+", check serialization", func(t *testing.T) {
+			serialized, err := json.Marshal(test.statement)
+			assert.NoError(t, err)
+			assert.Equal(t, test.serializedStatement, string(serialized))
+		})
+
+		t.Run(test.name+", check deserialization", func(t *testing.T) {
+			var statement schema.Statement
+			assert.NoError(t, json.Unmarshal([]byte(test.serializedStatement), &statement))
+			assert.Equal(t, test.statement, statement)
+		})
+	}
+}
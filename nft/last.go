@@ -0,0 +1,41 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft
+
+import (
+	"time"
+
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+// NewLast returns a last statement, tracking when the rule last matched a packet (`last`).
+// It requires recent nftables and kernel versions.
+func NewLast() schema.Statement {
+	return schema.Statement{Last: &schema.Last{}}
+}
+
+// LastUsed returns the time passed since the rule of the given (listed) last statement matched a packet.
+// The returned flag is false when the rule has never matched.
+func LastUsed(last *schema.Last) (time.Duration, bool) {
+	if last == nil || last.Used == nil {
+		return 0, false
+	}
+	return time.Duration(*last.Used) * time.Millisecond, true
+}
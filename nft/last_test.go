@@ -0,0 +1,82 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/networkplumbing/go-nft/nft"
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+func TestLast(t *testing.T) {
+	used := 1500
+
+	tests := []struct {
+		name                string
+		statement           schema.Statement
+		serializedStatement string
+	}{
+		{
+			name:                "Last statement",
+			statement:           nft.NewLast(),
+			serializedStatement: `{"last":null}`,
+		},
+		{
+			name:                "Listed last statement",
+			statement:           schema.Statement{Last: &schema.Last{Used: &used}},
+			serializedStatement: `{"last":{"used":1500}}`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name+", check serialization", func(t *testing.T) {
+			serialized, err := json.Marshal(test.statement)
+			assert.NoError(t, err)
+			assert.Equal(t, test.serializedStatement, string(serialized))
+		})
+
+		t.Run(test.name+", check deserialization", func(t *testing.T) {
+			var statement schema.Statement
+			assert.NoError(t, json.Unmarshal([]byte(test.serializedStatement), &statement))
+			assert.Equal(t, test.statement, statement)
+		})
+	}
+
+	t.Run("Read a rule with a last statement", func(t *testing.T) {
+		config := nft.NewConfig()
+		assert.NoError(t, config.FromJSON([]byte(`{"nftables":[{"rule":{"family":"ip","table":"test-table","chain":"test-chain",`+
+			`"expr":[{"last":{"used":250}},{"accept":null}]}}]}`)))
+
+		elapsed, used := nft.LastUsed(config.Nftables[0].Rule.Expr[0].Last)
+		assert.True(t, used)
+		assert.Equal(t, 250*time.Millisecond, elapsed)
+	})
+
+	t.Run("Last statement of a rule which never matched", func(t *testing.T) {
+		_, used := nft.LastUsed(nft.NewLast().Last)
+		assert.False(t, used)
+	})
+}
@@ -0,0 +1,153 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft
+
+import (
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+type LimitUnit string
+type LimitPer string
+
+// Limit Units
+const (
+	LimitUnitPackets LimitUnit = schema.LimitUnitPackets
+	LimitUnitBytes   LimitUnit = schema.LimitUnitBytes
+	LimitUnitKBytes  LimitUnit = schema.LimitUnitKBytes
+	LimitUnitMBytes  LimitUnit = schema.LimitUnitMBytes
+)
+
+// Limit Time Units
+const (
+	LimitPerSecond LimitPer = schema.LimitPerSecond
+	LimitPerMinute LimitPer = schema.LimitPerMinute
+	LimitPerHour   LimitPer = schema.LimitPerHour
+	LimitPerDay    LimitPer = schema.LimitPerDay
+	LimitPerWeek   LimitPer = schema.LimitPerWeek
+)
+
+// NewNamedLimit returns a new schema limit object structure, limiting to the rate of units per time unit.
+// When inverted (over), the limit matches the traffic over the rate.
+func NewNamedLimit(table *schema.Table, name string, rate int, unit LimitUnit, per LimitPer, over bool) *schema.NamedLimit {
+	l := &schema.NamedLimit{
+		Family: table.Family,
+		Table:  table.Name,
+		Name:   name,
+		Rate:   rate,
+		Per:    string(per),
+		Inv:    over,
+	}
+	if unit != LimitUnitPackets {
+		l.RateUnit = string(unit)
+	}
+	return l
+}
+
+// NewLimit returns an anonymous limit statement, matching the traffic under the rate of units per time unit
+// (e.g. `limit rate 10/second burst 5 packets`).
+// The burst is optional (zero) and is measured in the same unit as the rate.
+func NewLimit(rate int, unit LimitUnit, per LimitPer, burst int) schema.Statement {
+	l := &schema.Limit{
+		Rate:  rate,
+		Per:   string(per),
+		Burst: burst,
+	}
+	if unit != LimitUnitPackets {
+		l.RateUnit = string(unit)
+		if burst != 0 {
+			l.BurstUnit = string(unit)
+		}
+	}
+	return schema.Statement{Limit: l}
+}
+
+// NewLimitOver returns an anonymous limit statement, matching the traffic over the rate of units per time unit
+// (e.g. `limit rate over 10/second`).
+// It is the inverted form of NewLimit, commonly followed by a drop verdict for policing.
+func NewLimitOver(rate int, unit LimitUnit, per LimitPer, burst int) schema.Statement {
+	statement := NewLimit(rate, unit, per, burst)
+	statement.Limit.Inv = true
+	return statement
+}
+
+// NewPoliceRule returns a rule which drops the traffic, selected by the matches, over the given rate
+// (e.g. `tcp dport 80 limit rate over 100/second drop`).
+// Traffic under the rate is not matched by the rule, continuing to the following rules.
+// It is not to be confused with NewRateAllowRule, which accepts the traffic under the rate
+// and leaves the traffic over it to the following rules.
+func NewPoliceRule(table *schema.Table, chain *schema.Chain, matches []schema.Statement, rate int, unit LimitUnit, per LimitPer, burst int) *schema.Rule {
+	statements := append([]schema.Statement(nil), matches...)
+	statements = append(statements, NewLimitOver(rate, unit, per, burst), schema.Statement{Verdict: schema.Drop()})
+	return NewRule(table, chain, statements, nil, nil, "")
+}
+
+// NewRateAllowRule returns a rule which accepts the traffic, selected by the matches, under the given rate
+// (e.g. `icmp type echo-request limit rate 10/second accept`).
+// Traffic over the rate is not matched by the rule, therefore a following rule (or the chain policy)
+// is expected to drop it.
+func NewRateAllowRule(table *schema.Table, chain *schema.Chain, matches []schema.Statement, rate int, unit LimitUnit, per LimitPer, burst int) *schema.Rule {
+	statements := append([]schema.Statement(nil), matches...)
+	statements = append(statements, NewLimit(rate, unit, per, burst), schema.Statement{Verdict: schema.Accept()})
+	return NewRule(table, chain, statements, nil, nil, "")
+}
+
+// NewLimitReference returns a limit statement which applies the given named limit.
+func NewLimitReference(limit *schema.NamedLimit) schema.Statement {
+	return schema.Statement{Limit: &schema.Limit{Name: limit.Name}}
+}
+
+// AddLimit appends the given limit object to the nftable config.
+// The limit is added without an explicit action (`add`).
+func (c *Config) AddLimit(limit *schema.NamedLimit) {
+	nftable := schema.Nftable{Limit: limit}
+	c.Nftables = append(c.Nftables, nftable)
+}
+
+// UpdateLimit appends the given limit object to the nftable config, changing the parameters
+// (e.g. the rate) of an existing limit object once the config is applied.
+// The rules which reference the limit are not affected.
+// Kernels which do not support updating limit objects ignore the update.
+func (c *Config) UpdateLimit(limit *schema.NamedLimit) {
+	nftable := schema.Nftable{Add: &schema.Objects{Limit: limit}}
+	c.Nftables = append(c.Nftables, nftable)
+}
+
+// DeleteLimit appends a given limit object to the nftable config
+// with the `delete` action.
+// Attempting to delete a non-existing limit, results with a failure when the config is applied.
+// The limit must not be referenced by any rule.
+func (c *Config) DeleteLimit(limit *schema.NamedLimit) {
+	nftable := schema.Nftable{Delete: &schema.Objects{Limit: limit}}
+	c.Nftables = append(c.Nftables, nftable)
+}
+
+// LookupLimit searches the configuration for a matching limit object and returns it.
+// The limit is matched by the table and limit name.
+// Mutating the returned limit will result in mutating the configuration.
+func (c *Config) LookupLimit(toFind *schema.NamedLimit) *schema.NamedLimit {
+	for _, nftable := range c.Nftables {
+		if limit := nftable.Limit; limit != nil {
+			if limit.Table == toFind.Table && limit.Family == toFind.Family && limit.Name == toFind.Name {
+				return limit
+			}
+		}
+	}
+	return nil
+}
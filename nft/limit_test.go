@@ -0,0 +1,233 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/networkplumbing/go-nft/nft"
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+const limitName = "test-limit"
+
+func TestLimit(t *testing.T) {
+	testLimitObjectActions(t)
+	testLimitLookup(t)
+	testEgressRateCap(t)
+	testLimitStatement(t)
+	testPoliceRules(t)
+}
+
+func testLimitObjectActions(t *testing.T) {
+	table := nft.NewTable(tableName, nft.FamilyIP)
+	limit := nft.NewNamedLimit(table, limitName, 10, nft.LimitUnitMBytes, nft.LimitPerSecond, true)
+	limitArgs := fmt.Sprintf(
+		`"family":"ip","table":%q,"name":%q,"rate":10,"per":"second","rate_unit":"mbytes","inv":true`, tableName, limitName,
+	)
+
+	actions := map[string]func(*nft.Config){
+		"add":    func(c *nft.Config) { c.AddLimit(limit) },
+		"update": func(c *nft.Config) { c.UpdateLimit(limit) },
+		"delete": func(c *nft.Config) { c.DeleteLimit(limit) },
+	}
+	expected := map[string]string{
+		"add":    fmt.Sprintf(`{"nftables":[{"limit":{%s}}]}`, limitArgs),
+		"update": fmt.Sprintf(`{"nftables":[{"add":{"limit":{%s}}}]}`, limitArgs),
+		"delete": fmt.Sprintf(`{"nftables":[{"delete":{"limit":{%s}}}]}`, limitArgs),
+	}
+
+	for action, actionFunc := range actions {
+		action, actionFunc := action, actionFunc
+		t.Run(fmt.Sprintf("%s limit", action), func(t *testing.T) {
+			config := nft.NewConfig()
+			actionFunc(config)
+
+			serialized, err := config.ToJSON()
+			assert.NoError(t, err)
+			assert.Equal(t, expected[action], string(serialized))
+		})
+	}
+
+	t.Run("Read limit", func(t *testing.T) {
+		var deserializedConfig nft.Config
+		assert.NoError(t, json.Unmarshal([]byte(expected["add"]), &deserializedConfig))
+
+		expectedConfig := nft.NewConfig()
+		expectedConfig.AddLimit(limit)
+		assert.Equal(t, expectedConfig, &deserializedConfig)
+	})
+
+	t.Run("Limit reference statement, check serialization and deserialization", func(t *testing.T) {
+		statement := nft.NewLimitReference(limit)
+		serialized, err := json.Marshal(statement)
+		assert.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf(`{"limit":%q}`, limitName), string(serialized))
+
+		var deserializedStatement schema.Statement
+		assert.NoError(t, json.Unmarshal(serialized, &deserializedStatement))
+		assert.Equal(t, statement, deserializedStatement)
+	})
+}
+
+func testLimitLookup(t *testing.T) {
+	config := nft.NewConfig()
+	table := nft.NewTable(tableName, nft.FamilyIP)
+	limit := nft.NewNamedLimit(table, limitName, 100, nft.LimitUnitPackets, nft.LimitPerMinute, false)
+	config.AddLimit(limit)
+
+	t.Run("Lookup an existing limit", func(t *testing.T) {
+		assert.Equal(t, limit, config.LookupLimit(&schema.NamedLimit{Family: table.Family, Table: table.Name, Name: limitName}))
+	})
+
+	t.Run("Lookup a missing limit", func(t *testing.T) {
+		assert.Nil(t, config.LookupLimit(&schema.NamedLimit{Family: table.Family, Table: table.Name, Name: "limit-na"}))
+	})
+}
+
+func testEgressRateCap(t *testing.T) {
+	table := nft.NewTable(tableName, nft.FamilyINET)
+	chain := nft.NewRegularChain(table, chainName)
+	_, destination, _ := net.ParseCIDR("192.0.2.0/24")
+	mark := 1
+
+	t.Run("Add an egress rate cap to a destination", func(t *testing.T) {
+		config := nft.NewConfig()
+		assert.NoError(t, config.AddEgressRateCap(chain, nft.EgressRateCap{
+			Name: "to-docs", Rate: 10, Unit: nft.LimitUnitMBytes, Destination: destination,
+		}))
+
+		serialized, err := config.ToJSON()
+		assert.NoError(t, err)
+		expected := fmt.Sprintf(
+			`{"nftables":[`+
+				`{"limit":{"family":"inet","table":%[1]q,"name":"to-docs","rate":10,"per":"second","rate_unit":"mbytes","inv":true}},`+
+				`{"rule":{"family":"inet","table":%[1]q,"chain":%[2]q,"expr":[`+
+				`{"match":{"op":"==","left":{"payload":{"protocol":"ip","field":"daddr"}},"right":{"prefix":{"addr":"192.0.2.0","len":24}}}},`+
+				`{"limit":"to-docs"},{"drop":null}]}}`+
+				`]}`,
+			tableName, chainName,
+		)
+		assert.Equal(t, expected, string(serialized))
+	})
+
+	t.Run("Add an egress rate cap of marked packets", func(t *testing.T) {
+		config := nft.NewConfig()
+		assert.NoError(t, config.AddEgressRateCap(chain, nft.EgressRateCap{
+			Name: "marked", Rate: 1, Unit: nft.LimitUnitMBytes, Mark: &mark,
+		}))
+		rule := config.Nftables[1].Rule
+		assert.Equal(t, &schema.Meta{Key: schema.MetaKeyMark}, rule.Expr[0].Match.Left.Meta)
+	})
+
+	t.Run("Update an egress rate cap", func(t *testing.T) {
+		config := nft.NewConfig()
+		config.UpdateEgressRateCap(table, nft.EgressRateCap{Name: "marked", Rate: 2, Unit: nft.LimitUnitMBytes, Mark: &mark})
+
+		serialized, err := config.ToJSON()
+		assert.NoError(t, err)
+		expected := fmt.Sprintf(
+			`{"nftables":[{"add":{"limit":{"family":"inet","table":%q,"name":"marked","rate":2,"per":"second","rate_unit":"mbytes","inv":true}}}]}`,
+			tableName,
+		)
+		assert.Equal(t, expected, string(serialized))
+	})
+
+	t.Run("Add an invalid egress rate cap", func(t *testing.T) {
+		config := nft.NewConfig()
+		assert.Error(t, config.AddEgressRateCap(chain, nft.EgressRateCap{Name: "invalid", Rate: 1}))
+		assert.Error(t, config.AddEgressRateCap(chain, nft.EgressRateCap{Name: "invalid", Rate: 1, Mark: &mark, Destination: destination}))
+
+		ip6Chain := nft.NewRegularChain(nft.NewTable(tableName, nft.FamilyIP6), chainName)
+		assert.Error(t, config.AddEgressRateCap(ip6Chain, nft.EgressRateCap{Name: "invalid", Rate: 1, Destination: destination}))
+		assert.Empty(t, config.Nftables)
+	})
+}
+
+func testLimitStatement(t *testing.T) {
+	tests := []struct {
+		name                string
+		statement           schema.Statement
+		serializedStatement string
+	}{
+		{
+			name:                "limit packets rate with burst",
+			statement:           nft.NewLimit(10, nft.LimitUnitPackets, nft.LimitPerSecond, 5),
+			serializedStatement: `{"limit":{"rate":10,"per":"second","burst":5}}`,
+		},
+		{
+			name:                "limit bytes rate with burst",
+			statement:           nft.NewLimit(1, nft.LimitUnitMBytes, nft.LimitPerSecond, 2),
+			serializedStatement: `{"limit":{"rate":1,"per":"second","rate_unit":"mbytes","burst":2,"burst_unit":"mbytes"}}`,
+		},
+		{
+			name:                "limit bytes rate",
+			statement:           nft.NewLimit(100, nft.LimitUnitKBytes, nft.LimitPerMinute, 0),
+			serializedStatement: `{"limit":{"rate":100,"per":"minute","rate_unit":"kbytes"}}`,
+		},
+		{
+			name:                "limit rate over",
+			statement:           nft.NewLimitOver(10, nft.LimitUnitPackets, nft.LimitPerHour, 0),
+			serializedStatement: `{"limit":{"rate":10,"per":"hour","inv":true}}`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name+", check serialization", func(t *testing.T) {
+			serialized, err := json.Marshal(test.statement)
+			assert.NoError(t, err)
+			assert.Equal(t, test.serializedStatement, string(serialized))
+		})
+
+		t.Run(test.name+", check deserialization", func(t *testing.T) {
+			var statement schema.Statement
+			assert.NoError(t, json.Unmarshal([]byte(test.serializedStatement), &statement))
+			assert.Equal(t, test.statement, statement)
+		})
+	}
+}
+
+func testPoliceRules(t *testing.T) {
+	table := nft.NewTable(tableName, nft.FamilyIP)
+	chain := nft.NewRegularChain(table, chainName)
+	matches := []schema.Statement{nft.MatchPacketType(nft.PacketTypeBroadcast)}
+	const matchArgs = `{"match":{"op":"==","left":{"meta":{"key":"pkttype"}},"right":"broadcast"}}`
+
+	t.Run("Police traffic over a rate", func(t *testing.T) {
+		rule := nft.NewPoliceRule(table, chain, matches, 100, nft.LimitUnitPackets, nft.LimitPerSecond, 0)
+		serialized, err := json.Marshal(rule.Expr)
+		assert.NoError(t, err)
+		assert.Equal(t, `[`+matchArgs+`,{"limit":{"rate":100,"per":"second","inv":true}},{"drop":null}]`, string(serialized))
+	})
+
+	t.Run("Allow traffic under a rate", func(t *testing.T) {
+		rule := nft.NewRateAllowRule(table, chain, matches, 100, nft.LimitUnitPackets, nft.LimitPerSecond, 10)
+		serialized, err := json.Marshal(rule.Expr)
+		assert.NoError(t, err)
+		assert.Equal(t, `[`+matchArgs+`,{"limit":{"rate":100,"per":"second","burst":10}},{"accept":null}]`, string(serialized))
+		assert.Len(t, matches, 1, "Expecting the matches not to be mutated")
+	})
+}
@@ -0,0 +1,73 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft
+
+import (
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+type LogLevel string
+type LogFlag string
+
+// Log Levels
+const (
+	LogLevelEmerg  LogLevel = schema.LogLevelEmerg
+	LogLevelAlert  LogLevel = schema.LogLevelAlert
+	LogLevelCrit   LogLevel = schema.LogLevelCrit
+	LogLevelErr    LogLevel = schema.LogLevelErr
+	LogLevelWarn   LogLevel = schema.LogLevelWarn
+	LogLevelNotice LogLevel = schema.LogLevelNotice
+	LogLevelInfo   LogLevel = schema.LogLevelInfo
+	LogLevelDebug  LogLevel = schema.LogLevelDebug
+	LogLevelAudit  LogLevel = schema.LogLevelAudit
+)
+
+// Log Flags
+const (
+	LogFlagTCPSequence LogFlag = schema.LogFlagTCPSequence
+	LogFlagTCPOptions  LogFlag = schema.LogFlagTCPOptions
+	LogFlagIPOptions   LogFlag = schema.LogFlagIPOptions
+	LogFlagSkuid       LogFlag = schema.LogFlagSkuid
+	LogFlagEther       LogFlag = schema.LogFlagEther
+	LogFlagAll         LogFlag = schema.LogFlagAll
+)
+
+// NewLog returns a log statement, logging to the kernel log with the given prefix, level and flags.
+// The prefix, level and flags are optional.
+func NewLog(prefix string, level LogLevel, flags []LogFlag) schema.Statement {
+	l := &schema.Log{
+		Prefix: prefix,
+		Level:  string(level),
+	}
+	for _, flag := range flags {
+		l.Flags = append(l.Flags, string(flag))
+	}
+	return schema.Statement{Log: l}
+}
+
+// NewGroupLog returns a log statement, logging through nfnetlink_log to the given group with the given prefix.
+// A zero snaplen copies the whole packet.
+func NewGroupLog(prefix string, group int, snaplen int) schema.Statement {
+	return schema.Statement{Log: &schema.Log{
+		Prefix:  prefix,
+		Group:   &group,
+		Snaplen: snaplen,
+	}}
+}
@@ -0,0 +1,100 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft
+
+import (
+	"math"
+
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+// SetMark returns a mangle statement setting the packet mark (`meta mark set <value>`).
+func SetMark(value int) schema.Statement {
+	return Mangle(schema.Expression{Meta: &schema.Meta{Key: schema.MetaKeyMark}}, value)
+}
+
+// SetCtMark returns a mangle statement setting the connection mark (`ct mark set <value>`).
+func SetCtMark(value int) schema.Statement {
+	return Mangle(schema.Expression{Ct: &schema.Ct{Key: schema.CtKeyMark}}, value)
+}
+
+// SetDSCP returns a mangle statement setting the DSCP field of the IP header (`ip dscp set <value>`).
+// The IPv6 header is set when the family is ip6, otherwise the IPv4 header is set.
+func SetDSCP(family AddressFamily, value int) schema.Statement {
+	return Mangle(ipPayload(family, schema.PayloadFieldIPDscp), value)
+}
+
+// SetTTL returns a mangle statement setting the IPv4 time to live (`ip ttl set <value>`).
+func SetTTL(value int) schema.Statement {
+	return Mangle(ipPayload(FamilyIP, schema.PayloadFieldIP4Ttl), value)
+}
+
+// SetHopLimit returns a mangle statement setting the IPv6 hop limit (`ip6 hoplimit set <value>`).
+func SetHopLimit(value int) schema.Statement {
+	return Mangle(ipPayload(FamilyIP6, schema.PayloadFieldIP6HopLimit), value)
+}
+
+// Mangle returns a mangle statement setting the given field (key) to the numeric value.
+// Use the schema.Mangle structure directly for non-numeric values.
+func Mangle(key schema.Expression, value int) schema.Statement {
+	v := float64(value)
+	return schema.Statement{Mangle: &schema.Mangle{
+		Key:   key,
+		Value: schema.Expression{Float64: &v},
+	}}
+}
+
+// NewConnmarkRules returns the standard rules pair which preserves the packet marks of a connection
+// using the connection mark.
+// The restore rule, for the prerouting chain, copies the connection mark into the packet mark
+// (`meta mark set ct mark & <mask>`).
+// The save rule, for the postrouting chain, copies the packet mark into the connection mark
+// of marked packets (`meta mark != 0 ct mark set meta mark & <mask>`).
+// The mask limits the bits which are copied, a zero mask copies all the bits.
+func NewConnmarkRules(table *schema.Table, prerouting, postrouting *schema.Chain, mask uint32) (restore, save *schema.Rule) {
+	packetMark := schema.Expression{Meta: &schema.Meta{Key: schema.MetaKeyMark}}
+	connMark := schema.Expression{Ct: &schema.Ct{Key: schema.CtKeyMark}}
+	zero := float64(0)
+
+	restore = NewRule(table, prerouting, []schema.Statement{
+		{Mangle: &schema.Mangle{Key: packetMark, Value: maskedMark(connMark, mask)}},
+	}, nil, nil, "")
+	save = NewRule(table, postrouting, []schema.Statement{
+		{Match: &schema.Match{Op: schema.OperNEQ, Left: packetMark, Right: schema.Expression{Float64: &zero}}},
+		{Mangle: &schema.Mangle{Key: connMark, Value: maskedMark(packetMark, mask)}},
+	}, nil, nil, "")
+	return restore, save
+}
+
+func maskedMark(mark schema.Expression, mask uint32) schema.Expression {
+	if mask == 0 || mask == math.MaxUint32 {
+		return mark
+	}
+	m := float64(mask)
+	return schema.Expression{And: []schema.Expression{mark, {Float64: &m}}}
+}
+
+func ipPayload(family AddressFamily, field string) schema.Expression {
+	protocol := schema.PayloadProtocolIP4
+	if family == FamilyIP6 {
+		protocol = schema.PayloadProtocolIP6
+	}
+	return schema.Expression{Payload: &schema.Payload{Protocol: protocol, Field: field}}
+}
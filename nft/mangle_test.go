@@ -0,0 +1,116 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/networkplumbing/go-nft/nft"
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+func TestMangle(t *testing.T) {
+	tests := []struct {
+		name                string
+		statement           schema.Statement
+		serializedStatement string
+	}{
+		{
+			name:                "set meta mark",
+			statement:           nft.SetMark(42),
+			serializedStatement: `{"mangle":{"key":{"meta":{"key":"mark"}},"value":42}}`,
+		},
+		{
+			name:                "set ct mark",
+			statement:           nft.SetCtMark(7),
+			serializedStatement: `{"mangle":{"key":{"ct":{"key":"mark"}},"value":7}}`,
+		},
+		{
+			name:                "set ip dscp",
+			statement:           nft.SetDSCP(nft.FamilyIP, 46),
+			serializedStatement: `{"mangle":{"key":{"payload":{"protocol":"ip","field":"dscp"}},"value":46}}`,
+		},
+		{
+			name:                "set ip6 dscp",
+			statement:           nft.SetDSCP(nft.FamilyIP6, 10),
+			serializedStatement: `{"mangle":{"key":{"payload":{"protocol":"ip6","field":"dscp"}},"value":10}}`,
+		},
+		{
+			name:                "set ip ttl",
+			statement:           nft.SetTTL(64),
+			serializedStatement: `{"mangle":{"key":{"payload":{"protocol":"ip","field":"ttl"}},"value":64}}`,
+		},
+		{
+			name:                "set ip6 hoplimit",
+			statement:           nft.SetHopLimit(1),
+			serializedStatement: `{"mangle":{"key":{"payload":{"protocol":"ip6","field":"hoplimit"}},"value":1}}`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name+", check serialization", func(t *testing.T) {
+			serialized, err := json.Marshal(test.statement)
+			assert.NoError(t, err)
+			assert.Equal(t, test.serializedStatement, string(serialized))
+		})
+
+		t.Run(test.name+", check deserialization", func(t *testing.T) {
+			var statement schema.Statement
+			assert.NoError(t, json.Unmarshal([]byte(test.serializedStatement), &statement))
+			assert.Equal(t, test.statement, statement)
+		})
+	}
+}
+
+func TestConnmarkRules(t *testing.T) {
+	table := nft.NewTable(tableName, nft.FamilyINET)
+	prerouting := nft.NewRegularChain(table, "prerouting")
+	postrouting := nft.NewRegularChain(table, "postrouting")
+
+	t.Run("masked connmark restore and save", func(t *testing.T) {
+		restore, save := nft.NewConnmarkRules(table, prerouting, postrouting, 0xff)
+
+		assertRuleExpr(t, restore, "prerouting",
+			`[{"mangle":{"key":{"meta":{"key":"mark"}},"value":{"\u0026":[{"ct":{"key":"mark"}},255]}}}]`)
+		assertRuleExpr(t, save, "postrouting",
+			`[{"match":{"op":"!=","left":{"meta":{"key":"mark"}},"right":0}},`+
+				`{"mangle":{"key":{"ct":{"key":"mark"}},"value":{"\u0026":[{"meta":{"key":"mark"}},255]}}}]`)
+	})
+
+	t.Run("unmasked connmark restore and save", func(t *testing.T) {
+		restore, save := nft.NewConnmarkRules(table, prerouting, postrouting, 0)
+
+		assertRuleExpr(t, restore, "prerouting", `[{"mangle":{"key":{"meta":{"key":"mark"}},"value":{"ct":{"key":"mark"}}}}]`)
+		assertRuleExpr(t, save, "postrouting",
+			`[{"match":{"op":"!=","left":{"meta":{"key":"mark"}},"right":0}},`+
+				`{"mangle":{"key":{"ct":{"key":"mark"}},"value":{"meta":{"key":"mark"}}}}]`)
+	})
+}
+
+func assertRuleExpr(t *testing.T, rule *schema.Rule, chain string, expectedExpr string) {
+	assert.Equal(t, chain, rule.Chain)
+	serialized, err := json.Marshal(rule.Expr)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedExpr, string(serialized))
+}
@@ -0,0 +1,128 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft
+
+import (
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+// MapDataTypeVerdict is the data type of verdict maps (vmap).
+// Any of the set key types may be used as map data as well.
+const MapDataTypeVerdict SetType = schema.MapDataTypeVerdict
+
+// NewMap returns a new schema map structure for a named map.
+// Flags and elements are optional.
+func NewMap(table *schema.Table, name string, keyType SetType, dataType SetType, flags []SetFlag, elements []schema.MapElement) *schema.Map {
+	m := &schema.Map{
+		Family: table.Family,
+		Table:  table.Name,
+		Name:   name,
+		Type:   schema.StringList{string(keyType)},
+		Map:    schema.StringList{string(dataType)},
+		Elem:   elements,
+	}
+
+	for _, flag := range flags {
+		m.Flags = append(m.Flags, string(flag))
+	}
+
+	return m
+}
+
+// NewVerdictMap returns a new schema map structure for a named verdict map (vmap),
+// mapping keys to verdicts (e.g. accept, drop, jump or goto).
+func NewVerdictMap(table *schema.Table, name string, keyType SetType, elements []schema.MapElement) *schema.Map {
+	return NewMap(table, name, keyType, MapDataTypeVerdict, nil, elements)
+}
+
+// NewVerdictMapElement returns a verdict map element, mapping the key to the verdict.
+func NewVerdictMapElement(key schema.Expression, verdict schema.Verdict) schema.MapElement {
+	return schema.MapElement{Key: key, Verdict: &verdict}
+}
+
+// VerdictMapLookup returns a statement which applies the verdict
+// mapped to the key in the given (named) verdict map.
+func VerdictMapLookup(key schema.Expression, vmap *schema.Map) schema.Statement {
+	return schema.Statement{Vmap: &schema.Vmap{
+		Key:  key,
+		Data: schema.Expression{SetRef: vmap.Name},
+	}}
+}
+
+// AddMap appends the given map to the nftable config.
+// The map is added without an explicit action (`add`).
+// Adding multiple times the same map has no effect when the config is applied,
+// except for adding the given elements to it.
+func (c *Config) AddMap(m *schema.Map) {
+	nftable := schema.Nftable{Map: m}
+	c.Nftables = append(c.Nftables, nftable)
+}
+
+// DeleteMap appends a given map to the nftable config
+// with the `delete` action.
+// Attempting to delete a non-existing map, results with a failure when the config is applied.
+// The map must not be referenced by any rule.
+func (c *Config) DeleteMap(m *schema.Map) {
+	nftable := schema.Nftable{Delete: &schema.Objects{Map: m}}
+	c.Nftables = append(c.Nftables, nftable)
+}
+
+// FlushMap appends a given map to the nftable config
+// with the `flush` action.
+// All elements of the map are removed (when applied).
+// Attempting to flush a non-existing map, results with a failure when the config is applied.
+func (c *Config) FlushMap(m *schema.Map) {
+	nftable := schema.Nftable{Flush: &schema.Objects{Map: m}}
+	c.Nftables = append(c.Nftables, nftable)
+}
+
+// LookupMap searches the configuration for a matching map and returns it.
+// The map is matched first by the table and map name.
+// The map key and data types are optional for matching.
+// Mutating the returned map will result in mutating the configuration.
+func (c *Config) LookupMap(toFind *schema.Map) *schema.Map {
+	for _, nftable := range c.Nftables {
+		if m := nftable.Map; m != nil {
+			match := m.Table == toFind.Table && m.Family == toFind.Family && m.Name == toFind.Name
+			if match && len(toFind.Type) > 0 {
+				match = areStringListsEqual(m.Type, toFind.Type)
+			}
+			if match && len(toFind.Map) > 0 {
+				match = areStringListsEqual(m.Map, toFind.Map)
+			}
+			if match {
+				return m
+			}
+		}
+	}
+	return nil
+}
+
+func areStringListsEqual(a, b schema.StringList) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
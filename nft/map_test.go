@@ -0,0 +1,215 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/networkplumbing/go-nft/nft"
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+type mapAction string
+
+type mapActionFunc func(*nft.Config, *schema.Map)
+
+// Map Actions
+const (
+	mapADD    mapAction = "add"
+	mapDELETE mapAction = "delete"
+	mapFLUSH  mapAction = "flush"
+)
+
+const mapName = "test-map"
+
+func TestMap(t *testing.T) {
+	testMapActions(t)
+	testAddMapWithElements(t)
+	testAddVerdictMapWithElements(t)
+	testVerdictMapLookupStatement(t)
+	testMapLookup(t)
+}
+
+func testMapActions(t *testing.T) {
+	actions := map[mapAction]mapActionFunc{
+		mapADD:    func(c *nft.Config, m *schema.Map) { c.AddMap(m) },
+		mapDELETE: func(c *nft.Config, m *schema.Map) { c.DeleteMap(m) },
+		mapFLUSH:  func(c *nft.Config, m *schema.Map) { c.FlushMap(m) },
+	}
+
+	table := nft.NewTable(tableName, nft.FamilyIP)
+	m := nft.NewMap(table, mapName, nft.SetTypeIPv4Addr, nft.SetTypeMark, nil, nil)
+
+	for action, actionFunc := range actions {
+		t.Run(fmt.Sprintf("%s map", action), func(t *testing.T) {
+			config := nft.NewConfig()
+			actionFunc(config, m)
+
+			serializedConfig, err := config.ToJSON()
+			assert.NoError(t, err)
+
+			mapArgs := fmt.Sprintf(
+				`"family":%q,"table":%q,"name":%q,"type":"ipv4_addr","map":"mark"`, table.Family, table.Name, mapName,
+			)
+			var expected string
+			if action == mapADD {
+				expected = fmt.Sprintf(`{"nftables":[{"map":{%s}}]}`, mapArgs)
+			} else {
+				expected = fmt.Sprintf(`{"nftables":[{%q:{"map":{%s}}}]}`, action, mapArgs)
+			}
+			assert.Equal(t, expected, string(serializedConfig))
+		})
+	}
+}
+
+func testAddMapWithElements(t *testing.T) {
+	table := nft.NewTable(tableName, nft.FamilyIP)
+	serializedConfig := fmt.Sprintf(
+		`{"nftables":[{"map":{"family":"ip","table":%q,"name":%q,"type":"ipv4_addr","map":"mark","elem":[["10.0.0.1",1]]}}]}`,
+		tableName, mapName,
+	)
+
+	address, mark := "10.0.0.1", float64(1)
+	m := nft.NewMap(table, mapName, nft.SetTypeIPv4Addr, nft.SetTypeMark, nil, []schema.MapElement{
+		{Key: schema.Expression{String: &address}, Value: schema.Expression{Float64: &mark}},
+	})
+
+	t.Run("Add map with elements, check serialization", func(t *testing.T) {
+		config := nft.NewConfig()
+		config.AddMap(m)
+
+		serialized, err := config.ToJSON()
+		assert.NoError(t, err)
+		assert.Equal(t, serializedConfig, string(serialized))
+	})
+
+	t.Run("Add map with elements, check deserialization", func(t *testing.T) {
+		var deserializedConfig nft.Config
+		assert.NoError(t, json.Unmarshal([]byte(serializedConfig), &deserializedConfig))
+
+		expectedConfig := nft.NewConfig()
+		expectedConfig.AddMap(m)
+		assert.Equal(t, expectedConfig, &deserializedConfig)
+	})
+}
+
+func testAddVerdictMapWithElements(t *testing.T) {
+	table := nft.NewTable(tableName, nft.FamilyINET)
+	serializedConfig := fmt.Sprintf(
+		`{"nftables":[{"map":{"family":"inet","table":%q,"name":%q,"type":"inet_service","map":"verdict",`+
+			`"elem":[[22,{"accept":null}],[23,{"drop":null}],[80,{"jump":{"target":%q}}],[443,{"goto":{"target":%q}}]]}}]}`,
+		tableName, mapName, chainName, chainName,
+	)
+
+	ssh, telnet, http, https := float64(22), float64(23), float64(80), float64(443)
+	m := nft.NewVerdictMap(table, mapName, nft.SetTypeInetService, []schema.MapElement{
+		nft.NewVerdictMapElement(schema.Expression{Float64: &ssh}, schema.Accept()),
+		nft.NewVerdictMapElement(schema.Expression{Float64: &telnet}, schema.Drop()),
+		nft.NewVerdictMapElement(schema.Expression{Float64: &http}, schema.Verdict{Jump: &schema.ToTarget{Target: chainName}}),
+		nft.NewVerdictMapElement(schema.Expression{Float64: &https}, schema.Verdict{Goto: &schema.ToTarget{Target: chainName}}),
+	})
+
+	t.Run("Add verdict map with elements, check serialization", func(t *testing.T) {
+		config := nft.NewConfig()
+		config.AddMap(m)
+
+		serialized, err := config.ToJSON()
+		assert.NoError(t, err)
+		assert.Equal(t, serializedConfig, string(serialized))
+	})
+
+	t.Run("Add verdict map with elements, check deserialization", func(t *testing.T) {
+		var deserializedConfig nft.Config
+		assert.NoError(t, json.Unmarshal([]byte(serializedConfig), &deserializedConfig))
+
+		expectedConfig := nft.NewConfig()
+		expectedConfig.AddMap(m)
+		assert.Equal(t, expectedConfig, &deserializedConfig)
+	})
+
+	t.Run("Add verdict map with an invalid element", func(t *testing.T) {
+		invalidConfig := fmt.Sprintf(
+			`{"nftables":[{"map":{"family":"inet","table":%q,"name":%q,"elem":[[22]]}}]}`, tableName, mapName,
+		)
+		var deserializedConfig nft.Config
+		assert.Error(t, json.Unmarshal([]byte(invalidConfig), &deserializedConfig))
+	})
+}
+
+func testVerdictMapLookupStatement(t *testing.T) {
+	table := nft.NewTable(tableName, nft.FamilyINET)
+	chain := nft.NewRegularChain(table, chainName)
+	m := nft.NewVerdictMap(table, mapName, nft.SetTypeInetService, nil)
+
+	key := schema.Expression{Payload: &schema.Payload{Protocol: schema.PayloadProtocolTCP, Field: schema.PayloadFieldTCPDPort}}
+	rule := nft.NewRule(table, chain, []schema.Statement{nft.VerdictMapLookup(key, m)}, nil, nil, "")
+
+	serializedConfig := fmt.Sprintf(
+		`{"nftables":[{"rule":{"family":"inet","table":%q,"chain":%q,`+
+			`"expr":[{"vmap":{"key":{"payload":{"protocol":"tcp","field":"dport"}},"data":"@%s"}}]}}]}`,
+		tableName, chainName, mapName,
+	)
+
+	t.Run("Add rule with a verdict map lookup, check serialization", func(t *testing.T) {
+		config := nft.NewConfig()
+		config.AddRule(rule)
+
+		serialized, err := config.ToJSON()
+		assert.NoError(t, err)
+		assert.Equal(t, serializedConfig, string(serialized))
+	})
+
+	t.Run("Add rule with a verdict map lookup, check deserialization", func(t *testing.T) {
+		var deserializedConfig nft.Config
+		assert.NoError(t, json.Unmarshal([]byte(serializedConfig), &deserializedConfig))
+
+		expectedConfig := nft.NewConfig()
+		expectedConfig.AddRule(rule)
+		assert.Equal(t, expectedConfig, &deserializedConfig)
+	})
+}
+
+func testMapLookup(t *testing.T) {
+	config := nft.NewConfig()
+	table := nft.NewTable(tableName, nft.FamilyIP)
+	config.AddTable(table)
+	m := nft.NewVerdictMap(table, mapName, nft.SetTypeIPv4Addr, nil)
+	config.AddMap(m)
+
+	t.Run("Lookup an existing map", func(t *testing.T) {
+		assert.Equal(t, m, config.LookupMap(m))
+	})
+
+	t.Run("Lookup an existing map by name", func(t *testing.T) {
+		assert.Equal(t, m, config.LookupMap(&schema.Map{Family: table.Family, Table: table.Name, Name: mapName}))
+	})
+
+	t.Run("Lookup a missing map (data type not matching)", func(t *testing.T) {
+		assert.Nil(t, config.LookupMap(nft.NewMap(table, mapName, nft.SetTypeIPv4Addr, nft.SetTypeMark, nil, nil)))
+	})
+
+	t.Run("Lookup a missing map", func(t *testing.T) {
+		assert.Nil(t, config.LookupMap(nft.NewVerdictMap(table, "map-na", nft.SetTypeIPv4Addr, nil)))
+	})
+}
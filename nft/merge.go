@@ -0,0 +1,143 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+package nft
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+// MergeConflict describes an object which is declared differently by the merged configs.
+type MergeConflict struct {
+	// Existing is the entry declared by the config which is merged into.
+	Existing schema.Nftable
+	// Conflicting is the entry declared by the other config.
+	Conflicting schema.Nftable
+}
+
+func (c MergeConflict) String() string {
+	if rule := c.Existing.Rule; rule != nil {
+		if rule.Comment != "" {
+			return fmt.Sprintf("rule %q in chain %s %s %s", rule.Comment, rule.Family, rule.Table, rule.Chain)
+		}
+		return fmt.Sprintf("rule with handle %d in chain %s %s %s", *rule.Handle, rule.Family, rule.Table, rule.Chain)
+	}
+	id, _ := objectIdentity(c.Existing)
+	return id.String()
+}
+
+// MergeError is returned when the merged configs declare conflicting objects.
+type MergeError struct {
+	Conflicts []MergeConflict
+}
+
+func (e *MergeError) Error() string {
+	conflicts := make([]string, 0, len(e.Conflicts))
+	for _, conflict := range e.Conflicts {
+		conflicts = append(conflicts, conflict.String())
+	}
+	return fmt.Sprintf("merge conflicts, declared differently: %s", strings.Join(conflicts, "; "))
+}
+
+// Merge appends the entries of the other config to the config, skipping the entries which are already declared.
+// Objects (tables, chains, sets, etc) are identified by their kind, family, table and name,
+// an object which is declared identically by both configs (regardless of handles and counter values) is kept once.
+// Rules are identified in their chain by their comment or handle, an identical rule is kept once.
+// Entries with actions (e.g. deletions) are appended as is, the metainfo entries of the other config are skipped.
+// The entries of the other config are checked against the entries of the config only, not against each other:
+// the other config is expected to be consistent by itself.
+//
+// When the configs declare the same object (or rule) differently, a MergeError which lists the conflicts
+// is returned and the config is left unchanged.
+func (c *Config) Merge(other *Config) error {
+	objects := map[objectID]schema.Nftable{}
+	rules := map[objectID][]*schema.Rule{}
+	for _, nftable := range c.Nftables {
+		if rule := nftable.Rule; rule != nil {
+			id := ruleChainID(rule)
+			rules[id] = append(rules[id], rule)
+		} else if id, isObject := objectIdentity(nftable); isObject {
+			objects[id] = nftable
+		}
+	}
+
+	var merged []schema.Nftable
+	var conflicts []MergeConflict
+	for _, nftable := range other.Nftables {
+		if nftable.Metainfo != nil {
+			continue
+		}
+
+		if rule := nftable.Rule; rule != nil {
+			id := ruleChainID(rule)
+			existing, isDuplicate := mergedRule(rules[id], rule)
+			if existing != nil && !isDuplicate {
+				conflicts = append(conflicts, MergeConflict{Existing: schema.Nftable{Rule: existing}, Conflicting: nftable})
+			}
+			if existing == nil {
+				merged = append(merged, nftable)
+			}
+			continue
+		}
+
+		id, isObject := objectIdentity(nftable)
+		if !isObject {
+			merged = append(merged, nftable)
+			continue
+		}
+		if existing, exists := objects[id]; exists {
+			if objectKey(existing) != objectKey(nftable) {
+				conflicts = append(conflicts, MergeConflict{Existing: existing, Conflicting: nftable})
+			}
+			continue
+		}
+		merged = append(merged, nftable)
+	}
+
+	if len(conflicts) > 0 {
+		return &MergeError{Conflicts: conflicts}
+	}
+	c.Nftables = append(c.Nftables, merged...)
+	return nil
+}
+
+// mergedRule returns the rule of the chain which is identical to the given rule, reporting it as a duplicate.
+// Otherwise, it returns the rule with the same identity (comment or handle), if any.
+func mergedRule(chainRules []*schema.Rule, rule *schema.Rule) (*schema.Rule, bool) {
+	key := ruleKey(rule)
+	var sameIdentity *schema.Rule
+	for _, r := range chainRules {
+		if ruleKey(r) == key {
+			return r, true
+		}
+		if sameIdentity == nil && isSameRuleIdentity(r, rule) {
+			sameIdentity = r
+		}
+	}
+	return sameIdentity, false
+}
+
+func isSameRuleIdentity(a, b *schema.Rule) bool {
+	if a.Comment != "" || b.Comment != "" {
+		return a.Comment == b.Comment
+	}
+	return a.Handle != nil && b.Handle != nil && *a.Handle == *b.Handle
+}
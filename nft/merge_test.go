@@ -0,0 +1,89 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+package nft_test
+
+import (
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/networkplumbing/go-nft/nft"
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+func TestMerge(t *testing.T) {
+	table := nft.NewTable(tableName, nft.FamilyIP)
+	chain := nft.NewRegularChain(table, chainName)
+	acceptRule := func(comment string) *schema.Rule {
+		return nft.NewRule(table, chain, []schema.Statement{{Verdict: schema.Accept()}}, nil, nil, comment)
+	}
+	newConfig := func() *nft.Config {
+		config := nft.NewConfig()
+		config.AddTable(table)
+		config.AddChain(chain)
+		config.AddRule(acceptRule("allow"))
+		return config
+	}
+
+	t.Run("Merge configs", func(t *testing.T) {
+		config := newConfig()
+		other := newConfig()
+		otherChain := nft.NewRegularChain(table, "other-chain")
+		other.AddChain(otherChain)
+		other.AddRule(acceptRule("other"))
+		other.DeleteRule(acceptRule("stale"))
+
+		assert.NoError(t, config.Merge(other))
+
+		expected := newConfig()
+		expected.AddChain(otherChain)
+		expected.AddRule(acceptRule("other"))
+		expected.DeleteRule(acceptRule("stale"))
+		assert.Equal(t, expected, config)
+	})
+
+	t.Run("Merge conflicting configs", func(t *testing.T) {
+		config := newConfig()
+		other := newConfig()
+		other.Nftables[1].Chain = nft.NewChain(table, chainName, nil, nil, nil, nil)
+		other.Nftables[1].Chain.Comment = "changed"
+		other.Nftables[2].Rule = nft.NewRule(table, chain, []schema.Statement{{Verdict: schema.Drop()}}, nil, nil, "allow")
+
+		err := config.Merge(other)
+
+		assert.EqualError(t, err,
+			`merge conflicts, declared differently: chain ip test-table test-chain; rule "allow" in chain ip test-table test-chain`,
+		)
+		assert.Len(t, err.(*nft.MergeError).Conflicts, 2)
+		assert.Equal(t, newConfig(), config)
+	})
+
+	t.Run("Merge a config with rules of the same comment", func(t *testing.T) {
+		config := newConfig()
+		other := nft.NewConfig()
+		other.AddRule(acceptRule("other"))
+		other.AddRule(nft.NewRule(table, chain, []schema.Statement{{Verdict: schema.Drop()}}, nil, nil, "other"))
+
+		assert.NoError(t, config.Merge(other))
+
+		expected := newConfig()
+		expected.Nftables = append(expected.Nftables, other.Nftables...)
+		assert.Equal(t, expected, config)
+	})
+}
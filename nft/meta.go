@@ -0,0 +1,112 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+type PacketType string
+
+// Packet Types
+const (
+	PacketTypeHost      PacketType = schema.PktTypeHost
+	PacketTypeBroadcast PacketType = schema.PktTypeBroadcast
+	PacketTypeMulticast PacketType = schema.PktTypeMulticast
+	PacketTypeOther     PacketType = schema.PktTypeOther
+)
+
+type NfProto string
+
+// Netfilter Protocols
+const (
+	NfProtoIPv4 NfProto = schema.NfProtoIPv4
+	NfProtoIPv6 NfProto = schema.NfProtoIPv6
+)
+
+// MatchPacketType returns a match statement of the packet type (`meta pkttype`).
+// It is commonly used to filter broadcast and multicast traffic on bridges.
+func MatchPacketType(packetType PacketType) schema.Statement {
+	return matchMeta(schema.MetaKeyPktType, string(packetType))
+}
+
+// MatchNfProto returns a match statement of the packet netfilter protocol (`meta nfproto`).
+// It is commonly used in inet family chains to branch between IPv4 and IPv6 specific logic.
+func MatchNfProto(proto NfProto) schema.Statement {
+	return matchMeta(schema.MetaKeyNfProto, string(proto))
+}
+
+// MatchIIF returns a match statement of the input interface index (`meta iif`).
+// Unlike the interface name, the index identifies a specific interface instance,
+// therefore the rule will not match a recreated interface with the same name.
+func MatchIIF(index int) (schema.Statement, error) {
+	if index <= 0 || int64(index) > math.MaxInt32 {
+		return schema.Statement{}, fmt.Errorf("invalid interface index %d", index)
+	}
+	return matchMetaNumber(schema.MetaKeyIIF, index), nil
+}
+
+// MatchOIF returns a match statement of the output interface index (`meta oif`).
+// See MatchIIF for details.
+func MatchOIF(index int) (schema.Statement, error) {
+	if index <= 0 || int64(index) > math.MaxInt32 {
+		return schema.Statement{}, fmt.Errorf("invalid interface index %d", index)
+	}
+	return matchMetaNumber(schema.MetaKeyOIF, index), nil
+}
+
+// MatchIIFGroup returns a match statement of the input interface group (`meta iifgroup`),
+// as configured on the interface with `ip link set <dev> group <group>`.
+// Matching groups avoids tracking the names of dynamically created interfaces.
+func MatchIIFGroup(group int) (schema.Statement, error) {
+	if group < 0 || int64(group) > math.MaxUint32 {
+		return schema.Statement{}, fmt.Errorf("invalid interface group %d", group)
+	}
+	return matchMetaNumber(schema.MetaKeyIIFGroup, group), nil
+}
+
+// MatchOIFGroup returns a match statement of the output interface group (`meta oifgroup`).
+// See MatchIIFGroup for details.
+func MatchOIFGroup(group int) (schema.Statement, error) {
+	if group < 0 || int64(group) > math.MaxUint32 {
+		return schema.Statement{}, fmt.Errorf("invalid interface group %d", group)
+	}
+	return matchMetaNumber(schema.MetaKeyOIFGroup, group), nil
+}
+
+func matchMetaNumber(key string, value int) schema.Statement {
+	v := float64(value)
+	return schema.Statement{Match: &schema.Match{
+		Op:    schema.OperEQ,
+		Left:  schema.Expression{Meta: &schema.Meta{Key: key}},
+		Right: schema.Expression{Float64: &v},
+	}}
+}
+
+func matchMeta(key string, value string) schema.Statement {
+	return schema.Statement{Match: &schema.Match{
+		Op:    schema.OperEQ,
+		Left:  schema.Expression{Meta: &schema.Meta{Key: key}},
+		Right: schema.Expression{String: &value},
+	}}
+}
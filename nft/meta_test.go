@@ -0,0 +1,116 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/networkplumbing/go-nft/nft"
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+func TestMatchPacketType(t *testing.T) {
+	packetTypes := []nft.PacketType{
+		nft.PacketTypeHost,
+		nft.PacketTypeBroadcast,
+		nft.PacketTypeMulticast,
+		nft.PacketTypeOther,
+	}
+	for _, packetType := range packetTypes {
+		expected := fmt.Sprintf(`{"match":{"op":"==","left":{"meta":{"key":"pkttype"}},"right":%q}}`, packetType)
+
+		t.Run(fmt.Sprintf("Match %s packet type, check serialization", packetType), func(t *testing.T) {
+			serialized, err := json.Marshal(nft.MatchPacketType(packetType))
+			assert.NoError(t, err)
+			assert.Equal(t, expected, string(serialized))
+		})
+
+		t.Run(fmt.Sprintf("Match %s packet type, check deserialization", packetType), func(t *testing.T) {
+			var statement schema.Statement
+			assert.NoError(t, json.Unmarshal([]byte(expected), &statement))
+			assert.Equal(t, nft.MatchPacketType(packetType), statement)
+		})
+	}
+}
+
+func TestMatchNfProto(t *testing.T) {
+	for _, proto := range []nft.NfProto{nft.NfProtoIPv4, nft.NfProtoIPv6} {
+		expected := fmt.Sprintf(`{"match":{"op":"==","left":{"meta":{"key":"nfproto"}},"right":%q}}`, proto)
+
+		t.Run(fmt.Sprintf("Match %s nfproto, check serialization", proto), func(t *testing.T) {
+			serialized, err := json.Marshal(nft.MatchNfProto(proto))
+			assert.NoError(t, err)
+			assert.Equal(t, expected, string(serialized))
+		})
+
+		t.Run(fmt.Sprintf("Match %s nfproto, check deserialization", proto), func(t *testing.T) {
+			var statement schema.Statement
+			assert.NoError(t, json.Unmarshal([]byte(expected), &statement))
+			assert.Equal(t, nft.MatchNfProto(proto), statement)
+		})
+	}
+}
+
+func TestMatchInterfaceIndexAndGroup(t *testing.T) {
+	tests := []struct {
+		name                string
+		match               func(int) (schema.Statement, error)
+		value               int
+		serializedStatement string
+	}{
+		{"iif", nft.MatchIIF, 2, `{"match":{"op":"==","left":{"meta":{"key":"iif"}},"right":2}}`},
+		{"oif", nft.MatchOIF, 3, `{"match":{"op":"==","left":{"meta":{"key":"oif"}},"right":3}}`},
+		{"iifgroup", nft.MatchIIFGroup, 0, `{"match":{"op":"==","left":{"meta":{"key":"iifgroup"}},"right":0}}`},
+		{"oifgroup", nft.MatchOIFGroup, 10, `{"match":{"op":"==","left":{"meta":{"key":"oifgroup"}},"right":10}}`},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run("Match "+test.name+", check serialization", func(t *testing.T) {
+			statement, err := test.match(test.value)
+			assert.NoError(t, err)
+			serialized, err := json.Marshal(statement)
+			assert.NoError(t, err)
+			assert.Equal(t, test.serializedStatement, string(serialized))
+		})
+
+		t.Run("Match "+test.name+", check deserialization", func(t *testing.T) {
+			expected, err := test.match(test.value)
+			assert.NoError(t, err)
+			var statement schema.Statement
+			assert.NoError(t, json.Unmarshal([]byte(test.serializedStatement), &statement))
+			assert.Equal(t, expected, statement)
+		})
+	}
+
+	t.Run("Invalid interface index and group", func(t *testing.T) {
+		_, err := nft.MatchIIF(0)
+		assert.Error(t, err)
+		_, err = nft.MatchOIF(-1)
+		assert.Error(t, err)
+		_, err = nft.MatchIIFGroup(-1)
+		assert.Error(t, err)
+		_, err = nft.MatchOIFGroup(1 << 32)
+		assert.Error(t, err)
+	})
+}
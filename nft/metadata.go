@@ -0,0 +1,127 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+// RuleMetadata is structured key-value metadata, encoded in the rule comment.
+// It allows controllers to identify the rules they own, regardless of the rule content.
+type RuleMetadata map[string]string
+
+// Rule Metadata Keys
+const (
+	MetadataKeyOwner      = "owner"
+	MetadataKeyRuleID     = "rule-id"
+	MetadataKeyGeneration = "generation"
+)
+
+const (
+	commentMaxLen = 128
+
+	metadataSeparator      = "&"
+	metadataValueSeparator = "="
+)
+
+// Encode returns the comment representation of the metadata (e.g. `generation=3&owner=app&rule-id=ssh`).
+// The keys are sorted and the values are escaped, therefore equal metadata is always encoded the same.
+// It fails when a key is invalid (empty or containing other than alphanumeric, `-`, `_` and `.` characters)
+// or when the representation exceeds the comment size limit.
+func (m RuleMetadata) Encode() (string, error) {
+	values := url.Values{}
+	for key, value := range m {
+		if !isMetadataKey(key) {
+			return "", fmt.Errorf("invalid metadata key %q", key)
+		}
+		values.Set(key, value)
+	}
+	comment := values.Encode()
+	if len(comment) > commentMaxLen {
+		return "", fmt.Errorf("metadata exceeds the comment size limit of %d characters: %q", commentMaxLen, comment)
+	}
+	return comment, nil
+}
+
+// Matches reports if the metadata includes all the given key-values.
+func (m RuleMetadata) Matches(toMatch RuleMetadata) bool {
+	for key, value := range toMatch {
+		if v, exists := m[key]; !exists || v != value {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseRuleMetadata returns the metadata encoded in the comment.
+// It fails when the comment is not a metadata representation (e.g. a free text comment).
+func ParseRuleMetadata(comment string) (RuleMetadata, error) {
+	if comment == "" {
+		return nil, fmt.Errorf("no metadata in an empty comment")
+	}
+	metadata := RuleMetadata{}
+	for _, pair := range strings.Split(comment, metadataSeparator) {
+		parts := strings.SplitN(pair, metadataValueSeparator, 2)
+		if len(parts) != 2 || !isMetadataKey(parts[0]) {
+			return nil, fmt.Errorf("invalid metadata in comment %q", comment)
+		}
+		value, err := url.QueryUnescape(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid metadata in comment %q: %v", comment, err)
+		}
+		metadata[parts[0]] = value
+	}
+	return metadata, nil
+}
+
+// RuleWithMetadata encodes the metadata in the comment of the given rule and returns it,
+// e.g. `nft.RuleWithMetadata(rule, nft.RuleMetadata{nft.MetadataKeyOwner: "app"})`.
+// Any existing comment is replaced.
+func RuleWithMetadata(rule *schema.Rule, metadata RuleMetadata) (*schema.Rule, error) {
+	comment, err := metadata.Encode()
+	if err != nil {
+		return nil, err
+	}
+	rule.Comment = comment
+	return rule, nil
+}
+
+// ruleHasMetadata reports if the rule comment encodes metadata which includes all the given key-values.
+func ruleHasMetadata(rule *schema.Rule, toMatch RuleMetadata) bool {
+	metadata, err := ParseRuleMetadata(rule.Comment)
+	return err == nil && metadata.Matches(toMatch)
+}
+
+func isMetadataKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, c := range key {
+		isAlphanumeric := (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+		if !isAlphanumeric && c != '-' && c != '_' && c != '.' {
+			return false
+		}
+	}
+	return true
+}
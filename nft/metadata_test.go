@@ -0,0 +1,110 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft_test
+
+import (
+	"strings"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/networkplumbing/go-nft/nft"
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+func TestRuleMetadata(t *testing.T) {
+	metadata := nft.RuleMetadata{
+		nft.MetadataKeyOwner:      "app",
+		nft.MetadataKeyRuleID:     "allow ssh",
+		nft.MetadataKeyGeneration: "3",
+	}
+
+	t.Run("Encode and parse metadata", func(t *testing.T) {
+		comment, err := metadata.Encode()
+		assert.NoError(t, err)
+		assert.Equal(t, "generation=3&owner=app&rule-id=allow+ssh", comment)
+
+		parsed, err := nft.ParseRuleMetadata(comment)
+		assert.NoError(t, err)
+		assert.Equal(t, metadata, parsed)
+	})
+
+	t.Run("Encode metadata with escaped values", func(t *testing.T) {
+		special := nft.RuleMetadata{nft.MetadataKeyRuleID: "a&b=c"}
+		comment, err := special.Encode()
+		assert.NoError(t, err)
+		assert.Equal(t, "rule-id=a%26b%3Dc", comment)
+
+		parsed, err := nft.ParseRuleMetadata(comment)
+		assert.NoError(t, err)
+		assert.Equal(t, special, parsed)
+	})
+
+	t.Run("Encode invalid metadata", func(t *testing.T) {
+		_, err := nft.RuleMetadata{"": "app"}.Encode()
+		assert.Error(t, err)
+		_, err = nft.RuleMetadata{"owner name": "app"}.Encode()
+		assert.Error(t, err)
+		_, err = nft.RuleMetadata{nft.MetadataKeyOwner: strings.Repeat("a", 128)}.Encode()
+		assert.Error(t, err)
+	})
+
+	t.Run("Parse non metadata comments", func(t *testing.T) {
+		for _, comment := range []string{"", "allow ssh", "owner=app&free text", "=app", "owner=%zz"} {
+			_, err := nft.ParseRuleMetadata(comment)
+			assert.Error(t, err, comment)
+		}
+	})
+
+	t.Run("Match metadata", func(t *testing.T) {
+		assert.True(t, metadata.Matches(nil))
+		assert.True(t, metadata.Matches(nft.RuleMetadata{nft.MetadataKeyOwner: "app"}))
+		assert.False(t, metadata.Matches(nft.RuleMetadata{nft.MetadataKeyOwner: "other"}))
+		assert.False(t, metadata.Matches(nft.RuleMetadata{"zone": "public"}))
+	})
+
+	t.Run("Lookup rules by metadata", func(t *testing.T) {
+		table := nft.NewTable(tableName, nft.FamilyIP)
+		chain := nft.NewRegularChain(table, chainName)
+		config := nft.NewConfig()
+
+		var rules []*schema.Rule
+		for _, ruleMetadata := range []nft.RuleMetadata{
+			{nft.MetadataKeyOwner: "app", nft.MetadataKeyRuleID: "1"},
+			{nft.MetadataKeyOwner: "other", nft.MetadataKeyRuleID: "1"},
+			{nft.MetadataKeyOwner: "app", nft.MetadataKeyRuleID: "2"},
+		} {
+			rule, err := nft.RuleWithMetadata(nft.NewRule(table, chain, []schema.Statement{nft.Accept()}, nil, nil, ""), ruleMetadata)
+			assert.NoError(t, err)
+			config.AddRule(rule)
+			rules = append(rules, rule)
+		}
+		config.AddRule(nft.NewRule(table, chain, []schema.Statement{nft.Drop()}, nil, nil, "free text"))
+
+		options := nft.LookupRuleOptions{Metadata: nft.RuleMetadata{nft.MetadataKeyOwner: "app"}}
+		assert.Equal(t, []*schema.Rule{rules[0], rules[2]}, config.LookupRuleWithOptions(&schema.Rule{}, options))
+
+		options.Metadata[nft.MetadataKeyRuleID] = "2"
+		assert.Equal(t, []*schema.Rule{rules[2]}, config.LookupRuleWithOptions(nft.NewRule(table, chain, nil, nil, nil, ""), options))
+
+		options.Metadata[nft.MetadataKeyOwner] = "missing"
+		assert.Empty(t, config.LookupRuleWithOptions(&schema.Rule{}, options))
+	})
+}
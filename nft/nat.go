@@ -0,0 +1,104 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft
+
+import (
+	"net"
+
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+type NatFlag string
+
+// Nat Flags
+const (
+	// NatFlagRandom randomizes the port mapping.
+	NatFlagRandom NatFlag = schema.NatFlagRandom
+	// NatFlagFullyRandom fully randomizes the port mapping, using a PRNG instead of a hash.
+	NatFlagFullyRandom NatFlag = schema.NatFlagFullyRandom
+	// NatFlagPersistent maps a client to the same address for each connection.
+	NatFlagPersistent NatFlag = schema.NatFlagPersistent
+)
+
+// DNATTo returns a destination address translation statement (`dnat to`),
+// translating to the given address and (optional) port.
+// A zero port keeps the original destination port.
+// The statement address family is set according to the address, as required by tables of the inet family.
+func DNATTo(ip net.IP, port int) schema.Statement {
+	return schema.Statement{Dnat: newNat(ip, port)}
+}
+
+// SNATTo returns a source address translation statement (`snat to`),
+// translating to the given address and (optional) port.
+// A zero port keeps the original source port.
+// The statement address family is set according to the address, as required by tables of the inet family.
+func SNATTo(ip net.IP, port int) schema.Statement {
+	return schema.Statement{Snat: newNat(ip, port)}
+}
+
+// DNATToPortRange returns a destination address translation statement (`dnat to <addr>:<from>-<to>`),
+// translating to the given address and a port of the given (inclusive) range, selected according to the flags.
+func DNATToPortRange(ip net.IP, fromPort, toPort int, flags []NatFlag) schema.Statement {
+	return schema.Statement{Dnat: newNatPortRange(ip, fromPort, toPort, flags)}
+}
+
+// SNATToPortRange returns a source address translation statement (`snat to <addr>:<from>-<to>`),
+// translating to the given address and a port of the given (inclusive) range, selected according to the flags.
+func SNATToPortRange(ip net.IP, fromPort, toPort int, flags []NatFlag) schema.Statement {
+	return schema.Statement{Snat: newNatPortRange(ip, fromPort, toPort, flags)}
+}
+
+// RedirectTo returns a redirect statement, translating the destination to the local host
+// and the given (optional) port.
+// A zero port keeps the original destination port.
+func RedirectTo(port int) schema.Statement {
+	redirect := &schema.Redirect{}
+	if port != 0 {
+		p := float64(port)
+		redirect.Port = &schema.Expression{Float64: &p}
+	}
+	return schema.Statement{Redirect: redirect}
+}
+
+func newNat(ip net.IP, port int) *schema.Nat {
+	addr, family := ip.String(), schema.FamilyIP6
+	if ip.To4() != nil {
+		family = schema.FamilyIP
+	}
+	nat := &schema.Nat{
+		Addr:   &schema.Expression{String: &addr},
+		Family: family,
+	}
+	if port != 0 {
+		p := float64(port)
+		nat.Port = &schema.Expression{Float64: &p}
+	}
+	return nat
+}
+
+func newNatPortRange(ip net.IP, fromPort, toPort int, flags []NatFlag) *schema.Nat {
+	nat := newNat(ip, 0)
+	portRange := NumberRange(fromPort, toPort)
+	nat.Port = &portRange
+	for _, flag := range flags {
+		nat.Flags = append(nat.Flags, string(flag))
+	}
+	return nat
+}
@@ -0,0 +1,106 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft_test
+
+import (
+	"encoding/json"
+	"net"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/networkplumbing/go-nft/nft"
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+func TestNat(t *testing.T) {
+	tests := []struct {
+		name                string
+		statement           schema.Statement
+		serializedStatement string
+	}{
+		{
+			name:                "dnat to an address and port",
+			statement:           nft.DNATTo(net.ParseIP("10.0.0.1"), 8080),
+			serializedStatement: `{"dnat":{"addr":"10.0.0.1","family":"ip","port":8080}}`,
+		},
+		{
+			name:                "dnat to an IPv6 address",
+			statement:           nft.DNATTo(net.ParseIP("2001:db8::1"), 0),
+			serializedStatement: `{"dnat":{"addr":"2001:db8::1","family":"ip6"}}`,
+		},
+		{
+			name:                "snat to an address",
+			statement:           nft.SNATTo(net.ParseIP("192.0.2.1"), 0),
+			serializedStatement: `{"snat":{"addr":"192.0.2.1","family":"ip"}}`,
+		},
+		{
+			name:                "dnat to a port range",
+			statement:           nft.DNATToPortRange(net.ParseIP("10.0.0.1"), 8000, 8099, nil),
+			serializedStatement: `{"dnat":{"addr":"10.0.0.1","family":"ip","port":{"range":[8000,8099]}}}`,
+		},
+		{
+			name: "snat to a port range with random port selection",
+			statement: nft.SNATToPortRange(
+				net.ParseIP("192.0.2.1"), 1024, 65535, []nft.NatFlag{nft.NatFlagFullyRandom, nft.NatFlagPersistent},
+			),
+			serializedStatement: `{"snat":{"addr":"192.0.2.1","family":"ip","port":{"range":[1024,65535]},` +
+				`"flags":["fully-random","persistent"]}}`,
+		},
+		{
+			name:                "redirect to a port",
+			statement:           nft.RedirectTo(8080),
+			serializedStatement: `{"redirect":{"port":8080}}`,
+		},
+		{
+			name:                "redirect",
+			statement:           nft.RedirectTo(0),
+			serializedStatement: `{"redirect":null}`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name+", check serialization", func(t *testing.T) {
+			serialized, err := json.Marshal(test.statement)
+			assert.NoError(t, err)
+			assert.Equal(t, test.serializedStatement, string(serialized))
+		})
+
+		t.Run(test.name+", check deserialization", func(t *testing.T) {
+			var statement schema.Statement
+			assert.NoError(t, json.Unmarshal([]byte(test.serializedStatement), &statement))
+			assert.Equal(t, test.statement, statement)
+		})
+	}
+
+	t.Run("dnat with flags, check deserialization", func(t *testing.T) {
+		var statement schema.Statement
+		assert.NoError(t, json.Unmarshal([]byte(`{"dnat":{"addr":"10.0.0.1","flags":["random","persistent"]}}`), &statement))
+		assert.Equal(t, schema.StringList{"random", "persistent"}, statement.Dnat.Flags)
+	})
+
+	t.Run("redirect with flags, check deserialization", func(t *testing.T) {
+		var statement schema.Statement
+		assert.NoError(t, json.Unmarshal([]byte(`{"redirect":{"port":8080,"flags":"random"}}`), &statement))
+		port := float64(8080)
+		assert.Equal(t, &schema.Redirect{Port: &schema.Expression{Float64: &port}, Flags: schema.StringList{"random"}}, statement.Redirect)
+	})
+}
@@ -0,0 +1,114 @@
+//go:build go1.18
+// +build go1.18
+
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+// AddrExpression returns the expression of the IP address.
+// IPv4-mapped IPv6 addresses are unmapped, matching them in the IPv4 header.
+func AddrExpression(addr netip.Addr) schema.Expression {
+	address := addr.Unmap().String()
+	return schema.Expression{String: &address}
+}
+
+// PrefixExpression returns the expression of the CIDR prefix (e.g. `10.0.0.0/8`).
+// The prefix is masked, as nftables rejects prefixes with host bits set.
+func PrefixExpression(prefix netip.Prefix) (schema.Expression, error) {
+	if !prefix.IsValid() {
+		return schema.Expression{}, fmt.Errorf("invalid prefix: %q", prefix)
+	}
+	return prefixExpression(netipPrefixToIPNet(prefix.Masked()))
+}
+
+// AddrFromExpression returns the IP address of the expression.
+// It fails when the expression is not a single IP address (e.g. a prefix or a set).
+func AddrFromExpression(expression schema.Expression) (netip.Addr, error) {
+	if expression.String == nil {
+		return netip.Addr{}, fmt.Errorf("unsupported address expression")
+	}
+	return netip.ParseAddr(*expression.String)
+}
+
+// PrefixFromExpression returns the CIDR prefix of the expression.
+// A single IP address expression is returned as a full length prefix (e.g. `10.0.0.1/32`).
+func PrefixFromExpression(expression schema.Expression) (netip.Prefix, error) {
+	network, err := rightNetwork(expression)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	addr, _ := netip.AddrFromSlice(network.IP)
+	prefixLen, _ := network.Mask.Size()
+	return netip.PrefixFrom(addr, prefixLen), nil
+}
+
+// MatchSrcAddr returns a match statement of the source address in the IP header of its version
+// (`ip saddr` or `ip6 saddr`).
+func MatchSrcAddr(addr netip.Addr) schema.Statement {
+	return addressMatch(addrProtocol(addr), AddressFieldSource, AddrExpression(addr))
+}
+
+// MatchDstAddr returns a match statement of the destination address, see MatchSrcAddr for details.
+func MatchDstAddr(addr netip.Addr) schema.Statement {
+	return addressMatch(addrProtocol(addr), AddressFieldDestination, AddrExpression(addr))
+}
+
+// MatchSrcPrefix returns a match statement of the source address in the CIDR prefix,
+// in the IP header of its version (`ip saddr` or `ip6 saddr`).
+func MatchSrcPrefix(prefix netip.Prefix) (schema.Statement, error) {
+	return matchPrefix(AddressFieldSource, prefix)
+}
+
+// MatchDstPrefix returns a match statement of the destination address in the CIDR prefix,
+// see MatchSrcPrefix for details.
+func MatchDstPrefix(prefix netip.Prefix) (schema.Statement, error) {
+	return matchPrefix(AddressFieldDestination, prefix)
+}
+
+func matchPrefix(field AddressField, prefix netip.Prefix) (schema.Statement, error) {
+	right, err := PrefixExpression(prefix)
+	if err != nil {
+		return schema.Statement{}, err
+	}
+	return addressMatch(addrProtocol(prefix.Addr()), field, right), nil
+}
+
+func addrProtocol(addr netip.Addr) string {
+	if addr.Unmap().Is4() {
+		return schema.PayloadProtocolIP4
+	}
+	return schema.PayloadProtocolIP6
+}
+
+func netipPrefixToIPNet(prefix netip.Prefix) *net.IPNet {
+	addr := prefix.Addr()
+	return &net.IPNet{
+		IP:   net.IP(addr.AsSlice()),
+		Mask: net.CIDRMask(prefix.Bits(), addr.BitLen()),
+	}
+}
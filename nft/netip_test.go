@@ -0,0 +1,126 @@
+//go:build go1.18
+// +build go1.18
+
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft_test
+
+import (
+	"encoding/json"
+	"net/netip"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/networkplumbing/go-nft/nft"
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+func TestNetip(t *testing.T) {
+	t.Run("Address expression round trip", func(t *testing.T) {
+		for _, address := range []string{"10.0.0.1", "2001:db8::1"} {
+			addr := netip.MustParseAddr(address)
+			expression := nft.AddrExpression(addr)
+			assert.Equal(t, address, *expression.String)
+
+			parsed, err := nft.AddrFromExpression(expression)
+			assert.NoError(t, err)
+			assert.Equal(t, addr, parsed)
+		}
+	})
+
+	t.Run("IPv4-mapped address expression is unmapped", func(t *testing.T) {
+		expression := nft.AddrExpression(netip.MustParseAddr("::ffff:10.0.0.1"))
+		assert.Equal(t, "10.0.0.1", *expression.String)
+	})
+
+	t.Run("Prefix expression round trip", func(t *testing.T) {
+		prefix := netip.MustParsePrefix("2001:db8::/32")
+		expression, err := nft.PrefixExpression(prefix)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"prefix":{"addr":"2001:db8::","len":32}}`, string(expression.RowData))
+
+		parsed, err := nft.PrefixFromExpression(expression)
+		assert.NoError(t, err)
+		assert.Equal(t, prefix, parsed)
+	})
+
+	t.Run("Prefix expression is masked", func(t *testing.T) {
+		expression, err := nft.PrefixExpression(netip.MustParsePrefix("10.1.2.3/8"))
+		assert.NoError(t, err)
+		assert.Equal(t, `{"prefix":{"addr":"10.0.0.0","len":8}}`, string(expression.RowData))
+	})
+
+	t.Run("Prefix of a single address expression", func(t *testing.T) {
+		prefix, err := nft.PrefixFromExpression(nft.AddrExpression(netip.MustParseAddr("10.0.0.1")))
+		assert.NoError(t, err)
+		assert.Equal(t, netip.MustParsePrefix("10.0.0.1/32"), prefix)
+	})
+
+	t.Run("Invalid expressions", func(t *testing.T) {
+		_, err := nft.PrefixExpression(netip.Prefix{})
+		assert.Error(t, err)
+		expression, err := nft.PrefixExpression(netip.MustParsePrefix("10.0.0.0/8"))
+		assert.NoError(t, err)
+		_, err = nft.AddrFromExpression(expression)
+		assert.Error(t, err)
+	})
+
+	t.Run("Match addresses and prefixes", func(t *testing.T) {
+		srcPrefix, err := nft.MatchSrcPrefix(netip.MustParsePrefix("10.0.0.0/8"))
+		assert.NoError(t, err)
+		dstPrefix, err := nft.MatchDstPrefix(netip.MustParsePrefix("2001:db8::/32"))
+		assert.NoError(t, err)
+
+		tests := []struct {
+			name                string
+			statement           schema.Statement
+			serializedStatement string
+		}{
+			{
+				"IPv4 source address",
+				nft.MatchSrcAddr(netip.MustParseAddr("10.0.0.1")),
+				`{"match":{"op":"==","left":{"payload":{"protocol":"ip","field":"saddr"}},"right":"10.0.0.1"}}`,
+			},
+			{
+				"IPv6 destination address",
+				nft.MatchDstAddr(netip.MustParseAddr("2001:db8::1")),
+				`{"match":{"op":"==","left":{"payload":{"protocol":"ip6","field":"daddr"}},"right":"2001:db8::1"}}`,
+			},
+			{
+				"IPv4 source prefix",
+				srcPrefix,
+				`{"match":{"op":"==","left":{"payload":{"protocol":"ip","field":"saddr"}},` +
+					`"right":{"prefix":{"addr":"10.0.0.0","len":8}}}}`,
+			},
+			{
+				"IPv6 destination prefix",
+				dstPrefix,
+				`{"match":{"op":"==","left":{"payload":{"protocol":"ip6","field":"daddr"}},` +
+					`"right":{"prefix":{"addr":"2001:db8::","len":32}}}}`,
+			},
+		}
+		for _, test := range tests {
+			serialized, err := json.Marshal(test.statement)
+			assert.NoError(t, err)
+			assert.Equal(t, test.serializedStatement, string(serialized), test.name)
+		}
+	})
+}
@@ -0,0 +1,318 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+// NormalizeStatements returns the statements in their higher-level form,
+// reverting the kernel rendering of matches which are listed differently from the way they have been defined.
+// It allows comparing statements which are read from the system with generated ones.
+//
+// The following normalizations are performed:
+//   - A `meta l4proto tcp|udp` match followed by `th` port matches, is replaced by the equivalent `tcp|udp` port matches
+//     (e.g. `meta l4proto tcp th dport 22` is normalized to `tcp dport 22`).
+//     This rendering is common on inet family rules.
+//   - Symbolic service and protocol names are replaced by their numbers in port and protocol matches
+//     (e.g. `tcp dport ssh` is normalized to `tcp dport 22` and `meta l4proto udp` to `meta l4proto 17`).
+//     The listing depends on the host services database and the nft numeric settings.
+func NormalizeStatements(statements []schema.Statement) []schema.Statement {
+	if statements == nil {
+		return nil
+	}
+	return normalizeMatchValues(normalizeTransportMatches(statements))
+}
+
+// NormalizeStatements normalizes the statements of all the rules in the configuration.
+// See NormalizeStatements for the normalizations performed.
+func (c *Config) NormalizeStatements() {
+	for _, nftable := range c.Nftables {
+		if rule := nftable.Rule; rule != nil {
+			rule.Expr = NormalizeStatements(rule.Expr)
+		}
+	}
+}
+
+// objectKindsOrder is the canonical order of the object kinds, in which Normalize sorts the objects.
+var objectKindsOrder = map[string]int{
+	kindTable: 0, kindChain: 1, kindSet: 2, kindMap: 3, kindFlowtable: 4, kindCounter: 5, kindLimit: 6,
+	kindQuota: 7, kindSynproxy: 8, kindCtHelper: 9, kindCtTimeout: 10,
+}
+
+// Normalize sorts the configuration entries into a canonical order and strips their volatile fields,
+// so that the serialized configuration is stable across runs and can be compared (e.g. stored in git).
+//
+// Entries with actions (e.g. flush or delete commands) keep their position, as moving them
+// would change the effect of the config when applied (e.g. a delete moved after the add it precedes).
+// The objects and rules between two such entries are ordered as follows:
+//   - Objects by their kind (tables, chains, sets, maps and then the stateful objects),
+//     then by their family, table and name.
+//   - Rules grouped by their family, table and chain, keeping their relative order in each chain.
+//
+// The metainfo entries, the object and rule handles and the stateful values (counter values,
+// quota used bytes and last used times) are removed.
+func (c *Config) Normalize() {
+	nftables := make([]schema.Nftable, 0, len(c.Nftables))
+	var objects, rules []schema.Nftable
+	for _, nftable := range c.Nftables {
+		nftable = withoutVolatileValues(nftable)
+		switch _, isObject := objectIdentity(nftable); {
+		case nftable.Metainfo != nil:
+		case nftable.Rule != nil:
+			rules = append(rules, nftable)
+		case isObject:
+			objects = append(objects, nftable)
+		default:
+			nftables = append(appendSortedEntries(nftables, objects, rules), nftable)
+			objects, rules = nil, nil
+		}
+	}
+	c.Nftables = appendSortedEntries(nftables, objects, rules)
+}
+
+// appendSortedEntries appends the objects and the rules in their canonical order (see Normalize).
+func appendSortedEntries(nftables, objects, rules []schema.Nftable) []schema.Nftable {
+	sort.SliceStable(objects, func(i, j int) bool {
+		a, _ := objectIdentity(objects[i])
+		b, _ := objectIdentity(objects[j])
+		if a.kind != b.kind {
+			return objectKindsOrder[a.kind] < objectKindsOrder[b.kind]
+		}
+		return a.family+" "+a.table+" "+a.name < b.family+" "+b.table+" "+b.name
+	})
+	sort.SliceStable(rules, func(i, j int) bool {
+		a, b := ruleChainID(rules[i].Rule), ruleChainID(rules[j].Rule)
+		return a.family+" "+a.table+" "+a.name < b.family+" "+b.table+" "+b.name
+	})
+
+	nftables = append(nftables, objects...)
+	return append(nftables, rules...)
+}
+
+// withoutVolatileValues returns a copy of the entry, excluding its handle and stateful values.
+func withoutVolatileValues(nftable schema.Nftable) schema.Nftable {
+	return withoutStatefulValues(withoutHandles(nftable))
+}
+
+// withoutStatefulValues returns a copy of the entry, excluding the values which reflect the traffic:
+// the counter values, the quota used bytes and the last used times (of both objects and rule statements).
+func withoutStatefulValues(nftable schema.Nftable) schema.Nftable {
+	switch {
+	case nftable.Counter != nil:
+		counter := *nftable.Counter
+		counter.Packets, counter.Bytes = 0, 0
+		nftable.Counter = &counter
+	case nftable.Quota != nil:
+		quota := *nftable.Quota
+		quota.Used = 0
+		nftable.Quota = &quota
+	case nftable.Rule != nil:
+		rule := *nftable.Rule
+		rule.Expr = append([]schema.Statement(nil), withoutCounterValues(rule.Expr)...)
+		for i, statement := range rule.Expr {
+			if q := statement.Quota; q != nil && q.Used != 0 {
+				quota := *q
+				quota.Used, quota.UsedUnit = 0, ""
+				rule.Expr[i].Quota = &quota
+			}
+			if statement.Last != nil && statement.Last.Used != nil {
+				rule.Expr[i].Last = &schema.Last{}
+			}
+		}
+		nftable.Rule = &rule
+	}
+	return nftable
+}
+
+func normalizeTransportMatches(statements []schema.Statement) []schema.Statement {
+	normalized := make([]schema.Statement, 0, len(statements))
+	for i := 0; i < len(statements); i++ {
+		protocol := l4ProtocolOfMatch(statements[i].Match)
+		if protocol == "" || i+1 >= len(statements) || !isTransportPortMatch(statements[i+1].Match) {
+			normalized = append(normalized, statements[i])
+			continue
+		}
+
+		for i+1 < len(statements) && isTransportPortMatch(statements[i+1].Match) {
+			i++
+			match := *statements[i].Match
+			match.Left.Payload = &schema.Payload{Protocol: protocol, Field: match.Left.Payload.Field}
+			normalized = append(normalized, schema.Statement{Match: &match})
+		}
+	}
+	return normalized
+}
+
+// l4ProtocolOfMatch returns the protocol of a `meta l4proto == tcp|udp` match,
+// or an empty string if the match is of a different form.
+func l4ProtocolOfMatch(match *schema.Match) string {
+	if match == nil || match.Op != schema.OperEQ || match.Left.Meta == nil || match.Left.Meta.Key != schema.MetaKeyL4Proto {
+		return ""
+	}
+	number, isProtocol := protocolNumber(match.Right)
+	if !isProtocol {
+		return ""
+	}
+	switch number {
+	case protocolNumbers[schema.PayloadProtocolTCP]:
+		return schema.PayloadProtocolTCP
+	case protocolNumbers[schema.PayloadProtocolUDP]:
+		return schema.PayloadProtocolUDP
+	}
+	return ""
+}
+
+func isTransportPortMatch(match *schema.Match) bool {
+	if match == nil || match.Left.Payload == nil || match.Left.Payload.Protocol != schema.PayloadProtocolTH {
+		return false
+	}
+	field := match.Left.Payload.Field
+	return field == schema.PayloadFieldTHSPort || field == schema.PayloadFieldTHDPort
+}
+
+// serviceNumbers maps well-known service names, as listed by nft, to their port numbers.
+var serviceNumbers = map[string]int{
+	"ftp-data": 20, "ftp": 21, "ssh": 22, "telnet": 23, "smtp": 25, "domain": 53, "bootps": 67, "bootpc": 68,
+	"tftp": 69, "http": 80, "kerberos": 88, "pop3": 110, "sunrpc": 111, "ntp": 123, "netbios-ns": 137,
+	"imap2": 143, "snmp": 161, "bgp": 179, "ldap": 389, "https": 443, "microsoft-ds": 445, "isakmp": 500,
+	"syslog": 514, "submission": 587, "ldaps": 636, "rsync": 873, "imaps": 993, "pop3s": 995,
+	"nfs": 2049, "mysql": 3306, "ms-wbt-server": 3389, "ipsec-nat-t": 4500, "postgresql": 5432, "mdns": 5353,
+	"http-alt": 8080,
+}
+
+// protocolNumbers maps the protocol names, as listed by nft, to their IP protocol numbers.
+var protocolNumbers = map[string]int{
+	"icmp": 1, "igmp": 2, "ipencap": 4, "tcp": 6, "udp": 17, "dccp": 33, "ipv6": 41, "gre": 47, "esp": 50,
+	"ah": 51, "icmpv6": 58, "ipv6-icmp": 58, "sctp": 132, "udplite": 136,
+}
+
+// normalizeMatchValues replaces the symbolic names in port and protocol matches by their numbers.
+// The given statements are not mutated.
+func normalizeMatchValues(statements []schema.Statement) []schema.Statement {
+	var normalized []schema.Statement
+	for i, statement := range statements {
+		match := statement.Match
+		if match == nil {
+			continue
+		}
+
+		var toNumber func(schema.Expression) (int, bool)
+		switch {
+		case isPortExpression(match.Left):
+			toNumber = portNumber
+		case isProtocolExpression(match.Left):
+			toNumber = protocolNumber
+		default:
+			continue
+		}
+
+		right, changed := normalizeValue(match.Right, toNumber)
+		if !changed {
+			continue
+		}
+		if normalized == nil {
+			normalized = append([]schema.Statement(nil), statements...)
+		}
+		m := *match
+		m.Right = right
+		normalized[i].Match = &m
+	}
+
+	if normalized == nil {
+		return statements
+	}
+	return normalized
+}
+
+// normalizeValue converts the value (or the anonymous set elements) to a number.
+// It reports if the value has been changed.
+func normalizeValue(value schema.Expression, toNumber func(schema.Expression) (int, bool)) (schema.Expression, bool) {
+	if value.Set != nil {
+		changed := false
+		elements := make([]schema.Expression, len(value.Set))
+		for i, element := range value.Set {
+			var elementChanged bool
+			elements[i], elementChanged = normalizeValue(element, toNumber)
+			changed = changed || elementChanged
+		}
+		return schema.Expression{Set: elements}, changed
+	}
+
+	if value.String == nil {
+		return value, false
+	}
+	number, ok := toNumber(value)
+	if !ok {
+		return value, false
+	}
+	n := float64(number)
+	return schema.Expression{Float64: &n}, true
+}
+
+func isPortExpression(expression schema.Expression) bool {
+	payload := expression.Payload
+	if payload == nil {
+		return false
+	}
+	switch payload.Protocol {
+	case schema.PayloadProtocolTCP, schema.PayloadProtocolUDP, schema.PayloadProtocolTH:
+		return payload.Field == schema.PayloadFieldTHSPort || payload.Field == schema.PayloadFieldTHDPort
+	}
+	return false
+}
+
+func isProtocolExpression(expression schema.Expression) bool {
+	if meta := expression.Meta; meta != nil {
+		return meta.Key == schema.MetaKeyL4Proto
+	}
+	if payload := expression.Payload; payload != nil {
+		return (payload.Protocol == schema.PayloadProtocolIP4 && payload.Field == schema.PayloadFieldIP4Protocol) ||
+			(payload.Protocol == schema.PayloadProtocolIP6 && payload.Field == schema.PayloadFieldIP6NextHdr)
+	}
+	return false
+}
+
+// portNumber returns the number of a port value, given as a number, a numerical string or a service name.
+func portNumber(value schema.Expression) (int, bool) {
+	return numberOf(value, serviceNumbers)
+}
+
+// protocolNumber returns the number of a protocol value, given as a number, a numerical string or a protocol name.
+func protocolNumber(value schema.Expression) (int, bool) {
+	return numberOf(value, protocolNumbers)
+}
+
+func numberOf(value schema.Expression, names map[string]int) (int, bool) {
+	switch {
+	case value.Float64 != nil:
+		return int(*value.Float64), true
+	case value.String != nil:
+		if number, err := strconv.Atoi(*value.String); err == nil {
+			return number, true
+		}
+		number, known := names[*value.String]
+		return number, known
+	}
+	return 0, false
+}
@@ -0,0 +1,196 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft_test
+
+import (
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/networkplumbing/go-nft/nft"
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+func TestNormalizeStatements(t *testing.T) {
+	t.Run("Normalize l4proto with transport header port match", func(t *testing.T) {
+		c := nft.NewConfig()
+		assert.NoError(t, c.FromJSON([]byte(`{"nftables":[{"rule":{
+			"family":"inet","table":"test-table","chain":"test-chain","expr":[
+			{"match":{"op":"==","left":{"meta":{"key":"l4proto"}},"right":"tcp"}},
+			{"match":{"op":"==","left":{"payload":{"protocol":"th","field":"dport"}},"right":22}},
+			{"accept":null}
+		]}}]}`)))
+		c.NormalizeStatements()
+
+		port := float64(22)
+		expected := []schema.Statement{
+			{Match: &schema.Match{
+				Op:    schema.OperEQ,
+				Left:  schema.Expression{Payload: &schema.Payload{Protocol: schema.PayloadProtocolTCP, Field: schema.PayloadFieldTCPDPort}},
+				Right: schema.Expression{Float64: &port},
+			}},
+			{Verdict: schema.Accept()},
+		}
+		assert.Equal(t, expected, c.Nftables[0].Rule.Expr)
+	})
+
+	t.Run("Keep l4proto match which is not followed by a port match", func(t *testing.T) {
+		protocol := schema.PayloadProtocolUDP
+		statements := []schema.Statement{
+			{Match: &schema.Match{
+				Op:    schema.OperEQ,
+				Left:  schema.Expression{Meta: &schema.Meta{Key: schema.MetaKeyL4Proto}},
+				Right: schema.Expression{String: &protocol},
+			}},
+			{Verdict: schema.Drop()},
+		}
+
+		protocolNumber := float64(17)
+		expected := []schema.Statement{
+			{Match: &schema.Match{
+				Op:    schema.OperEQ,
+				Left:  schema.Expression{Meta: &schema.Meta{Key: schema.MetaKeyL4Proto}},
+				Right: schema.Expression{Float64: &protocolNumber},
+			}},
+			{Verdict: schema.Drop()},
+		}
+		assert.Equal(t, expected, nft.NormalizeStatements(statements))
+		assert.Equal(t, protocol, *statements[0].Match.Right.String, "Expecting the input statements not to be mutated")
+	})
+
+	t.Run("Normalize numeric l4proto with transport header port match", func(t *testing.T) {
+		c := nft.NewConfig()
+		assert.NoError(t, c.FromJSON([]byte(`{"nftables":[{"rule":{
+			"family":"inet","table":"test-table","chain":"test-chain","expr":[
+			{"match":{"op":"==","left":{"meta":{"key":"l4proto"}},"right":6}},
+			{"match":{"op":"==","left":{"payload":{"protocol":"th","field":"dport"}},"right":"ssh"}}
+		]}}]}`)))
+		c.NormalizeStatements()
+
+		port := float64(22)
+		expected := []schema.Statement{
+			{Match: &schema.Match{
+				Op:    schema.OperEQ,
+				Left:  schema.Expression{Payload: &schema.Payload{Protocol: schema.PayloadProtocolTCP, Field: schema.PayloadFieldTCPDPort}},
+				Right: schema.Expression{Float64: &port},
+			}},
+		}
+		assert.Equal(t, expected, c.Nftables[0].Rule.Expr)
+	})
+
+	t.Run("Normalize service names and numerical strings in a port set", func(t *testing.T) {
+		http, https, custom, unknown := "http", "443", "8443", "unknown-service"
+		statements := []schema.Statement{{Match: &schema.Match{
+			Op:   schema.OperEQ,
+			Left: schema.Expression{Payload: &schema.Payload{Protocol: schema.PayloadProtocolUDP, Field: schema.PayloadFieldUDPSPort}},
+			Right: nft.AnonymousSet(
+				schema.Expression{String: &http}, schema.Expression{String: &https},
+				schema.Expression{String: &custom}, schema.Expression{String: &unknown},
+			),
+		}}}
+
+		httpPort, httpsPort, customPort := float64(80), float64(443), float64(8443)
+		expected := []schema.Statement{{Match: &schema.Match{
+			Op:   schema.OperEQ,
+			Left: statements[0].Match.Left,
+			Right: nft.AnonymousSet(
+				schema.Expression{Float64: &httpPort}, schema.Expression{Float64: &httpsPort},
+				schema.Expression{Float64: &customPort}, schema.Expression{String: &unknown},
+			),
+		}}}
+		assert.Equal(t, expected, nft.NormalizeStatements(statements))
+	})
+
+	t.Run("Lookup a rule listed with a service name", func(t *testing.T) {
+		c := nft.NewConfig()
+		assert.NoError(t, c.FromJSON([]byte(`{"nftables":[{"rule":{
+			"family":"ip","table":"test-table","chain":"test-chain","handle":3,"expr":[
+			{"match":{"op":"==","left":{"payload":{"protocol":"ip","field":"protocol"}},"right":"tcp"}},
+			{"match":{"op":"==","left":{"payload":{"protocol":"tcp","field":"dport"}},"right":"ssh"}},
+			{"accept":null}
+		]}}]}`)))
+
+		protocolNumber, port := float64(6), float64(22)
+		table := nft.NewTable(tableName, nft.FamilyIP)
+		rule := nft.NewRule(table, nft.NewRegularChain(table, chainName), []schema.Statement{
+			{Match: &schema.Match{
+				Op:    schema.OperEQ,
+				Left:  schema.Expression{Payload: &schema.Payload{Protocol: schema.PayloadProtocolIP4, Field: schema.PayloadFieldIP4Protocol}},
+				Right: schema.Expression{Float64: &protocolNumber},
+			}},
+			{Match: &schema.Match{
+				Op:    schema.OperEQ,
+				Left:  schema.Expression{Payload: &schema.Payload{Protocol: schema.PayloadProtocolTCP, Field: schema.PayloadFieldTCPDPort}},
+				Right: schema.Expression{Float64: &port},
+			}},
+			{Verdict: schema.Accept()},
+		}, nil, nil, "")
+		assert.Len(t, c.LookupRule(rule), 1)
+	})
+
+	t.Run("Lookup a rule listed with a protocol name", func(t *testing.T) {
+		c := nft.NewConfig()
+		assert.NoError(t, c.FromJSON([]byte(`{"nftables":[{"rule":{
+			"family":"inet","table":"test-table","chain":"test-chain","handle":3,"expr":[
+			{"match":{"op":"==","left":{"meta":{"key":"l4proto"}},"right":"tcp"}},
+			{"accept":null}
+		]}}]}`)))
+
+		table := nft.NewTable(tableName, nft.FamilyINET)
+		rule := nft.NewRule(table, nft.NewRegularChain(table, chainName), []schema.Statement{
+			nft.MatchProtocol(nft.L4ProtocolTCP),
+			nft.Accept(),
+		}, nil, nil, "")
+		assert.Len(t, c.LookupRule(rule), 1)
+	})
+}
+
+func TestNormalize(t *testing.T) {
+	c := nft.NewConfig()
+	assert.NoError(t, c.FromJSON([]byte(`{"nftables":[
+		{"metainfo":{"version":"1.0.2","release_name":"Lester Gooch #3","json_schema_version":1}},
+		{"rule":{"family":"ip","table":"test-table","chain":"b-chain","handle":7,"expr":[
+			{"counter":{"packets":3,"bytes":180}},{"accept":null}]}},
+		{"set":{"family":"ip","table":"test-table","name":"test-set","handle":5,"type":"ipv4_addr"}},
+		{"rule":{"family":"ip","table":"test-table","chain":"a-chain","handle":6,"expr":[{"drop":null}]}},
+		{"chain":{"family":"ip","table":"test-table","name":"b-chain","handle":2}},
+		{"chain":{"family":"ip","table":"test-table","name":"a-chain","handle":3}},
+		{"rule":{"family":"ip","table":"test-table","chain":"b-chain","handle":8,"expr":[
+			{"last":{"used":120}},{"drop":null}]}},
+		{"table":{"family":"ip","name":"test-table","handle":1}},
+		{"flush":{"ruleset":null}}
+	]}`)))
+
+	c.Normalize()
+
+	serialized, err := c.ToJSON()
+	assert.NoError(t, err)
+	// The flush command keeps its position, after the entries it follows.
+	assert.Equal(t, `{"nftables":[`+
+		`{"table":{"family":"ip","name":"test-table"}},`+
+		`{"chain":{"family":"ip","table":"test-table","name":"a-chain"}},`+
+		`{"chain":{"family":"ip","table":"test-table","name":"b-chain"}},`+
+		`{"set":{"family":"ip","table":"test-table","name":"test-set","type":"ipv4_addr"}},`+
+		`{"rule":{"family":"ip","table":"test-table","chain":"a-chain","expr":[{"drop":null}]}},`+
+		`{"rule":{"family":"ip","table":"test-table","chain":"b-chain","expr":[{"counter":{"packets":0,"bytes":0}},{"accept":null}]}},`+
+		`{"rule":{"family":"ip","table":"test-table","chain":"b-chain","expr":[{"last":null},{"drop":null}]}},`+
+		`{"flush":{"ruleset":null}}`+
+		`]}`, string(serialized))
+}
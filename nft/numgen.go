@@ -0,0 +1,43 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft
+
+import (
+	"fmt"
+
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+// MatchSample returns a match statement which matches a random sample of the packets,
+// approximately `rate` out of every `of` packets (`numgen random mod <of> < <rate>`).
+// For example, `MatchSample(1, 100)` matches about 1% of the traffic.
+// Followed by a log or queue statement, it allows analysing traffic with a bounded overhead.
+func MatchSample(rate int, of int) (schema.Statement, error) {
+	if of <= 0 || rate <= 0 || rate > of {
+		return schema.Statement{}, fmt.Errorf("invalid sample rate %d of %d", rate, of)
+	}
+
+	value := float64(rate)
+	return schema.Statement{Match: &schema.Match{
+		Op:    schema.OperLS,
+		Left:  schema.Expression{Numgen: &schema.Numgen{Mode: schema.NumgenModeRandom, Mod: of}},
+		Right: schema.Expression{Float64: &value},
+	}}, nil
+}
@@ -0,0 +1,59 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/networkplumbing/go-nft/nft"
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+func TestMatchSample(t *testing.T) {
+	const serializedStatement = `{"match":{"op":"\u003c","left":{"numgen":{"mode":"random","mod":100}},"right":1}}`
+
+	t.Run("Match a sample of the traffic, check serialization", func(t *testing.T) {
+		statement, err := nft.MatchSample(1, 100)
+		assert.NoError(t, err)
+
+		serialized, err := json.Marshal(statement)
+		assert.NoError(t, err)
+		assert.Equal(t, serializedStatement, string(serialized))
+	})
+
+	t.Run("Match a sample of the traffic, check deserialization", func(t *testing.T) {
+		var statement schema.Statement
+		assert.NoError(t, json.Unmarshal([]byte(serializedStatement), &statement))
+
+		expected, err := nft.MatchSample(1, 100)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, statement)
+	})
+
+	t.Run("Match a sample with an invalid rate", func(t *testing.T) {
+		_, err := nft.MatchSample(101, 100)
+		assert.Error(t, err)
+		_, err = nft.MatchSample(0, 100)
+		assert.Error(t, err)
+	})
+}
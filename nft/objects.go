@@ -0,0 +1,75 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+package nft
+
+import (
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+// The accessors return the objects which are declared by the configuration (without an explicit action),
+// in their configuration order.
+// Mutating the returned objects will result in mutating the configuration.
+
+// Tables returns the tables of the given family, or all the tables when the family is empty.
+func (c *Config) Tables(family AddressFamily) []*schema.Table {
+	var tables []*schema.Table
+	for _, nftable := range c.Nftables {
+		if table := nftable.Table; table != nil && (family == "" || table.Family == string(family)) {
+			tables = append(tables, table)
+		}
+	}
+	return tables
+}
+
+// Chains returns the chains of the given table, or all the chains when the table is nil.
+func (c *Config) Chains(table *schema.Table) []*schema.Chain {
+	var chains []*schema.Chain
+	for _, nftable := range c.Nftables {
+		if chain := nftable.Chain; chain != nil && isInTable(table, chain.Family, chain.Table) {
+			chains = append(chains, chain)
+		}
+	}
+	return chains
+}
+
+// Rules returns the rules of the given table, or all the rules when the table is nil.
+func (c *Config) Rules(table *schema.Table) []*schema.Rule {
+	var rules []*schema.Rule
+	for _, nftable := range c.Nftables {
+		if rule := nftable.Rule; rule != nil && isInTable(table, rule.Family, rule.Table) {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// Sets returns the sets of the given table, or all the sets when the table is nil.
+func (c *Config) Sets(table *schema.Table) []*schema.Set {
+	var sets []*schema.Set
+	for _, nftable := range c.Nftables {
+		if set := nftable.Set; set != nil && isInTable(table, set.Family, set.Table) {
+			sets = append(sets, set)
+		}
+	}
+	return sets
+}
+
+func isInTable(table *schema.Table, family, name string) bool {
+	return table == nil || (table.Family == family && table.Name == name)
+}
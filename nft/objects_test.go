@@ -0,0 +1,69 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+package nft_test
+
+import (
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/networkplumbing/go-nft/nft"
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+func TestAccessors(t *testing.T) {
+	table := nft.NewTable(tableName, nft.FamilyIP)
+	otherTable := nft.NewTable(tableName, nft.FamilyIP6)
+	chain := nft.NewRegularChain(table, chainName)
+	otherChain := nft.NewRegularChain(otherTable, chainName)
+	rule := nft.NewRule(table, chain, []schema.Statement{{Verdict: schema.Accept()}}, nil, nil, "")
+	otherRule := nft.NewRule(otherTable, otherChain, []schema.Statement{{Verdict: schema.Drop()}}, nil, nil, "")
+	set := nft.NewSet(table, setName, nft.SetTypeIPv4Addr, nil, nil)
+
+	config := nft.NewConfig()
+	config.AddTable(table)
+	config.AddTable(otherTable)
+	config.AddChain(chain)
+	config.AddChain(otherChain)
+	config.AddSet(set)
+	config.AddRule(rule)
+	config.AddRule(otherRule)
+	config.DeleteChain(nft.NewRegularChain(table, "stale-chain"))
+
+	t.Run("Tables", func(t *testing.T) {
+		assert.Equal(t, []*schema.Table{table, otherTable}, config.Tables(""))
+		assert.Equal(t, []*schema.Table{otherTable}, config.Tables(nft.FamilyIP6))
+		assert.Empty(t, config.Tables(nft.FamilyINET))
+	})
+
+	t.Run("Chains", func(t *testing.T) {
+		assert.Equal(t, []*schema.Chain{chain, otherChain}, config.Chains(nil))
+		assert.Equal(t, []*schema.Chain{otherChain}, config.Chains(otherTable))
+	})
+
+	t.Run("Rules", func(t *testing.T) {
+		assert.Equal(t, []*schema.Rule{rule, otherRule}, config.Rules(nil))
+		assert.Equal(t, []*schema.Rule{rule}, config.Rules(table))
+	})
+
+	t.Run("Sets", func(t *testing.T) {
+		assert.Equal(t, []*schema.Set{set}, config.Sets(nil))
+		assert.Empty(t, config.Sets(otherTable))
+	})
+}
@@ -0,0 +1,58 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft
+
+import (
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+// OwnedRules returns the rules of the config which are owned by the given owner,
+// i.e. whose comment metadata owner key is the given owner (see RuleMetadata).
+// Mutating the returned rules will result in mutating the configuration.
+func (c *Config) OwnedRules(owner string) []*schema.Rule {
+	return c.LookupRuleWithOptions(&schema.Rule{}, LookupRuleOptions{Metadata: RuleMetadata{MetadataKeyOwner: owner}})
+}
+
+// StaleOwnedRules returns a config which deletes the stale rules of the given owner.
+// A stale rule is a rule of the current config (commonly read from the system using ReadConfig) owned by the owner,
+// which the desired config does not add.
+// Rules are compared regardless of their handle, index and stateful values (e.g. anonymous counter values,
+// quota used bytes and last used times),
+// therefore a rule with an outdated metadata (e.g. a previous generation) is stale.
+// Current rules without a handle are ignored, as they cannot be deleted.
+//
+// It enables a controller which restarts with a new desired state to clean up the rules it has previously added,
+// without affecting the rules of other owners.
+func StaleOwnedRules(current, desired *Config, owner string) *Config {
+	desiredRules := map[string]bool{}
+	for _, nftable := range desired.Nftables {
+		if rule := addedRule(nftable); rule != nil {
+			desiredRules[ruleKey(rule)] = true
+		}
+	}
+
+	config := NewConfig()
+	for _, rule := range current.OwnedRules(owner) {
+		if rule.Handle != nil && !desiredRules[ruleKey(rule)] {
+			config.DeleteRule(rule)
+		}
+	}
+	return config
+}
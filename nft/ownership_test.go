@@ -0,0 +1,101 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft_test
+
+import (
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/networkplumbing/go-nft/nft"
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+const ruleOwner = "test-owner"
+
+func TestRuleOwnership(t *testing.T) {
+	table := nft.NewTable(tableName, nft.FamilyIP)
+	chain := nft.NewRegularChain(table, chainName)
+
+	// ownedRule returns a rule of the owner, without a handle when the given handle is zero.
+	ownedRule := func(handle int, ruleID, generation string, statements ...schema.Statement) *schema.Rule {
+		var h *int
+		if handle != 0 {
+			h = &handle
+		}
+		rule, err := nft.RuleWithMetadata(nft.NewRule(table, chain, statements, h, nil, ""), nft.RuleMetadata{
+			nft.MetadataKeyOwner:      ruleOwner,
+			nft.MetadataKeyRuleID:     ruleID,
+			nft.MetadataKeyGeneration: generation,
+		})
+		assert.NoError(t, err)
+		return rule
+	}
+
+	current := nft.NewConfig()
+	current.AddTable(table)
+	current.AddChain(chain)
+	kept := ownedRule(1, "ssh", "1", nft.MatchIIFName("eth0"), nft.Accept())
+	outdated := ownedRule(2, "web", "1", nft.MatchIIFName("eth1"), nft.Accept())
+	removed := ownedRule(3, "dns", "1", nft.MatchIIFName("eth2"), nft.Accept())
+	otherHandle := 4
+	other := nft.NewRule(table, chain, []schema.Statement{nft.Drop()}, &otherHandle, nil, "owner=other")
+	for _, rule := range []*schema.Rule{kept, outdated, removed, other} {
+		current.AddRule(rule)
+	}
+
+	t.Run("Owned rules", func(t *testing.T) {
+		assert.Equal(t, []*schema.Rule{kept, outdated, removed}, current.OwnedRules(ruleOwner))
+		assert.Empty(t, current.OwnedRules("missing"))
+	})
+
+	t.Run("Stale owned rules", func(t *testing.T) {
+		desired := nft.NewConfig()
+		desired.AddTable(table)
+		desired.AddChain(chain)
+		desired.AddRule(ownedRule(0, "ssh", "1", nft.MatchIIFName("eth0"), nft.Accept()))
+		desired.AddRule(ownedRule(0, "web", "2", nft.MatchIIFName("eth1"), nft.Accept()))
+
+		expected := nft.NewConfig()
+		expected.DeleteRule(outdated)
+		expected.DeleteRule(removed)
+		assert.Equal(t, expected, nft.StaleOwnedRules(current, desired, ruleOwner))
+	})
+
+	t.Run("Stale owned rules with stateful values", func(t *testing.T) {
+		used := 1500
+		quota, last := nft.NewQuota(1000, false), nft.NewLast()
+		quota.Quota.Used, last.Last.Used = 600, &used
+		currentStateful := nft.NewConfig()
+		currentStateful.AddRule(ownedRule(1, "ssh", "1", quota, last, nft.Accept()))
+		desired := nft.NewConfig()
+		desired.AddRule(ownedRule(0, "ssh", "1", nft.NewQuota(1000, false), nft.NewLast(), nft.Accept()))
+
+		assert.Empty(t, nft.StaleOwnedRules(currentStateful, desired, ruleOwner).Nftables)
+	})
+
+	t.Run("Stale owned rules without a desired state", func(t *testing.T) {
+		expected := nft.NewConfig()
+		expected.DeleteRule(kept)
+		expected.DeleteRule(outdated)
+		expected.DeleteRule(removed)
+		assert.Equal(t, expected, nft.StaleOwnedRules(current, nft.NewConfig(), ruleOwner))
+	})
+}
@@ -0,0 +1,44 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft
+
+import (
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+type PayloadBase string
+
+// Payload Bases
+const (
+	PayloadBaseLinkLayer PayloadBase = schema.PayloadBaseLL
+	PayloadBaseNetwork   PayloadBase = schema.PayloadBaseNH
+	PayloadBaseTransport PayloadBase = schema.PayloadBaseTH
+)
+
+// RawPayload returns a raw payload expression, referring to the header bits
+// at the given offset with the given length (both in bits) from the header base.
+// For example, `RawPayload(PayloadBaseTransport, 16, 16)` refers to the transport destination port (`@th,16,16`).
+func RawPayload(base PayloadBase, offset, length int) schema.Expression {
+	return schema.Expression{Payload: &schema.Payload{
+		Base:   string(base),
+		Offset: offset,
+		Len:    length,
+	}}
+}
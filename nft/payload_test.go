@@ -0,0 +1,72 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/networkplumbing/go-nft/nft"
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+func TestPayload(t *testing.T) {
+	tests := []struct {
+		name                 string
+		expression           schema.Expression
+		serializedExpression string
+	}{
+		{
+			name: "named payload",
+			expression: schema.Expression{Payload: &schema.Payload{
+				Protocol: schema.PayloadProtocolTCP,
+				Field:    schema.PayloadFieldTCPDPort,
+			}},
+			serializedExpression: `{"payload":{"protocol":"tcp","field":"dport"}}`,
+		},
+		{
+			name:                 "raw transport header payload",
+			expression:           nft.RawPayload(nft.PayloadBaseTransport, 16, 16),
+			serializedExpression: `{"payload":{"base":"th","offset":16,"len":16}}`,
+		},
+		{
+			name:                 "raw link layer payload at offset zero",
+			expression:           nft.RawPayload(nft.PayloadBaseLinkLayer, 0, 48),
+			serializedExpression: `{"payload":{"base":"ll","offset":0,"len":48}}`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name+", check serialization", func(t *testing.T) {
+			serialized, err := json.Marshal(test.expression)
+			assert.NoError(t, err)
+			assert.Equal(t, test.serializedExpression, string(serialized))
+		})
+
+		t.Run(test.name+", check deserialization", func(t *testing.T) {
+			var expression schema.Expression
+			assert.NoError(t, json.Unmarshal([]byte(test.serializedExpression), &expression))
+			assert.Equal(t, test.expression, expression)
+		})
+	}
+}
@@ -0,0 +1,69 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft
+
+import (
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+// PortField is a transport header port field, e.g. the tcp destination port (`tcp dport`).
+type PortField struct {
+	Protocol string
+	Field    string
+}
+
+// Port Fields
+var (
+	TCPSPort = PortField{Protocol: schema.PayloadProtocolTCP, Field: schema.PayloadFieldTCPSPort}
+	TCPDPort = PortField{Protocol: schema.PayloadProtocolTCP, Field: schema.PayloadFieldTCPDPort}
+	UDPSPort = PortField{Protocol: schema.PayloadProtocolUDP, Field: schema.PayloadFieldUDPSPort}
+	UDPDPort = PortField{Protocol: schema.PayloadProtocolUDP, Field: schema.PayloadFieldUDPDPort}
+	// The generic transport header ports (`th sport` and `th dport`) match any protocol with ports
+	// at the common offsets (e.g. tcp, udp and sctp).
+	THSPort = PortField{Protocol: schema.PayloadProtocolTH, Field: schema.PayloadFieldTHSPort}
+	THDPort = PortField{Protocol: schema.PayloadProtocolTH, Field: schema.PayloadFieldTHDPort}
+)
+
+// Expression returns the payload expression of the port field.
+func (f PortField) Expression() schema.Expression {
+	return schema.Expression{Payload: &schema.Payload{Protocol: f.Protocol, Field: f.Field}}
+}
+
+// Port returns the expression of a port number.
+func Port(port int) schema.Expression {
+	p := float64(port)
+	return schema.Expression{Float64: &p}
+}
+
+// PortRange returns an inclusive range expression of ports (e.g. `30000-32767`).
+func PortRange(from, to int) schema.Expression {
+	return NumberRange(from, to)
+}
+
+// MatchPort returns a match statement of the port field against the given expression,
+// commonly a Port, a PortRange or an anonymous set of them.
+// For example, `MatchPort(TCPDPort, PortRange(30000, 32767))` matches `tcp dport 30000-32767`.
+func MatchPort(field PortField, right schema.Expression) schema.Statement {
+	return schema.Statement{Match: &schema.Match{
+		Op:    schema.OperEQ,
+		Left:  field.Expression(),
+		Right: right,
+	}}
+}
@@ -0,0 +1,76 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/networkplumbing/go-nft/nft"
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+func TestMatchPort(t *testing.T) {
+	tests := []struct {
+		name                string
+		statement           schema.Statement
+		serializedStatement string
+	}{
+		{
+			name:                "tcp destination port",
+			statement:           nft.MatchPort(nft.TCPDPort, nft.Port(443)),
+			serializedStatement: `{"match":{"op":"==","left":{"payload":{"protocol":"tcp","field":"dport"}},"right":443}}`,
+		},
+		{
+			name:      "tcp destination port range",
+			statement: nft.MatchPort(nft.TCPDPort, nft.PortRange(30000, 32767)),
+			serializedStatement: `{"match":{"op":"==","left":{"payload":{"protocol":"tcp","field":"dport"}},` +
+				`"right":{"range":[30000,32767]}}}`,
+		},
+		{
+			name:      "udp source ports",
+			statement: nft.MatchPort(nft.UDPSPort, nft.AnonymousSet(nft.Port(53), nft.PortRange(5353, 5355))),
+			serializedStatement: `{"match":{"op":"==","left":{"payload":{"protocol":"udp","field":"sport"}},` +
+				`"right":{"set":[53,{"range":[5353,5355]}]}}}`,
+		},
+		{
+			name:                "transport header destination port",
+			statement:           nft.MatchPort(nft.THDPort, nft.Port(8080)),
+			serializedStatement: `{"match":{"op":"==","left":{"payload":{"protocol":"th","field":"dport"}},"right":8080}}`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name+", check serialization", func(t *testing.T) {
+			serialized, err := json.Marshal(test.statement)
+			assert.NoError(t, err)
+			assert.Equal(t, test.serializedStatement, string(serialized))
+		})
+
+		t.Run(test.name+", check deserialization", func(t *testing.T) {
+			var statement schema.Statement
+			assert.NoError(t, json.Unmarshal([]byte(test.serializedStatement), &statement))
+			assert.Equal(t, test.statement, statement)
+		})
+	}
+}
@@ -0,0 +1,61 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft
+
+import (
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+type QueueFlag string
+
+// Queue Flags
+const (
+	// QueueFlagBypass accepts the packets when no userspace program listens on the queue.
+	QueueFlagBypass QueueFlag = schema.QueueFlagBypass
+	// QueueFlagFanout distributes the packets across a range of queues, by their CPU.
+	QueueFlagFanout QueueFlag = schema.QueueFlagFanout
+)
+
+// NewQueue returns a queue statement, passing the packets to the given userspace queue.
+func NewQueue(num int, flags []QueueFlag) schema.Statement {
+	n := float64(num)
+	return NewQueueTo(schema.Expression{Float64: &n}, flags)
+}
+
+// NewNumgenQueue returns a queue statement, spreading the packets in turn across
+// `count` userspace queues, starting from the `first` queue (`queue to numgen inc mod <count> offset <first>`).
+// It allows balancing the packets between multiple userspace workers.
+func NewNumgenQueue(first int, count int, flags []QueueFlag) schema.Statement {
+	return NewQueueTo(schema.Expression{Numgen: &schema.Numgen{
+		Mode:   schema.NumgenModeInc,
+		Mod:    count,
+		Offset: first,
+	}}, flags)
+}
+
+// NewQueueTo returns a queue statement, passing the packets to the queue number
+// which is evaluated from the given expression.
+func NewQueueTo(num schema.Expression, flags []QueueFlag) schema.Statement {
+	q := &schema.Queue{Num: &num}
+	for _, flag := range flags {
+		q.Flags = append(q.Flags, string(flag))
+	}
+	return schema.Statement{Queue: q}
+}
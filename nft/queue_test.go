@@ -0,0 +1,69 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/networkplumbing/go-nft/nft"
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+func TestQueue(t *testing.T) {
+	tests := []struct {
+		name                string
+		statement           schema.Statement
+		serializedStatement string
+	}{
+		{
+			name:                "queue number",
+			statement:           nft.NewQueue(3, nil),
+			serializedStatement: `{"queue":{"num":3}}`,
+		},
+		{
+			name:                "queue number with bypass",
+			statement:           nft.NewQueue(0, []nft.QueueFlag{nft.QueueFlagBypass}),
+			serializedStatement: `{"queue":{"num":0,"flags":"bypass"}}`,
+		},
+		{
+			name:                "queue to numgen",
+			statement:           nft.NewNumgenQueue(2, 4, []nft.QueueFlag{nft.QueueFlagBypass, nft.QueueFlagFanout}),
+			serializedStatement: `{"queue":{"num":{"numgen":{"mode":"inc","mod":4,"offset":2}},"flags":["bypass","fanout"]}}`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name+", check serialization", func(t *testing.T) {
+			serialized, err := json.Marshal(test.statement)
+			assert.NoError(t, err)
+			assert.Equal(t, test.serializedStatement, string(serialized))
+		})
+
+		t.Run(test.name+", check deserialization", func(t *testing.T) {
+			var statement schema.Statement
+			assert.NoError(t, json.Unmarshal([]byte(test.serializedStatement), &statement))
+			assert.Equal(t, test.statement, statement)
+		})
+	}
+}
@@ -0,0 +1,77 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft
+
+import (
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+// NewNamedQuota returns a new schema quota object structure, matching the traffic until the given bytes are used.
+// When inverted (over), the quota matches the traffic once the bytes are used.
+func NewNamedQuota(table *schema.Table, name string, bytes int, over bool) *schema.NamedQuota {
+	return &schema.NamedQuota{
+		Family: table.Family,
+		Table:  table.Name,
+		Name:   name,
+		Bytes:  bytes,
+		Inv:    over,
+	}
+}
+
+// NewQuota returns an anonymous quota statement, matching the traffic until the given bytes are used
+// (e.g. `quota until 1000 bytes`), or once they are used when inverted (over).
+func NewQuota(bytes int, over bool) schema.Statement {
+	return schema.Statement{Quota: &schema.Quota{Val: bytes, ValUnit: schema.LimitUnitBytes, Inv: over}}
+}
+
+// NewQuotaReference returns a quota statement which applies the given named quota.
+func NewQuotaReference(quota *schema.NamedQuota) schema.Statement {
+	return schema.Statement{Quota: &schema.Quota{Name: quota.Name}}
+}
+
+// AddQuota appends the given quota object to the nftable config.
+// The quota is added without an explicit action (`add`).
+func (c *Config) AddQuota(quota *schema.NamedQuota) {
+	nftable := schema.Nftable{Quota: quota}
+	c.Nftables = append(c.Nftables, nftable)
+}
+
+// DeleteQuota appends a given quota object to the nftable config
+// with the `delete` action.
+// Attempting to delete a non-existing quota, results with a failure when the config is applied.
+// The quota must not be referenced by any rule.
+func (c *Config) DeleteQuota(quota *schema.NamedQuota) {
+	nftable := schema.Nftable{Delete: &schema.Objects{Quota: quota}}
+	c.Nftables = append(c.Nftables, nftable)
+}
+
+// LookupQuota searches the configuration for a matching quota object and returns it.
+// The quota is matched by the table and quota name.
+// Mutating the returned quota will result in mutating the configuration.
+func (c *Config) LookupQuota(toFind *schema.NamedQuota) *schema.NamedQuota {
+	for _, nftable := range c.Nftables {
+		if quota := nftable.Quota; quota != nil {
+			if quota.Table == toFind.Table && quota.Family == toFind.Family && quota.Name == toFind.Name {
+				return quota
+			}
+		}
+	}
+	return nil
+}
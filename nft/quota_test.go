@@ -0,0 +1,131 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/networkplumbing/go-nft/nft"
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+const quotaName = "test-quota"
+
+func TestQuota(t *testing.T) {
+	testQuotaObjectActions(t)
+	testQuotaLookup(t)
+	testQuotaStatements(t)
+}
+
+func testQuotaObjectActions(t *testing.T) {
+	table := nft.NewTable(tableName, nft.FamilyIP)
+	quota := nft.NewNamedQuota(table, quotaName, 1000000, true)
+	quotaArgs := fmt.Sprintf(`"family":"ip","table":%q,"name":%q,"bytes":1000000,"inv":true`, tableName, quotaName)
+
+	actions := map[string]func(*nft.Config){
+		"add":    func(c *nft.Config) { c.AddQuota(quota) },
+		"delete": func(c *nft.Config) { c.DeleteQuota(quota) },
+	}
+	expected := map[string]string{
+		"add":    fmt.Sprintf(`{"nftables":[{"quota":{%s}}]}`, quotaArgs),
+		"delete": fmt.Sprintf(`{"nftables":[{"delete":{"quota":{%s}}}]}`, quotaArgs),
+	}
+
+	for action, actionFunc := range actions {
+		action, actionFunc := action, actionFunc
+		t.Run(fmt.Sprintf("%s quota", action), func(t *testing.T) {
+			config := nft.NewConfig()
+			actionFunc(config)
+
+			serialized, err := config.ToJSON()
+			assert.NoError(t, err)
+			assert.Equal(t, expected[action], string(serialized))
+		})
+	}
+
+	t.Run("Read quota", func(t *testing.T) {
+		var deserializedConfig nft.Config
+		assert.NoError(t, json.Unmarshal([]byte(expected["add"]), &deserializedConfig))
+
+		expectedConfig := nft.NewConfig()
+		expectedConfig.AddQuota(quota)
+		assert.Equal(t, expectedConfig, &deserializedConfig)
+	})
+}
+
+func testQuotaLookup(t *testing.T) {
+	config := nft.NewConfig()
+	table := nft.NewTable(tableName, nft.FamilyIP)
+	quota := nft.NewNamedQuota(table, quotaName, 1000, false)
+	config.AddQuota(quota)
+
+	t.Run("Lookup an existing quota", func(t *testing.T) {
+		assert.Equal(t, quota, config.LookupQuota(&schema.NamedQuota{Family: table.Family, Table: table.Name, Name: quotaName}))
+	})
+
+	t.Run("Lookup a missing quota", func(t *testing.T) {
+		assert.Nil(t, config.LookupQuota(&schema.NamedQuota{Family: table.Family, Table: table.Name, Name: "quota-na"}))
+	})
+}
+
+func testQuotaStatements(t *testing.T) {
+	table := nft.NewTable(tableName, nft.FamilyIP)
+
+	tests := []struct {
+		name                string
+		statement           schema.Statement
+		serializedStatement string
+	}{
+		{
+			name:                "Anonymous quota",
+			statement:           nft.NewQuota(1000, false),
+			serializedStatement: `{"quota":{"val":1000,"val_unit":"bytes"}}`,
+		},
+		{
+			name:                "Listed anonymous quota",
+			statement:           schema.Statement{Quota: &schema.Quota{Val: 25, ValUnit: "mbytes", Used: 300, UsedUnit: "bytes", Inv: true}},
+			serializedStatement: `{"quota":{"val":25,"val_unit":"mbytes","used":300,"used_unit":"bytes","inv":true}}`,
+		},
+		{
+			name:                "Quota reference",
+			statement:           nft.NewQuotaReference(nft.NewNamedQuota(table, quotaName, 1000, false)),
+			serializedStatement: fmt.Sprintf(`{"quota":%q}`, quotaName),
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name+", check serialization", func(t *testing.T) {
+			serialized, err := json.Marshal(test.statement)
+			assert.NoError(t, err)
+			assert.Equal(t, test.serializedStatement, string(serialized))
+		})
+
+		t.Run(test.name+", check deserialization", func(t *testing.T) {
+			var statement schema.Statement
+			assert.NoError(t, json.Unmarshal([]byte(test.serializedStatement), &statement))
+			assert.Equal(t, test.statement, statement)
+		})
+	}
+}
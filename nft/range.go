@@ -0,0 +1,43 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft
+
+import (
+	"net"
+
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+// Range returns an inclusive range expression between the given bounds.
+func Range(from, to schema.Expression) schema.Expression {
+	return schema.Expression{Range: &[2]schema.Expression{from, to}}
+}
+
+// NumberRange returns an inclusive range expression of numbers, e.g. a port range (`1024-65535`).
+func NumberRange(from, to int) schema.Expression {
+	f, t := float64(from), float64(to)
+	return Range(schema.Expression{Float64: &f}, schema.Expression{Float64: &t})
+}
+
+// AddressRange returns an inclusive range expression of IP addresses (e.g. `10.0.0.1-10.0.0.9`).
+func AddressRange(from, to net.IP) schema.Expression {
+	f, t := from.String(), to.String()
+	return Range(schema.Expression{String: &f}, schema.Expression{String: &t})
+}
@@ -0,0 +1,65 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft_test
+
+import (
+	"encoding/json"
+	"net"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/networkplumbing/go-nft/nft"
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+func TestRange(t *testing.T) {
+	tests := []struct {
+		name                 string
+		expression           schema.Expression
+		serializedExpression string
+	}{
+		{
+			name:                 "number range",
+			expression:           nft.NumberRange(1024, 65535),
+			serializedExpression: `{"range":[1024,65535]}`,
+		},
+		{
+			name:                 "address range",
+			expression:           nft.AddressRange(net.ParseIP("10.0.0.1"), net.ParseIP("10.0.0.9")),
+			serializedExpression: `{"range":["10.0.0.1","10.0.0.9"]}`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name+", check serialization", func(t *testing.T) {
+			serialized, err := json.Marshal(test.expression)
+			assert.NoError(t, err)
+			assert.Equal(t, test.serializedExpression, string(serialized))
+		})
+
+		t.Run(test.name+", check deserialization", func(t *testing.T) {
+			var expression schema.Expression
+			assert.NoError(t, json.Unmarshal([]byte(test.serializedExpression), &expression))
+			assert.Equal(t, test.expression, expression)
+		})
+	}
+}
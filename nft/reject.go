@@ -0,0 +1,145 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft
+
+import (
+	"fmt"
+
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+type RejectType string
+type RejectCode string
+
+// Reject Types
+const (
+	RejectTypeTCPReset RejectType = schema.RejectTypeTCPReset
+	RejectTypeICMP     RejectType = schema.RejectTypeICMP
+	RejectTypeICMPv6   RejectType = schema.RejectTypeICMPv6
+	RejectTypeICMPx    RejectType = schema.RejectTypeICMPx
+)
+
+// ICMP (IPv4) Reject Codes
+const (
+	ICMPCodeNetUnreachable  RejectCode = schema.ICMPCodeNetUnreachable
+	ICMPCodeHostUnreachable RejectCode = schema.ICMPCodeHostUnreachable
+	ICMPCodeProtUnreachable RejectCode = schema.ICMPCodeProtUnreachable
+	ICMPCodePortUnreachable RejectCode = schema.ICMPCodePortUnreachable
+	ICMPCodeNetProhibited   RejectCode = schema.ICMPCodeNetProhibited
+	ICMPCodeHostProhibited  RejectCode = schema.ICMPCodeHostProhibited
+	ICMPCodeAdminProhibited RejectCode = schema.ICMPCodeAdminProhibited
+)
+
+// ICMPv6 Reject Codes
+const (
+	ICMPv6CodeNoRoute         RejectCode = schema.ICMPv6CodeNoRoute
+	ICMPv6CodeAdminProhibited RejectCode = schema.ICMPv6CodeAdminProhibited
+	ICMPv6CodeAddrUnreachable RejectCode = schema.ICMPv6CodeAddrUnreachable
+	ICMPv6CodePortUnreachable RejectCode = schema.ICMPv6CodePortUnreachable
+	ICMPv6CodePolicyFail      RejectCode = schema.ICMPv6CodePolicyFail
+	ICMPv6CodeRejectRoute     RejectCode = schema.ICMPv6CodeRejectRoute
+)
+
+// ICMPx (inet) Reject Codes
+const (
+	ICMPxCodeNoRoute         RejectCode = schema.ICMPxCodeNoRoute
+	ICMPxCodePortUnreachable RejectCode = schema.ICMPxCodePortUnreachable
+	ICMPxCodeHostUnreachable RejectCode = schema.ICMPxCodeHostUnreachable
+	ICMPxCodeAdminProhibited RejectCode = schema.ICMPxCodeAdminProhibited
+)
+
+// rejectTypeFamilies lists the address families which support each reject type.
+var rejectTypeFamilies = map[string][]string{
+	schema.RejectTypeTCPReset: {schema.FamilyIP, schema.FamilyIP6, schema.FamilyINET, schema.FamilyBridge, schema.FamilyNETDEV},
+	schema.RejectTypeICMP:     {schema.FamilyIP, schema.FamilyINET, schema.FamilyBridge, schema.FamilyNETDEV},
+	schema.RejectTypeICMPv6:   {schema.FamilyIP6, schema.FamilyINET, schema.FamilyBridge, schema.FamilyNETDEV},
+	schema.RejectTypeICMPx:    {schema.FamilyINET, schema.FamilyBridge, schema.FamilyNETDEV},
+}
+
+// rejectTypeCodes lists the codes of each reject type.
+var rejectTypeCodes = map[string][]string{
+	schema.RejectTypeTCPReset: {},
+	schema.RejectTypeICMP: {
+		schema.ICMPCodeNetUnreachable, schema.ICMPCodeHostUnreachable, schema.ICMPCodeProtUnreachable,
+		schema.ICMPCodePortUnreachable, schema.ICMPCodeNetProhibited, schema.ICMPCodeHostProhibited,
+		schema.ICMPCodeAdminProhibited,
+	},
+	schema.RejectTypeICMPv6: {
+		schema.ICMPv6CodeNoRoute, schema.ICMPv6CodeAdminProhibited, schema.ICMPv6CodeAddrUnreachable,
+		schema.ICMPv6CodePortUnreachable, schema.ICMPv6CodePolicyFail, schema.ICMPv6CodeRejectRoute,
+	},
+	schema.RejectTypeICMPx: {
+		schema.ICMPxCodeNoRoute, schema.ICMPxCodePortUnreachable, schema.ICMPxCodeHostUnreachable,
+		schema.ICMPxCodeAdminProhibited,
+	},
+}
+
+// RejectWith returns a reject statement of the given type and code (e.g. `reject with icmpx admin-prohibited`).
+// The code is optional (empty), as well as the type for the default reject.
+// For the tcp reset type, no code is expected.
+func RejectWith(rejectType RejectType, code RejectCode) schema.Statement {
+	reject := &schema.Reject{Type: string(rejectType)}
+	if code != "" {
+		c := string(code)
+		reject.Expr = &schema.Expression{String: &c}
+	}
+	return schema.Statement{Reject: reject}
+}
+
+// ValidateReject verifies that the reject type is supported by the address family
+// and that the reject code is legal for the type.
+// ICMP codes are supported by the ip family, ICMPv6 codes by the ip6 family and
+// ICMPx codes by the inet family (all types are supported by the bridge and netdev families).
+func ValidateReject(family string, reject *schema.Reject) error {
+	if reject.Type == "" {
+		if reject.Expr != nil {
+			return fmt.Errorf("reject code without a type")
+		}
+		return nil
+	}
+
+	families, known := rejectTypeFamilies[reject.Type]
+	if !known {
+		return fmt.Errorf("unknown reject type %q", reject.Type)
+	}
+	if !containsString(families, family) {
+		return fmt.Errorf("reject type %q is not supported in the %s family", reject.Type, family)
+	}
+
+	if reject.Expr == nil {
+		return nil
+	}
+	if reject.Expr.String == nil {
+		return fmt.Errorf("unsupported reject %q code expression", reject.Type)
+	}
+	if !containsString(rejectTypeCodes[reject.Type], *reject.Expr.String) {
+		return fmt.Errorf("reject code %q is not legal for the %q type", *reject.Expr.String, reject.Type)
+	}
+	return nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
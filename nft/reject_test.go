@@ -0,0 +1,113 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/networkplumbing/go-nft/nft"
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+func TestReject(t *testing.T) {
+	testRejectSerialization(t)
+	testValidateReject(t)
+}
+
+func testRejectSerialization(t *testing.T) {
+	tests := []struct {
+		name                string
+		statement           schema.Statement
+		serializedStatement string
+	}{
+		{
+			name:                "reject",
+			statement:           nft.RejectWith("", ""),
+			serializedStatement: `{"reject":null}`,
+		},
+		{
+			name:                "reject with tcp reset",
+			statement:           nft.RejectWith(nft.RejectTypeTCPReset, ""),
+			serializedStatement: `{"reject":{"type":"tcp reset"}}`,
+		},
+		{
+			name:                "reject with icmpx admin-prohibited",
+			statement:           nft.RejectWith(nft.RejectTypeICMPx, nft.ICMPxCodeAdminProhibited),
+			serializedStatement: `{"reject":{"type":"icmpx","expr":"admin-prohibited"}}`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name+", check serialization", func(t *testing.T) {
+			serialized, err := json.Marshal(test.statement)
+			assert.NoError(t, err)
+			assert.Equal(t, test.serializedStatement, string(serialized))
+		})
+
+		t.Run(test.name+", check deserialization", func(t *testing.T) {
+			var statement schema.Statement
+			assert.NoError(t, json.Unmarshal([]byte(test.serializedStatement), &statement))
+			assert.Equal(t, test.statement, statement)
+		})
+	}
+}
+
+func testValidateReject(t *testing.T) {
+	tests := []struct {
+		name      string
+		family    string
+		statement schema.Statement
+		valid     bool
+	}{
+		{"icmp code in the ip family", schema.FamilyIP, nft.RejectWith(nft.RejectTypeICMP, nft.ICMPCodeHostUnreachable), true},
+		{"icmpv6 code in the ip6 family", schema.FamilyIP6, nft.RejectWith(nft.RejectTypeICMPv6, nft.ICMPv6CodeNoRoute), true},
+		{"icmpx code in the inet family", schema.FamilyINET, nft.RejectWith(nft.RejectTypeICMPx, nft.ICMPxCodePortUnreachable), true},
+		{"tcp reset in the ip6 family", schema.FamilyIP6, nft.RejectWith(nft.RejectTypeTCPReset, ""), true},
+		{"default reject in the ip family", schema.FamilyIP, nft.RejectWith("", ""), true},
+		{"icmpv6 code in the ip family", schema.FamilyIP, nft.RejectWith(nft.RejectTypeICMPv6, nft.ICMPv6CodeNoRoute), false},
+		{"icmpx code in the ip family", schema.FamilyIP, nft.RejectWith(nft.RejectTypeICMPx, nft.ICMPxCodeNoRoute), false},
+		{"icmpv6 only code of the icmp type", schema.FamilyIP, nft.RejectWith(nft.RejectTypeICMP, nft.ICMPv6CodePolicyFail), false},
+		{"tcp reset with a code", schema.FamilyIP, nft.RejectWith(nft.RejectTypeTCPReset, nft.ICMPCodePortUnreachable), false},
+		{"code without a type", schema.FamilyIP, nft.RejectWith("", nft.ICMPCodePortUnreachable), false},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run("Validate reject with "+test.name, func(t *testing.T) {
+			err := nft.ValidateReject(test.family, test.statement.Reject)
+			if test.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+
+	t.Run("Validate rule family with an illegal reject", func(t *testing.T) {
+		rule := newFamilyRule(schema.FamilyIP6, nft.RejectWith(nft.RejectTypeICMP, nft.ICMPCodeHostProhibited))
+		assert.EqualError(t, nft.ValidateRuleFamily(rule),
+			`rule in chain "test-chain": statement 0: reject type "icmp" is not supported in the ip6 family`,
+		)
+	})
+}
@@ -0,0 +1,79 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft
+
+import (
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+const (
+	cmdReset  = "reset"
+	cmdQuotas = "quotas"
+)
+
+// ResetCounter appends a given counter object to the nftable config with the `reset` action,
+// zeroing its packets and bytes when applied.
+func (c *Config) ResetCounter(counter *schema.NamedCounter) {
+	nftable := schema.Nftable{Reset: &schema.Objects{Counter: counter}}
+	c.Nftables = append(c.Nftables, nftable)
+}
+
+// ResetQuota appends a given quota object to the nftable config with the `reset` action,
+// zeroing its used bytes when applied.
+func (c *Config) ResetQuota(quota *schema.NamedQuota) {
+	nftable := schema.Nftable{Reset: &schema.Objects{Quota: quota}}
+	c.Nftables = append(c.Nftables, nftable)
+}
+
+// ResetRule appends a given rule to the nftable config with the `reset` action,
+// zeroing the stateful statements of the rule (e.g. the anonymous counters and quotas) when applied.
+// A rule is identified by its handle ID and it must be present in the given rule.
+func (c *Config) ResetRule(rule *schema.Rule) {
+	nftable := schema.Nftable{Reset: &schema.Objects{Rule: rule}}
+	c.Nftables = append(c.Nftables, nftable)
+}
+
+// ResetCounters resets the named counters of the given table on the system and
+// returns their values before the reset, as a nftables config structure.
+// Reading and zeroing the counters is atomic, no counted traffic is lost between periodic collections.
+// The system is expected to have the `nft` executable deployed and nftables enabled in the kernel.
+func ResetCounters(table *schema.Table) (*Config, error) {
+	return defaultExecutor.resetConfig(cmdCounters, cmdTable, table.Family, table.Name)
+}
+
+// ResetQuotas resets the named quotas of the given table on the system and
+// returns their values before the reset, as a nftables config structure (see ResetCounters).
+// The system is expected to have the `nft` executable deployed and nftables enabled in the kernel.
+func ResetQuotas(table *schema.Table) (*Config, error) {
+	return defaultExecutor.resetConfig(cmdQuotas, cmdTable, table.Family, table.Name)
+}
+
+func (e *executor) resetConfig(resetArgs ...string) (*Config, error) {
+	stdout, err := e.execCommand(nil, append([]string{cmdJSON, cmdReset}, resetArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	config := NewConfig()
+	if err := config.FromJSON(stdout.Bytes()); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
@@ -0,0 +1,54 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft_test
+
+import (
+	"fmt"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/networkplumbing/go-nft/nft"
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+func TestReset(t *testing.T) {
+	table := nft.NewTable(tableName, nft.FamilyIP)
+	chain := nft.NewRegularChain(table, chainName)
+	handle := 7
+
+	config := nft.NewConfig()
+	config.ResetCounter(&schema.NamedCounter{Family: table.Family, Table: table.Name, Name: "hits"})
+	config.ResetQuota(nft.NewNamedQuota(table, quotaName, 1000, false))
+	config.ResetRule(nft.NewRule(table, chain, nil, &handle, nil, ""))
+
+	serialized, err := config.ToJSON()
+	assert.NoError(t, err)
+	expected := fmt.Sprintf(`{"nftables":[`+
+		`{"reset":{"counter":{"family":"ip","table":%[1]q,"name":"hits","packets":0,"bytes":0}}},`+
+		`{"reset":{"quota":{"family":"ip","table":%[1]q,"name":%[2]q,"bytes":1000}}},`+
+		`{"reset":{"rule":{"family":"ip","table":%[1]q,"chain":%[3]q,"handle":7}}}`+
+		`]}`, tableName, quotaName, chainName)
+	assert.Equal(t, expected, string(serialized))
+
+	deserializedConfig := nft.NewConfig()
+	assert.NoError(t, deserializedConfig.FromJSON(serialized))
+	assert.Equal(t, config, deserializedConfig)
+}
@@ -0,0 +1,44 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft
+
+import (
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+type RtKey string
+
+// Routing Keys
+const (
+	// RtKeyClassid is the routing realm.
+	RtKeyClassid RtKey = schema.RtKeyClassid
+	// RtKeyNexthop is the routing nexthop address.
+	RtKeyNexthop RtKey = schema.RtKeyNexthop
+	// RtKeyMtu is the TCP maximum segment size of the route.
+	RtKeyMtu RtKey = schema.RtKeyMtu
+	// RtKeyIpsec reports if the route is processed by IPsec.
+	RtKeyIpsec RtKey = schema.RtKeyIpsec
+)
+
+// RtExpression returns a routing information expression of the given key (`rt <key>`).
+// The family (ip or ip6) is optional, required by the nexthop key in tables of the inet family.
+func RtExpression(key RtKey, family AddressFamily) schema.Expression {
+	return schema.Expression{Rt: &schema.Rt{Key: string(key), Family: string(family)}}
+}
@@ -0,0 +1,74 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/networkplumbing/go-nft/nft"
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+func TestRtExpression(t *testing.T) {
+	tests := []struct {
+		name                 string
+		expression           schema.Expression
+		serializedExpression string
+	}{
+		{
+			name:                 "rt classid",
+			expression:           nft.RtExpression(nft.RtKeyClassid, ""),
+			serializedExpression: `{"rt":{"key":"classid"}}`,
+		},
+		{
+			name:                 "rt ip6 nexthop",
+			expression:           nft.RtExpression(nft.RtKeyNexthop, nft.FamilyIP6),
+			serializedExpression: `{"rt":{"key":"nexthop","family":"ip6"}}`,
+		},
+		{
+			name:                 "rt mtu",
+			expression:           nft.RtExpression(nft.RtKeyMtu, ""),
+			serializedExpression: `{"rt":{"key":"mtu"}}`,
+		},
+		{
+			name:                 "rt ipsec",
+			expression:           nft.RtExpression(nft.RtKeyIpsec, ""),
+			serializedExpression: `{"rt":{"key":"ipsec"}}`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name+", check serialization", func(t *testing.T) {
+			serialized, err := json.Marshal(test.expression)
+			assert.NoError(t, err)
+			assert.Equal(t, test.serializedExpression, string(serialized))
+		})
+
+		t.Run(test.name+", check deserialization", func(t *testing.T) {
+			var expression schema.Expression
+			assert.NoError(t, json.Unmarshal([]byte(test.serializedExpression), &expression))
+			assert.Equal(t, test.expression, expression)
+		})
+	}
+}
@@ -21,7 +21,8 @@ package nft
 
 import (
 	"bytes"
-	"encoding/json"
+	"fmt"
+	"sort"
 
 	"github.com/networkplumbing/go-nft/nft/schema"
 )
@@ -49,6 +50,28 @@ func (c *Config) AddRule(rule *schema.Rule) {
 	c.Nftables = append(c.Nftables, nftable)
 }
 
+// AddRuleChecked appends the given rule to the nftable config, similar to AddRule,
+// after verifying that the rule table is declared in the config
+// and that the rule statements are compatible with its family (see ValidateRuleFamily).
+// See AddChainChecked for details.
+func (c *Config) AddRuleChecked(rule *schema.Rule) error {
+	if err := c.checkTableDeclared(rule.Family, rule.Table); err != nil {
+		return fmt.Errorf("rule in chain %q: %v", rule.Chain, err)
+	}
+	if err := ValidateRuleFamily(rule); err != nil {
+		return err
+	}
+	c.AddRule(rule)
+	return nil
+}
+
+// InsertRule appends the given rule to the nftable config with the `insert` action.
+// The rule is inserted at the beginning of its chain, or before the rule of the given handle when set.
+func (c *Config) InsertRule(rule *schema.Rule) {
+	nftable := schema.Nftable{Insert: &schema.Objects{Rule: rule}}
+	c.Nftables = append(c.Nftables, nftable)
+}
+
 // DeleteRule appends a given rule to the nftable config
 // with the `delete` action.
 // A rule is identified by its handle ID and it must be present in the given rule.
@@ -59,49 +82,207 @@ func (c *Config) DeleteRule(rule *schema.Rule) {
 	c.Nftables = append(c.Nftables, nftable)
 }
 
+// DestroyRule appends a given rule to the nftable config with the `destroy` action.
+// A rule is identified by its handle ID and it must be present in the given rule.
+// Unlike deleting, destroying a non-existing rule has no effect when the config is applied.
+func (c *Config) DestroyRule(rule *schema.Rule) {
+	nftable := schema.Nftable{Destroy: &schema.Objects{Rule: rule}}
+	c.Nftables = append(c.Nftables, nftable)
+}
+
+// DeleteRulesWhere appends to the nftable config the commands to delete the rules of the given table and chain
+// which satisfy the predicate, as found in the current config (commonly read from the system using ReadConfig).
+// When the chain is nil, the rules of all the table chains are considered.
+// Rules are deleted by their handle, therefore rules without a handle are skipped.
+// It returns the number of rules which are deleted (when applied).
+func (c *Config) DeleteRulesWhere(current *Config, table *schema.Table, chain *schema.Chain, predicate func(*schema.Rule) bool) int {
+	deleted := 0
+	for _, nftable := range current.Nftables {
+		rule := nftable.Rule
+		if rule == nil || rule.Handle == nil || rule.Family != table.Family || rule.Table != table.Name {
+			continue
+		}
+		if chain != nil && rule.Chain != chain.Name {
+			continue
+		}
+		if predicate(rule) {
+			c.DeleteRule(rule)
+			deleted++
+		}
+	}
+	return deleted
+}
+
+// EnsureRule appends the given rule to the nftable config, unless an equivalent rule is already
+// appended to the same chain.
+// Rules are equivalent when they have the same comment and statements, regardless of their handle, index
+// and counter values. The statements are compared in their normalized and canonical form.
+// See EnsureTable for details.
+func (c *Config) EnsureRule(rule *schema.Rule) bool {
+	key := ruleKey(rule)
+	for _, nftable := range c.Nftables {
+		if r := nftable.Rule; r != nil && ruleKey(r) == key {
+			return false
+		}
+	}
+	c.AddRule(rule)
+	return true
+}
+
+// RemoveRule removes the previously appended rules which match the given rule from the nftable config,
+// it does not affect the system.
+// The rules are matched as with LookupRule, entries with an explicit action (e.g. a rule deletion) are not removed.
+// It returns the number of removed rules.
+func (c *Config) RemoveRule(rule *schema.Rule) int {
+	matches := map[*schema.Rule]bool{}
+	for _, r := range c.LookupRule(rule) {
+		matches[r] = true
+	}
+	return c.removeEntries(func(nftable schema.Nftable) bool {
+		return nftable.Rule != nil && matches[nftable.Rule]
+	})
+}
+
 // LookupRule searches the configuration for a matching rule and returns it.
-// The rule is matched first by the table and chain.
+// The rule is matched first by the table and chain, an empty family, table or chain matches any
+// (e.g. the rules with a given comment are matched in all the tables).
 // Other matching fields are optional (nil or an empty string arguments imply no-matching).
+// Statements are compared in their canonical form (see CanonicalizeStatements).
 // Mutating the returned chain will result in mutating the configuration.
 func (c *Config) LookupRule(toFind *schema.Rule) []*schema.Rule {
+	return c.LookupRuleWithOptions(toFind, LookupRuleOptions{})
+}
+
+// LookupRuleOptions tunes the matching strictness of LookupRuleWithOptions.
+// The zero value provides the LookupRule semantics.
+type LookupRuleOptions struct {
+	// CommentOnly matches rules by the table, chain and comment only.
+	CommentOnly bool
+	// Metadata matches rules by the table, chain and the metadata encoded in their comment only.
+	// A rule matches when its metadata includes all the given key-values (see RuleMetadata).
+	Metadata RuleMetadata
+	// IgnoreHandle ignores the handle of the rule to find.
+	IgnoreHandle bool
+	// IgnoreIndex ignores the index of the rule to find.
+	// Rules which are read from the system have no index.
+	IgnoreIndex bool
+	// IgnoreMatchOrder compares consecutive match statements regardless of their order.
+	// Matches are commutative, therefore such statements are equivalent.
+	IgnoreMatchOrder bool
+}
+
+// LookupRuleWithOptions searches the configuration for a matching rule and returns it,
+// using the options to tune the matching.
+// See LookupRule for the default matching semantics.
+func (c *Config) LookupRuleWithOptions(toFind *schema.Rule, options LookupRuleOptions) []*schema.Rule {
 	var rules []*schema.Rule
 
 	for _, nftable := range c.Nftables {
 		if r := nftable.Rule; r != nil {
-			match := r.Table == toFind.Table && r.Family == toFind.Family && r.Chain == toFind.Chain
-			if match {
-				if h := toFind.Handle; h != nil {
+			match := isWildcardMatch(toFind.Table, r.Table) && isWildcardMatch(toFind.Family, r.Family) &&
+				isWildcardMatch(toFind.Chain, r.Chain)
+			if match && options.Metadata != nil {
+				match = ruleHasMetadata(r, options.Metadata)
+			} else if match && options.CommentOnly {
+				match = r.Comment == toFind.Comment
+			} else if match {
+				if h := toFind.Handle; h != nil && !options.IgnoreHandle {
 					match = match && r.Handle != nil && *r.Handle == *h
 				}
-				if i := toFind.Index; i != nil {
+				if i := toFind.Index; i != nil && !options.IgnoreIndex {
 					match = match && r.Index != nil && *r.Index == *i
 				}
 				if co := toFind.Comment; co != "" {
 					match = match && r.Comment == co
 				}
 				if toFindStatements := toFind.Expr; toFindStatements != nil {
-					if match = match && len(toFindStatements) == len(r.Expr); match {
-						for i, toFindStatement := range toFindStatements {
-							equal, err := areStatementsEqual(toFindStatement, r.Expr[i])
-							match = match && err == nil && equal
-						}
-					}
-				}
-				if match {
-					rules = append(rules, r)
+					equal, err := areStatementListsEqual(toFindStatements, r.Expr, options.IgnoreMatchOrder)
+					match = match && err == nil && equal
 				}
 			}
+			if match {
+				rules = append(rules, r)
+			}
+		}
+	}
+	return rules
+}
+
+// LookupRulesInChain returns all the rules of the given table chain, in their configuration order.
+// Mutating the returned rules will result in mutating the configuration.
+func (c *Config) LookupRulesInChain(table *schema.Table, chain *schema.Chain) []*schema.Rule {
+	var rules []*schema.Rule
+	for _, nftable := range c.Nftables {
+		if r := nftable.Rule; r != nil && r.Family == table.Family && r.Table == table.Name && r.Chain == chain.Name {
+			rules = append(rules, r)
 		}
 	}
 	return rules
 }
 
+// areStatementListsEqual compares the statements one by one.
+// When ignoreMatchOrder is set, consecutive match statements are compared regardless of their order.
+// Anonymous counter values are ignored, as they reflect the traffic which matched the rule.
+// Symbolic service and protocol names are compared by their numbers (see NormalizeStatements).
+func areStatementListsEqual(statementsA, statementsB []schema.Statement, ignoreMatchOrder bool) (bool, error) {
+	if len(statementsA) != len(statementsB) {
+		return false, nil
+	}
+	statementsA, statementsB = withoutCounterValues(statementsA), withoutCounterValues(statementsB)
+	statementsA, statementsB = normalizeMatchValues(statementsA), normalizeMatchValues(statementsB)
+
+	for i := 0; i < len(statementsA); i++ {
+		if !ignoreMatchOrder || statementsA[i].Match == nil {
+			equal, err := areStatementsEqual(statementsA[i], statementsB[i])
+			if err != nil || !equal {
+				return false, err
+			}
+			continue
+		}
+
+		end := i
+		for end < len(statementsA) && statementsA[end].Match != nil {
+			end++
+		}
+		matchesA, err := sortedCanonicalStatements(statementsA[i:end])
+		if err != nil {
+			return false, err
+		}
+		matchesB, err := sortedCanonicalStatements(statementsB[i:end])
+		if err != nil {
+			return false, err
+		}
+		for j := range matchesA {
+			if matchesA[j] != matchesB[j] {
+				return false, nil
+			}
+		}
+		i = end - 1
+	}
+	return true, nil
+}
+
+func sortedCanonicalStatements(statements []schema.Statement) ([]string, error) {
+	serialized := make([]string, 0, len(statements))
+	for _, statement := range statements {
+		data, err := canonicalStatementJSON(statement)
+		if err != nil {
+			return nil, err
+		}
+		serialized = append(serialized, string(data))
+	}
+	sort.Strings(serialized)
+	return serialized, nil
+}
+
+// areStatementsEqual compares the statements in their canonical form.
+// See CanonicalizeStatements for details.
 func areStatementsEqual(statementA, statementB schema.Statement) (bool, error) {
-	statementARow, err := json.Marshal(statementA)
+	statementARow, err := canonicalStatementJSON(statementA)
 	if err != nil {
 		return false, err
 	}
-	statementBRow, err := json.Marshal(statementB)
+	statementBRow, err := canonicalStatementJSON(statementB)
 	if err != nil {
 		return false, err
 	}
@@ -34,17 +34,25 @@ type ruleAction string
 
 // Rule Actions
 const (
-	ruleADD    ruleAction = "add"
-	ruleDELETE ruleAction = "delete"
+	ruleADD     ruleAction = "add"
+	ruleDELETE  ruleAction = "delete"
+	ruleDESTROY ruleAction = "destroy"
 )
 
 func TestRule(t *testing.T) {
 	testAddRuleWithMatchAndVerdict(t)
 	testDeleteRule(t)
+	testDeleteRulesWhere(t)
 
 	testAddRuleWithRowExpression(t)
 
 	testRuleLookup(t)
+	testRuleLookupWithReadBackSet(t)
+	testRuleLookupWithOptions(t)
+	testLookupRulesInChain(t)
+	testRemoveRule(t)
+	testEnsureRule(t)
+	testAddRuleChecked(t)
 
 	testReadRuleWithNumericalExpression(t)
 }
@@ -138,6 +146,69 @@ func testDeleteRule(t *testing.T) {
 		expectedConfig := buildSerializedConfig(ruleDELETE, "", &handleID, "")
 		assert.Equal(t, string(expectedConfig), string(serializedConfig))
 	})
+
+	t.Run("Destroy rule", func(t *testing.T) {
+		handleID := 100
+		rule := nft.NewRule(table, chain, nil, &handleID, nil, "")
+
+		config := nft.NewConfig()
+		config.DestroyRule(rule)
+
+		serializedConfig, err := config.ToJSON()
+		assert.NoError(t, err)
+
+		expectedConfig := buildSerializedConfig(ruleDESTROY, "", &handleID, "")
+		assert.Equal(t, string(expectedConfig), string(serializedConfig))
+	})
+}
+
+func testDeleteRulesWhere(t *testing.T) {
+	table := nft.NewTable(tableName, nft.FamilyIP)
+	chain := nft.NewRegularChain(table, chainName)
+	otherChain := nft.NewRegularChain(table, "other-chain")
+
+	statements, _ := matchSrcIP4withReturnVerdict()
+	handles := []int{1, 2, 3}
+	current := nft.NewConfig()
+	current.AddRule(nft.NewRule(table, chain, statements, &handles[0], nil, "from 10.10.10.10"))
+	current.AddRule(nft.NewRule(table, chain, nil, &handles[1], nil, "other"))
+	current.AddRule(nft.NewRule(table, otherChain, statements, &handles[2], nil, "from 10.10.10.10"))
+	current.AddRule(nft.NewRule(table, chain, statements, nil, nil, "from 10.10.10.10 without a handle"))
+
+	mentionsAddress := func(rule *schema.Rule) bool {
+		for _, statement := range rule.Expr {
+			if m := statement.Match; m != nil && m.Right.String != nil && *m.Right.String == "10.10.10.10" {
+				return true
+			}
+		}
+		return false
+	}
+
+	t.Run("Delete rules of a chain matching a predicate", func(t *testing.T) {
+		config := nft.NewConfig()
+		assert.Equal(t, 1, config.DeleteRulesWhere(current, table, chain, mentionsAddress))
+
+		expectedConfig := nft.NewConfig()
+		expectedConfig.DeleteRule(current.Nftables[0].Rule)
+		assert.Equal(t, expectedConfig, config)
+	})
+
+	t.Run("Delete rules of all table chains matching a predicate", func(t *testing.T) {
+		config := nft.NewConfig()
+		assert.Equal(t, 2, config.DeleteRulesWhere(current, table, nil, mentionsAddress))
+
+		expectedConfig := nft.NewConfig()
+		expectedConfig.DeleteRule(current.Nftables[0].Rule)
+		expectedConfig.DeleteRule(current.Nftables[2].Rule)
+		assert.Equal(t, expectedConfig, config)
+	})
+
+	t.Run("Delete rules of a different table", func(t *testing.T) {
+		config := nft.NewConfig()
+		otherTable := nft.NewTable(tableName, nft.FamilyIP6)
+		assert.Equal(t, 0, config.DeleteRulesWhere(current, otherTable, nil, mentionsAddress))
+		assert.Empty(t, config.Nftables)
+	})
 }
 
 func buildSerializedConfig(action ruleAction, serializedStatements string, handle *int, comment string) []byte {
@@ -247,6 +318,11 @@ func testRuleLookup(t *testing.T) {
 		assert.Equal(t, ruleWithAllParams, rules[0])
 	})
 
+	t.Run("Lookup an existing rule by comment in any table and chain", func(t *testing.T) {
+		rules := config.LookupRule(&schema.Rule{Comment: "comment123"})
+		assert.Equal(t, []*schema.Rule{ruleSimple}, rules)
+	})
+
 	t.Run("Lookup a missing rule (comment not matching)", func(t *testing.T) {
 		rule := nft.NewRule(table_br, chainRegular, nil, nil, nil, "comment-missing")
 		assert.Empty(t, config.LookupRule(rule))
@@ -272,6 +348,145 @@ func testRuleLookup(t *testing.T) {
 	})
 }
 
+func testRuleLookupWithOptions(t *testing.T) {
+	config := nft.NewConfig()
+	table := nft.NewTable(tableName, nft.FamilyIP)
+	chain := nft.NewRegularChain(table, chainName)
+
+	statements, _ := matchSrcIP4withReturnVerdict()
+	dportMatch := matchDPortWithSet(`22`)[0]
+	handle := 7
+	rule := nft.NewRule(table, chain, append([]schema.Statement{dportMatch}, statements...), &handle, nil, "comment123")
+	config.AddRule(rule)
+
+	t.Run("Lookup a rule by comment only", func(t *testing.T) {
+		toFind := nft.NewRule(table, chain, []schema.Statement{{}}, nil, nil, "comment123")
+		assert.Empty(t, config.LookupRule(toFind))
+
+		rules := config.LookupRuleWithOptions(toFind, nft.LookupRuleOptions{CommentOnly: true})
+		assert.Equal(t, []*schema.Rule{rule}, rules)
+	})
+
+	t.Run("Lookup a rule ignoring the handle", func(t *testing.T) {
+		otherHandle := 8
+		toFind := nft.NewRule(table, chain, rule.Expr, &otherHandle, nil, "comment123")
+		assert.Empty(t, config.LookupRule(toFind))
+
+		rules := config.LookupRuleWithOptions(toFind, nft.LookupRuleOptions{IgnoreHandle: true})
+		assert.Equal(t, []*schema.Rule{rule}, rules)
+	})
+
+	t.Run("Lookup a rule ignoring the match statements order", func(t *testing.T) {
+		reordered := []schema.Statement{statements[0], dportMatch, statements[1]}
+		toFind := nft.NewRule(table, chain, reordered, nil, nil, "comment123")
+		assert.Empty(t, config.LookupRule(toFind))
+
+		rules := config.LookupRuleWithOptions(toFind, nft.LookupRuleOptions{IgnoreMatchOrder: true})
+		assert.Equal(t, []*schema.Rule{rule}, rules)
+	})
+
+	t.Run("Lookup a missing rule ignoring the match statements order (verdict moved)", func(t *testing.T) {
+		reordered := []schema.Statement{statements[1], dportMatch, statements[0]}
+		toFind := nft.NewRule(table, chain, reordered, nil, nil, "comment123")
+		assert.Empty(t, config.LookupRuleWithOptions(toFind, nft.LookupRuleOptions{IgnoreMatchOrder: true}))
+	})
+}
+
+func testLookupRulesInChain(t *testing.T) {
+	table := nft.NewTable(tableName, nft.FamilyIP)
+	chain := nft.NewRegularChain(table, chainName)
+	otherChain := nft.NewRegularChain(table, "other-chain")
+	first := nft.NewRule(table, chain, []schema.Statement{{Verdict: schema.Accept()}}, nil, nil, "first")
+	other := nft.NewRule(table, otherChain, []schema.Statement{{Verdict: schema.Accept()}}, nil, nil, "other")
+	second := nft.NewRule(table, chain, []schema.Statement{{Verdict: schema.Drop()}}, nil, nil, "second")
+
+	config := nft.NewConfig()
+	config.AddRule(first)
+	config.AddRule(other)
+	config.AddRule(second)
+	config.DeleteRule(first)
+
+	t.Run("Lookup the rules of a chain", func(t *testing.T) {
+		assert.Equal(t, []*schema.Rule{first, second}, config.LookupRulesInChain(table, chain))
+	})
+
+	t.Run("Lookup the rules of a chain in another family", func(t *testing.T) {
+		assert.Empty(t, config.LookupRulesInChain(nft.NewTable(tableName, nft.FamilyIP6), chain))
+	})
+}
+
+func testRemoveRule(t *testing.T) {
+	table := nft.NewTable(tableName, nft.FamilyIP)
+	chain := nft.NewRegularChain(table, chainName)
+	rule := func(comment string) *schema.Rule {
+		return nft.NewRule(table, chain, []schema.Statement{{Verdict: schema.Accept()}}, nil, nil, comment)
+	}
+	config := nft.NewConfig()
+	config.AddRule(rule("keep"))
+	config.AddRule(rule("retract"))
+	config.AddRule(rule("retract"))
+	config.DeleteRule(rule("retract"))
+
+	t.Run("Remove rules", func(t *testing.T) {
+		assert.Equal(t, 2, config.RemoveRule(rule("retract")))
+
+		expected := nft.NewConfig()
+		expected.AddRule(rule("keep"))
+		expected.DeleteRule(rule("retract"))
+		assert.Equal(t, expected, config)
+	})
+
+	t.Run("Remove a missing rule", func(t *testing.T) {
+		assert.Zero(t, config.RemoveRule(rule("retract")))
+	})
+}
+
+func testEnsureRule(t *testing.T) {
+	table := nft.NewTable(tableName, nft.FamilyIP)
+	chain := nft.NewRegularChain(table, chainName)
+	acceptRule := func(comment string) *schema.Rule {
+		return nft.NewRule(table, chain, []schema.Statement{nft.NewCounter(), {Verdict: schema.Accept()}}, nil, nil, comment)
+	}
+	config := nft.NewConfig()
+
+	t.Run("Ensure a rule", func(t *testing.T) {
+		assert.True(t, config.EnsureRule(acceptRule("accept")))
+
+		listedRule := acceptRule("accept")
+		handle := 5
+		listedRule.Handle = &handle
+		listedRule.Expr[0].Counter.Packets = 10
+		assert.False(t, config.EnsureRule(listedRule))
+		assert.Len(t, config.Nftables, 1)
+	})
+
+	t.Run("Ensure a rule with a different comment", func(t *testing.T) {
+		assert.True(t, config.EnsureRule(acceptRule("other")))
+		assert.Len(t, config.Nftables, 2)
+	})
+}
+
+func testAddRuleChecked(t *testing.T) {
+	table := nft.NewTable(tableName, nft.FamilyIP)
+	chain := nft.NewRegularChain(table, chainName)
+
+	t.Run("Add checked rule to a flushed table", func(t *testing.T) {
+		config := nft.NewConfig()
+		config.FlushTable(table)
+
+		assert.NoError(t, config.AddRuleChecked(nft.NewRule(table, chain, nil, nil, nil, "")))
+		assert.Len(t, config.Nftables, 2)
+	})
+
+	t.Run("Add checked rule to a missing table", func(t *testing.T) {
+		config := nft.NewConfig()
+
+		err := config.AddRuleChecked(nft.NewRule(table, chain, nil, nil, nil, ""))
+		assert.EqualError(t, err, `rule in chain "test-chain": table ip test-table is not declared in the config`)
+		assert.Empty(t, config.Nftables)
+	})
+}
+
 func testReadRuleWithNumericalExpression(t *testing.T) {
 	t.Run("Read rule with numerical expression", func(t *testing.T) {
 		c := nft.NewConfig()
@@ -282,3 +497,39 @@ func testReadRuleWithNumericalExpression(t *testing.T) {
 		`)))
 	})
 }
+
+func TestMatchOperators(t *testing.T) {
+	one := float64(1)
+	match := func(op string) schema.Statement {
+		return schema.Statement{Match: &schema.Match{
+			Op:    op,
+			Left:  schema.Expression{Meta: &schema.Meta{Key: schema.MetaKeyMark}},
+			Right: schema.Expression{Float64: &one},
+		}}
+	}
+
+	for _, op := range []string{
+		schema.OperEQ, schema.OperNEQ, schema.OperLS, schema.OperGR, schema.OperLSE, schema.OperGRE, schema.OperIN,
+	} {
+		op := op
+		t.Run("Serialize match with operator "+op, func(t *testing.T) {
+			serialized, err := json.Marshal(match(op))
+			assert.NoError(t, err)
+
+			var statement schema.Statement
+			assert.NoError(t, json.Unmarshal(serialized, &statement))
+			assert.Equal(t, op, statement.Match.Op)
+		})
+	}
+
+	t.Run("Serialize match with an unknown operator", func(t *testing.T) {
+		_, err := json.Marshal(match("=~"))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), `unsupported match operator "=~"`)
+	})
+
+	t.Run("Serialize match with no operator", func(t *testing.T) {
+		_, err := json.Marshal(match(""))
+		assert.Error(t, err)
+	})
+}
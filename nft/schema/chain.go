@@ -19,6 +19,11 @@
 
 package schema
 
+import (
+	"encoding/json"
+	"fmt"
+)
+
 // Chain Types
 const (
 	TypeFilter = "filter"
@@ -34,6 +39,7 @@ const (
 	HookForward     = "forward"
 	HookPostRouting = "postrouting"
 	HookIngress     = "ingress"
+	HookEgress      = "egress"
 )
 
 // Chain Policies
@@ -42,12 +48,103 @@ const (
 	PolicyDrop   = "drop"
 )
 
+// Chain Priority Names
+// The named priorities are resolved by nft according to the table family and the chain hook.
+const (
+	PrioNameRaw      = "raw"
+	PrioNameMangle   = "mangle"
+	PrioNameDstNAT   = "dstnat"
+	PrioNameFilter   = "filter"
+	PrioNameSecurity = "security"
+	PrioNameSrcNAT   = "srcnat"
+	PrioNameOut      = "out" // Bridge family only.
+)
+
 type Chain struct {
 	Family string `json:"family"`
 	Table  string `json:"table"`
-	Name   string `json:"name"`
+	Name   string `json:"name,omitempty"`
+	Handle *int   `json:"handle,omitempty"`
 	Type   string `json:"type,omitempty"`
 	Hook   string `json:"hook,omitempty"`
 	Prio   *int   `json:"prio,omitempty"`
-	Policy string `json:"policy,omitempty"`
+	// PrioName is a textual priority (e.g. `filter` or `dstnat`), encoded in place of the numeric priority.
+	// When set, it takes precedence over the numeric priority (Prio).
+	PrioName string     `json:"-"`
+	Dev      StringList `json:"dev,omitempty"`
+	Policy   string     `json:"policy,omitempty"`
+	// Comment is the chain description, up to 128 characters.
+	Comment string `json:"comment,omitempty"`
+	// NewName is the new name of a renamed chain, it is only relevant with the `rename` command.
+	NewName string `json:"newname,omitempty"`
+}
+
+// chainJSON is the chain encoding, in which the priority is either a number or a name.
+type chainJSON struct {
+	Family  string      `json:"family"`
+	Table   string      `json:"table"`
+	Name    string      `json:"name,omitempty"`
+	Handle  *int        `json:"handle,omitempty"`
+	Type    string      `json:"type,omitempty"`
+	Hook    string      `json:"hook,omitempty"`
+	Prio    interface{} `json:"prio,omitempty"`
+	Dev     StringList  `json:"dev,omitempty"`
+	Policy  string      `json:"policy,omitempty"`
+	Comment string      `json:"comment,omitempty"`
+	NewName string      `json:"newname,omitempty"`
+}
+
+func (c Chain) MarshalJSON() ([]byte, error) {
+	chain := chainJSON{
+		Family:  c.Family,
+		Table:   c.Table,
+		Name:    c.Name,
+		Handle:  c.Handle,
+		Type:    c.Type,
+		Hook:    c.Hook,
+		Dev:     c.Dev,
+		Policy:  c.Policy,
+		Comment: c.Comment,
+		NewName: c.NewName,
+	}
+	if c.PrioName != "" {
+		chain.Prio = c.PrioName
+	} else if c.Prio != nil {
+		chain.Prio = *c.Prio
+	}
+	return json.Marshal(chain)
+}
+
+func (c *Chain) UnmarshalJSON(data []byte) error {
+	var chain chainJSON
+	var prio json.RawMessage
+	chain.Prio = &prio
+	if err := json.Unmarshal(data, &chain); err != nil {
+		return err
+	}
+	*c = Chain{
+		Family:  chain.Family,
+		Table:   chain.Table,
+		Name:    chain.Name,
+		Handle:  chain.Handle,
+		Type:    chain.Type,
+		Hook:    chain.Hook,
+		Dev:     chain.Dev,
+		Policy:  chain.Policy,
+		Comment: chain.Comment,
+		NewName: chain.NewName,
+	}
+	if prio != nil {
+		var prioName string
+		if err := json.Unmarshal(prio, &prioName); err == nil {
+			c.PrioName = prioName
+			return nil
+		}
+		var prioNumber int
+		if err := json.Unmarshal(prio, &prioNumber); err != nil {
+			return fmt.Errorf("invalid chain priority: %s", prio)
+		}
+		c.Prio = &prioNumber
+	}
+	return nil
 }
@@ -0,0 +1,407 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+package schema
+
+import (
+	"encoding/json"
+)
+
+// The Clone methods return a deep copy of the object, which can be mutated without affecting the original.
+// Cloning a nil object returns nil.
+
+func (n Nftable) Clone() Nftable {
+	clone := n
+	clone.Table = n.Table.Clone()
+	clone.Chain = n.Chain.Clone()
+	clone.Rule = n.Rule.Clone()
+	clone.Set = n.Set.Clone()
+	clone.Map = n.Map.Clone()
+	clone.Flowtable = n.Flowtable.Clone()
+	clone.Counter = n.Counter.Clone()
+	clone.Limit = n.Limit.Clone()
+	clone.Quota = n.Quota.Clone()
+	clone.Synproxy = n.Synproxy.Clone()
+	clone.CtHelper = n.CtHelper.Clone()
+	clone.CtTimeout = n.CtTimeout.Clone()
+	clone.Add = n.Add.Clone()
+	clone.Create = n.Create.Clone()
+	clone.Insert = n.Insert.Clone()
+	clone.Delete = n.Delete.Clone()
+	clone.Destroy = n.Destroy.Clone()
+	clone.Flush = n.Flush.Clone()
+	clone.Rename = n.Rename.Clone()
+	clone.Reset = n.Reset.Clone()
+	if n.Metainfo != nil {
+		metainfo := *n.Metainfo
+		clone.Metainfo = &metainfo
+	}
+	clone.RowData = cloneRawMessage(n.RowData)
+	return clone
+}
+
+func (o *Objects) Clone() *Objects {
+	if o == nil {
+		return nil
+	}
+	clone := *o
+	clone.Table = o.Table.Clone()
+	clone.Chain = o.Chain.Clone()
+	clone.Rule = o.Rule.Clone()
+	clone.Set = o.Set.Clone()
+	clone.Map = o.Map.Clone()
+	clone.Flowtable = o.Flowtable.Clone()
+	clone.Counter = o.Counter.Clone()
+	clone.Limit = o.Limit.Clone()
+	clone.Quota = o.Quota.Clone()
+	clone.Synproxy = o.Synproxy.Clone()
+	clone.CtHelper = o.CtHelper.Clone()
+	clone.CtTimeout = o.CtTimeout.Clone()
+	return &clone
+}
+
+func (t *Table) Clone() *Table {
+	if t == nil {
+		return nil
+	}
+	clone := *t
+	clone.Handle = cloneInt(t.Handle)
+	clone.Flags = cloneStringList(t.Flags)
+	return &clone
+}
+
+func (c *Chain) Clone() *Chain {
+	if c == nil {
+		return nil
+	}
+	clone := *c
+	clone.Handle = cloneInt(c.Handle)
+	clone.Prio = cloneInt(c.Prio)
+	clone.Dev = cloneStringList(c.Dev)
+	return &clone
+}
+
+func (r *Rule) Clone() *Rule {
+	if r == nil {
+		return nil
+	}
+	clone := *r
+	clone.Handle = cloneInt(r.Handle)
+	clone.Index = cloneInt(r.Index)
+	if r.Expr != nil {
+		clone.Expr = make([]Statement, len(r.Expr))
+		for i, statement := range r.Expr {
+			clone.Expr[i] = statement.Clone()
+		}
+	}
+	return &clone
+}
+
+func (s *Set) Clone() *Set {
+	if s == nil {
+		return nil
+	}
+	clone := *s
+	clone.Handle = cloneInt(s.Handle)
+	clone.Type = cloneStringList(s.Type)
+	clone.Flags = cloneStringList(s.Flags)
+	clone.Elem = cloneExpressions(s.Elem)
+	return &clone
+}
+
+func (m *Map) Clone() *Map {
+	if m == nil {
+		return nil
+	}
+	clone := *m
+	clone.Handle = cloneInt(m.Handle)
+	clone.Type = cloneStringList(m.Type)
+	clone.Map = cloneStringList(m.Map)
+	clone.Flags = cloneStringList(m.Flags)
+	if m.Elem != nil {
+		clone.Elem = make([]MapElement, len(m.Elem))
+		for i, element := range m.Elem {
+			clone.Elem[i] = element.Clone()
+		}
+	}
+	return &clone
+}
+
+func (e MapElement) Clone() MapElement {
+	clone := MapElement{Key: e.Key.Clone(), Value: e.Value.Clone()}
+	if e.Verdict != nil {
+		verdict := e.Verdict.Clone()
+		clone.Verdict = &verdict
+	}
+	return clone
+}
+
+func (f *Flowtable) Clone() *Flowtable {
+	if f == nil {
+		return nil
+	}
+	clone := *f
+	clone.Handle = cloneInt(f.Handle)
+	clone.Prio = cloneInt(f.Prio)
+	clone.Dev = cloneStringList(f.Dev)
+	return &clone
+}
+
+func (c *NamedCounter) Clone() *NamedCounter {
+	if c == nil {
+		return nil
+	}
+	clone := *c
+	clone.Handle = cloneInt(c.Handle)
+	return &clone
+}
+
+func (l *NamedLimit) Clone() *NamedLimit {
+	if l == nil {
+		return nil
+	}
+	clone := *l
+	clone.Handle = cloneInt(l.Handle)
+	return &clone
+}
+
+func (q *NamedQuota) Clone() *NamedQuota {
+	if q == nil {
+		return nil
+	}
+	clone := *q
+	clone.Handle = cloneInt(q.Handle)
+	return &clone
+}
+
+func (s *NamedSynproxy) Clone() *NamedSynproxy {
+	if s == nil {
+		return nil
+	}
+	clone := *s
+	clone.Handle = cloneInt(s.Handle)
+	clone.Flags = cloneStringList(s.Flags)
+	return &clone
+}
+
+func (h *NamedCtHelper) Clone() *NamedCtHelper {
+	if h == nil {
+		return nil
+	}
+	clone := *h
+	clone.Handle = cloneInt(h.Handle)
+	return &clone
+}
+
+func (t *NamedCtTimeout) Clone() *NamedCtTimeout {
+	if t == nil {
+		return nil
+	}
+	clone := *t
+	clone.Handle = cloneInt(t.Handle)
+	if t.Policy != nil {
+		clone.Policy = make(map[string]int, len(t.Policy))
+		for state, timeout := range t.Policy {
+			clone.Policy[state] = timeout
+		}
+	}
+	return &clone
+}
+
+func (s Statement) Clone() Statement {
+	clone := s
+	clone.Verdict = s.Verdict.Clone()
+	if s.Match != nil {
+		clone.Match = &Match{Op: s.Match.Op, Left: s.Match.Left.Clone(), Right: s.Match.Right.Clone()}
+	}
+	if s.Vmap != nil {
+		clone.Vmap = &Vmap{Key: s.Vmap.Key.Clone(), Data: s.Vmap.Data.Clone()}
+	}
+	clone.Snat = s.Snat.clone()
+	clone.Dnat = s.Dnat.clone()
+	if s.Redirect != nil {
+		clone.Redirect = &Redirect{Port: cloneExpression(s.Redirect.Port), Flags: cloneStringList(s.Redirect.Flags)}
+	}
+	if s.Counter != nil {
+		counter := *s.Counter
+		clone.Counter = &counter
+	}
+	if s.Limit != nil {
+		limit := *s.Limit
+		clone.Limit = &limit
+	}
+	if s.Quota != nil {
+		quota := *s.Quota
+		clone.Quota = &quota
+	}
+	if s.Reject != nil {
+		reject := *s.Reject
+		reject.Expr = cloneExpression(s.Reject.Expr)
+		clone.Reject = &reject
+	}
+	if s.Log != nil {
+		log := *s.Log
+		log.Group = cloneInt(s.Log.Group)
+		log.Flags = cloneStringList(s.Log.Flags)
+		clone.Log = &log
+	}
+	if s.Mangle != nil {
+		clone.Mangle = &Mangle{Key: s.Mangle.Key.Clone(), Value: s.Mangle.Value.Clone()}
+	}
+	if s.Queue != nil {
+		queue := *s.Queue
+		queue.Num = cloneExpression(s.Queue.Num)
+		queue.Flags = cloneStringList(s.Queue.Flags)
+		clone.Queue = &queue
+	}
+	if s.Fwd != nil {
+		fwd := *s.Fwd
+		fwd.Dev = s.Fwd.Dev.Clone()
+		fwd.Addr = cloneExpression(s.Fwd.Addr)
+		clone.Fwd = &fwd
+	}
+	if s.Synproxy != nil {
+		synproxy := *s.Synproxy
+		synproxy.Flags = cloneStringList(s.Synproxy.Flags)
+		clone.Synproxy = &synproxy
+	}
+	if s.Xt != nil {
+		xt := *s.Xt
+		xt.Info = cloneRawMessage(s.Xt.Info)
+		clone.Xt = &xt
+	}
+	if s.Last != nil {
+		clone.Last = &Last{Used: cloneInt(s.Last.Used)}
+	}
+	return clone
+}
+
+func (n *Nat) clone() *Nat {
+	if n == nil {
+		return nil
+	}
+	clone := *n
+	clone.Addr = cloneExpression(n.Addr)
+	clone.Port = cloneExpression(n.Port)
+	clone.Flags = cloneStringList(n.Flags)
+	return &clone
+}
+
+func (v Verdict) Clone() Verdict {
+	clone := v
+	if v.Jump != nil {
+		clone.Jump = &ToTarget{Target: v.Jump.Target}
+	}
+	if v.Goto != nil {
+		clone.Goto = &ToTarget{Target: v.Goto.Target}
+	}
+	return clone
+}
+
+func (e Expression) Clone() Expression {
+	clone := e
+	if e.String != nil {
+		s := *e.String
+		clone.String = &s
+	}
+	if e.Bool != nil {
+		b := *e.Bool
+		clone.Bool = &b
+	}
+	if e.Float64 != nil {
+		f := *e.Float64
+		clone.Float64 = &f
+	}
+	if e.Payload != nil {
+		payload := *e.Payload
+		clone.Payload = &payload
+	}
+	if e.Meta != nil {
+		meta := *e.Meta
+		clone.Meta = &meta
+	}
+	if e.Ct != nil {
+		ct := *e.Ct
+		clone.Ct = &ct
+	}
+	if e.Rt != nil {
+		rt := *e.Rt
+		clone.Rt = &rt
+	}
+	if e.TCPOption != nil {
+		option := *e.TCPOption
+		clone.TCPOption = &option
+	}
+	if e.Numgen != nil {
+		numgen := *e.Numgen
+		clone.Numgen = &numgen
+	}
+	if e.Range != nil {
+		clone.Range = &[2]Expression{e.Range[0].Clone(), e.Range[1].Clone()}
+	}
+	clone.Set = cloneExpressions(e.Set)
+	clone.Concat = cloneExpressions(e.Concat)
+	clone.And = cloneExpressions(e.And)
+	clone.Or = cloneExpressions(e.Or)
+	clone.Xor = cloneExpressions(e.Xor)
+	clone.LShift = cloneExpressions(e.LShift)
+	clone.RShift = cloneExpressions(e.RShift)
+	clone.RowData = cloneRawMessage(e.RowData)
+	return clone
+}
+
+func cloneExpression(e *Expression) *Expression {
+	if e == nil {
+		return nil
+	}
+	clone := e.Clone()
+	return &clone
+}
+
+func cloneExpressions(expressions []Expression) []Expression {
+	if expressions == nil {
+		return nil
+	}
+	clone := make([]Expression, len(expressions))
+	for i, expression := range expressions {
+		clone[i] = expression.Clone()
+	}
+	return clone
+}
+
+func cloneInt(i *int) *int {
+	if i == nil {
+		return nil
+	}
+	clone := *i
+	return &clone
+}
+
+func cloneStringList(l StringList) StringList {
+	if l == nil {
+		return nil
+	}
+	return append(StringList{}, l...)
+}
+
+func cloneRawMessage(m json.RawMessage) json.RawMessage {
+	if m == nil {
+		return nil
+	}
+	return append(json.RawMessage{}, m...)
+}
@@ -0,0 +1,71 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package schema
+
+// Conntrack Helper Protocols
+const (
+	CtHelperProtocolTCP = "tcp"
+	CtHelperProtocolUDP = "udp"
+)
+
+// NamedCtHelper is a conntrack helper object (`ct helper`), which rules may assign to connections by name.
+// The type is the name of the kernel helper (e.g. `ftp` or `sip`), the protocol is its layer 4 protocol.
+// The layer 3 protocol is optional, it defaults to the table family.
+type NamedCtHelper struct {
+	Family   string `json:"family"`
+	Table    string `json:"table"`
+	Name     string `json:"name"`
+	Handle   *int   `json:"handle,omitempty"`
+	Type     string `json:"type"`
+	Protocol string `json:"protocol,omitempty"`
+	L3Proto  string `json:"l3proto,omitempty"`
+}
+
+// NamedCtTimeout is a conntrack timeout policy object (`ct timeout`), which rules may assign to connections by name.
+// The policy maps the connection states of the protocol (e.g. `established`) to their timeout in seconds.
+// The layer 3 protocol is optional, it defaults to the table family.
+type NamedCtTimeout struct {
+	Family   string         `json:"family"`
+	Table    string         `json:"table"`
+	Name     string         `json:"name"`
+	Handle   *int           `json:"handle,omitempty"`
+	Protocol string         `json:"protocol"`
+	L3Proto  string         `json:"l3proto,omitempty"`
+	Policy   map[string]int `json:"policy,omitempty"`
+}
+
+// Conntrack Timeout Policy States
+const (
+	// TCP
+	CtTimeoutStateSynSent     = "syn_sent"
+	CtTimeoutStateSynRecv     = "syn_recv"
+	CtTimeoutStateEstablished = "established"
+	CtTimeoutStateFinWait     = "fin_wait"
+	CtTimeoutStateCloseWait   = "close_wait"
+	CtTimeoutStateLastAck     = "last_ack"
+	CtTimeoutStateTimeWait    = "time_wait"
+	CtTimeoutStateClose       = "close"
+	CtTimeoutStateRetrans     = "retrans"
+	CtTimeoutStateUnack       = "unacknowledged"
+
+	// UDP
+	CtTimeoutStateUnreplied = "unreplied"
+	CtTimeoutStateReplied   = "replied"
+)
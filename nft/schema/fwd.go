@@ -0,0 +1,28 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package schema
+
+// Fwd is the statement forwarding the packet to a device, supported in the netdev family.
+// The address and its family are optional, forwarding to the neighbour of the given address on the device.
+type Fwd struct {
+	Dev    Expression  `json:"dev"`
+	Family string      `json:"family,omitempty"`
+	Addr   *Expression `json:"addr,omitempty"`
+}
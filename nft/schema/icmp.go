@@ -0,0 +1,61 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package schema
+
+// ICMP Payload Expressions
+const (
+	PayloadProtocolICMP   = "icmp"
+	PayloadProtocolICMPv6 = "icmpv6"
+	PayloadFieldICMPType  = "type"
+	PayloadFieldICMPCode  = "code"
+)
+
+// ICMP (IPv4) Types
+const (
+	ICMPTypeEchoReply              = "echo-reply"
+	ICMPTypeDestinationUnreachable = "destination-unreachable"
+	ICMPTypeSourceQuench           = "source-quench"
+	ICMPTypeRedirect               = "redirect"
+	ICMPTypeEchoRequest            = "echo-request"
+	ICMPTypeRouterAdvertisement    = "router-advertisement"
+	ICMPTypeRouterSolicitation     = "router-solicitation"
+	ICMPTypeTimeExceeded           = "time-exceeded"
+	ICMPTypeParameterProblem       = "parameter-problem"
+	ICMPTypeTimestampRequest       = "timestamp-request"
+	ICMPTypeTimestampReply         = "timestamp-reply"
+)
+
+// ICMPv6 Types
+const (
+	ICMPv6TypeDestinationUnreachable = "destination-unreachable"
+	ICMPv6TypePacketTooBig           = "packet-too-big"
+	ICMPv6TypeTimeExceeded           = "time-exceeded"
+	ICMPv6TypeParameterProblem       = "parameter-problem"
+	ICMPv6TypeEchoRequest            = "echo-request"
+	ICMPv6TypeEchoReply              = "echo-reply"
+	ICMPv6TypeMLDListenerQuery       = "mld-listener-query"
+	ICMPv6TypeMLDListenerReport      = "mld-listener-report"
+	ICMPv6TypeMLDListenerDone        = "mld-listener-done"
+	ICMPv6TypeNDRouterSolicit        = "nd-router-solicit"
+	ICMPv6TypeNDRouterAdvert         = "nd-router-advert"
+	ICMPv6TypeNDNeighborSolicit      = "nd-neighbor-solicit"
+	ICMPv6TypeNDNeighborAdvert       = "nd-neighbor-advert"
+	ICMPv6TypeNDRedirect             = "nd-redirect"
+)
@@ -0,0 +1,55 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package schema
+
+// Log Levels
+const (
+	LogLevelEmerg  = "emerg"
+	LogLevelAlert  = "alert"
+	LogLevelCrit   = "crit"
+	LogLevelErr    = "err"
+	LogLevelWarn   = "warn"
+	LogLevelNotice = "notice"
+	LogLevelInfo   = "info"
+	LogLevelDebug  = "debug"
+	LogLevelAudit  = "audit"
+)
+
+// Log Flags
+const (
+	LogFlagTCPSequence = "tcp sequence"
+	LogFlagTCPOptions  = "tcp options"
+	LogFlagIPOptions   = "ip options"
+	LogFlagSkuid       = "skuid"
+	LogFlagEther       = "ether"
+	LogFlagAll         = "all"
+)
+
+// Log is the log statement.
+// The group, when set, selects logging through nfnetlink_log instead of the kernel log.
+// All fields are optional, a log without any fields is encoded as `{"log":null}`.
+type Log struct {
+	Prefix         string     `json:"prefix,omitempty"`
+	Group          *int       `json:"group,omitempty"`
+	Snaplen        int        `json:"snaplen,omitempty"`
+	QueueThreshold int        `json:"queue-threshold,omitempty"`
+	Level          string     `json:"level,omitempty"`
+	Flags          StringList `json:"flags,omitempty"`
+}
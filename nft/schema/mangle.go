@@ -0,0 +1,28 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package schema
+
+// Mangle is the statement changing the value of a packet field or meta data,
+// e.g. `meta mark set 42`, `ct mark set 42` or `ip dscp set 46`.
+// The key is the field to set (e.g. a Meta, Ct or Payload expression) and the value is its new value.
+type Mangle struct {
+	Key   Expression `json:"key"`
+	Value Expression `json:"value"`
+}
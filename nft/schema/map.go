@@ -0,0 +1,109 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Map Data Types
+// In addition to the set key types, maps accept verdicts as data.
+const (
+	MapDataTypeVerdict = "verdict"
+)
+
+type Map struct {
+	Family string     `json:"family"`
+	Table  string     `json:"table"`
+	Name   string     `json:"name"`
+	Handle *int       `json:"handle,omitempty"`
+	Type   StringList `json:"type,omitempty"`
+	Map    StringList `json:"map,omitempty"`
+	Flags  StringList `json:"flags,omitempty"`
+	// Comment is the map description, up to 128 characters.
+	Comment string       `json:"comment,omitempty"`
+	Elem    []MapElement `json:"elem,omitempty"`
+}
+
+// MapElement is a map key with its data.
+// The data is either a value expression or a verdict (for verdict maps).
+// It is encoded as a pair: `[key, data]`.
+type MapElement struct {
+	Key     Expression
+	Value   Expression
+	Verdict *Verdict
+}
+
+// Vmap is the verdict map statement, applying the verdict which is mapped to the key.
+// The data is either a named map reference (e.g. `"@name"`) or an anonymous map.
+type Vmap struct {
+	Key  Expression `json:"key"`
+	Data Expression `json:"data"`
+}
+
+func (e MapElement) MarshalJSON() ([]byte, error) {
+	var data interface{} = e.Value
+	if e.Verdict != nil {
+		data = Statement{Verdict: *e.Verdict}
+	}
+	return json.Marshal([]interface{}{e.Key, data})
+}
+
+func (e *MapElement) UnmarshalJSON(data []byte) error {
+	var pair []json.RawMessage
+	if err := json.Unmarshal(data, &pair); err != nil {
+		return err
+	}
+	if len(pair) != 2 {
+		return fmt.Errorf("map element is expected to be a key and data pair: %s", data)
+	}
+
+	element := MapElement{}
+	if err := json.Unmarshal(pair[0], &element.Key); err != nil {
+		return err
+	}
+
+	if isVerdictData(pair[1]) {
+		var statement Statement
+		if err := json.Unmarshal(pair[1], &statement); err != nil {
+			return err
+		}
+		element.Verdict = &statement.Verdict
+	} else if err := json.Unmarshal(pair[1], &element.Value); err != nil {
+		return err
+	}
+
+	*e = element
+	return nil
+}
+
+func isVerdictData(data []byte) bool {
+	var dynamicStructure map[string]json.RawMessage
+	if err := json.Unmarshal(data, &dynamicStructure); err != nil {
+		return false
+	}
+	for _, key := range []string{VerdictAccept, VerdictContinue, VerdictDrop, VerdictReturn, "jump", "goto"} {
+		if _, exists := dynamicStructure[key]; exists {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package schema
+
+// Nat is the source (snat) or destination (dnat) address translation statement.
+// The family is required for tables of the inet family, specifying the address family (ip or ip6).
+type Nat struct {
+	Addr   *Expression `json:"addr,omitempty"`
+	Family string      `json:"family,omitempty"`
+	Port   *Expression `json:"port,omitempty"`
+	Flags  StringList  `json:"flags,omitempty"`
+}
+
+// Redirect is the redirect statement, translating the destination to the local host.
+// The port is optional, a redirect without any fields is encoded as `{"redirect":null}`.
+type Redirect struct {
+	Port  *Expression `json:"port,omitempty"`
+	Flags StringList  `json:"flags,omitempty"`
+}
+
+// Nat Flags
+const (
+	NatFlagRandom      = "random"
+	NatFlagFullyRandom = "fully-random"
+	NatFlagPersistent  = "persistent"
+)
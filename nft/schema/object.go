@@ -0,0 +1,158 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package schema
+
+import (
+	"encoding/json"
+)
+
+// NamedCounter is a stateful counter object, which rules may reference by name.
+type NamedCounter struct {
+	Family  string `json:"family"`
+	Table   string `json:"table"`
+	Name    string `json:"name"`
+	Handle  *int   `json:"handle,omitempty"`
+	Packets int    `json:"packets"`
+	Bytes   int    `json:"bytes"`
+}
+
+// Limit Units
+const (
+	LimitUnitPackets = "packets"
+	LimitUnitBytes   = "bytes"
+	LimitUnitKBytes  = "kbytes"
+	LimitUnitMBytes  = "mbytes"
+)
+
+// Limit Time Units
+const (
+	LimitPerSecond = "second"
+	LimitPerMinute = "minute"
+	LimitPerHour   = "hour"
+	LimitPerDay    = "day"
+	LimitPerWeek   = "week"
+)
+
+// NamedLimit is a stateful limit object, which rules may reference by name.
+// When inverted (Inv), the limit matches the traffic over the rate, instead of the traffic under it.
+type NamedLimit struct {
+	Family    string `json:"family"`
+	Table     string `json:"table"`
+	Name      string `json:"name"`
+	Handle    *int   `json:"handle,omitempty"`
+	Rate      int    `json:"rate"`
+	Per       string `json:"per,omitempty"`
+	RateUnit  string `json:"rate_unit,omitempty"`
+	Burst     int    `json:"burst,omitempty"`
+	BurstUnit string `json:"burst_unit,omitempty"`
+	Inv       bool   `json:"inv,omitempty"`
+}
+
+// Counter is the counter statement.
+// An anonymous counter holds the packets and bytes which matched the rule,
+// while a named counter references a counter object (see NamedCounter) by its name.
+type Counter struct {
+	Name    string
+	Packets int
+	Bytes   int
+}
+
+type anonymousCounter struct {
+	Packets int `json:"packets"`
+	Bytes   int `json:"bytes"`
+}
+
+func (c Counter) MarshalJSON() ([]byte, error) {
+	if c.Name != "" {
+		return json.Marshal(c.Name)
+	}
+	return json.Marshal(anonymousCounter{Packets: c.Packets, Bytes: c.Bytes})
+}
+
+func (c *Counter) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*c = Counter{Name: name}
+		return nil
+	}
+	var counter anonymousCounter
+	if err := json.Unmarshal(data, &counter); err != nil {
+		return err
+	}
+	*c = Counter{Packets: counter.Packets, Bytes: counter.Bytes}
+	return nil
+}
+
+// Limit is the limit statement.
+// An anonymous limit matches the traffic under its rate (or over it, when inverted),
+// while a named limit references a limit object (see NamedLimit) by its name.
+type Limit struct {
+	Name      string
+	Rate      int
+	Per       string
+	RateUnit  string
+	Burst     int
+	BurstUnit string
+	Inv       bool
+}
+
+type anonymousLimit struct {
+	Rate      int    `json:"rate"`
+	Per       string `json:"per,omitempty"`
+	RateUnit  string `json:"rate_unit,omitempty"`
+	Burst     int    `json:"burst,omitempty"`
+	BurstUnit string `json:"burst_unit,omitempty"`
+	Inv       bool   `json:"inv,omitempty"`
+}
+
+func (l Limit) MarshalJSON() ([]byte, error) {
+	if l.Name != "" {
+		return json.Marshal(l.Name)
+	}
+	return json.Marshal(anonymousLimit{
+		Rate:      l.Rate,
+		Per:       l.Per,
+		RateUnit:  l.RateUnit,
+		Burst:     l.Burst,
+		BurstUnit: l.BurstUnit,
+		Inv:       l.Inv,
+	})
+}
+
+func (l *Limit) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*l = Limit{Name: name}
+		return nil
+	}
+	var limit anonymousLimit
+	if err := json.Unmarshal(data, &limit); err != nil {
+		return err
+	}
+	*l = Limit{
+		Rate:      limit.Rate,
+		Per:       limit.Per,
+		RateUnit:  limit.RateUnit,
+		Burst:     limit.Burst,
+		BurstUnit: limit.BurstUnit,
+		Inv:       limit.Inv,
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package schema
+
+// Queue is the statement passing the packet to a userspace queue.
+// The queue number is an expression, commonly a number, a range of queues (fanout)
+// or a number generator spreading the packets across queues (e.g. `queue to numgen inc mod 4`).
+type Queue struct {
+	Num   *Expression `json:"num,omitempty"`
+	Flags StringList  `json:"flags,omitempty"`
+}
+
+// Queue Flags
+const (
+	QueueFlagBypass = "bypass"
+	QueueFlagFanout = "fanout"
+)
@@ -0,0 +1,90 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package schema
+
+import (
+	"encoding/json"
+)
+
+// NamedQuota is a stateful quota object, which rules may reference by name.
+// The quota matches the traffic until the used bytes reach the quota bytes,
+// when inverted (Inv), it matches the traffic over the quota instead.
+type NamedQuota struct {
+	Family string `json:"family"`
+	Table  string `json:"table"`
+	Name   string `json:"name"`
+	Handle *int   `json:"handle,omitempty"`
+	Bytes  int    `json:"bytes"`
+	Used   int    `json:"used,omitempty"`
+	Inv    bool   `json:"inv,omitempty"`
+}
+
+// Quota is the quota statement.
+// An anonymous quota holds the quota value and the used value with their units,
+// while a named quota references a quota object (see NamedQuota) by its name.
+type Quota struct {
+	Name     string
+	Val      int
+	ValUnit  string
+	Used     int
+	UsedUnit string
+	Inv      bool
+}
+
+type anonymousQuota struct {
+	Val      int    `json:"val"`
+	ValUnit  string `json:"val_unit,omitempty"`
+	Used     int    `json:"used,omitempty"`
+	UsedUnit string `json:"used_unit,omitempty"`
+	Inv      bool   `json:"inv,omitempty"`
+}
+
+func (q Quota) MarshalJSON() ([]byte, error) {
+	if q.Name != "" {
+		return json.Marshal(q.Name)
+	}
+	return json.Marshal(anonymousQuota{
+		Val:      q.Val,
+		ValUnit:  q.ValUnit,
+		Used:     q.Used,
+		UsedUnit: q.UsedUnit,
+		Inv:      q.Inv,
+	})
+}
+
+func (q *Quota) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*q = Quota{Name: name}
+		return nil
+	}
+	var quota anonymousQuota
+	if err := json.Unmarshal(data, &quota); err != nil {
+		return err
+	}
+	*q = Quota{
+		Val:      quota.Val,
+		ValUnit:  quota.ValUnit,
+		Used:     quota.Used,
+		UsedUnit: quota.UsedUnit,
+		Inv:      quota.Inv,
+	}
+	return nil
+}
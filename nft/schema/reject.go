@@ -0,0 +1,64 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package schema
+
+// Reject Types
+const (
+	RejectTypeTCPReset = "tcp reset"
+	RejectTypeICMP     = "icmp"
+	RejectTypeICMPv6   = "icmpv6"
+	RejectTypeICMPx    = "icmpx"
+)
+
+// ICMP (IPv4) Reject Codes
+const (
+	ICMPCodeNetUnreachable  = "net-unreachable"
+	ICMPCodeHostUnreachable = "host-unreachable"
+	ICMPCodeProtUnreachable = "prot-unreachable"
+	ICMPCodePortUnreachable = "port-unreachable"
+	ICMPCodeNetProhibited   = "net-prohibited"
+	ICMPCodeHostProhibited  = "host-prohibited"
+	ICMPCodeAdminProhibited = "admin-prohibited"
+)
+
+// ICMPv6 Reject Codes
+const (
+	ICMPv6CodeNoRoute         = "no-route"
+	ICMPv6CodeAdminProhibited = "admin-prohibited"
+	ICMPv6CodeAddrUnreachable = "addr-unreachable"
+	ICMPv6CodePortUnreachable = "port-unreachable"
+	ICMPv6CodePolicyFail      = "policy-fail"
+	ICMPv6CodeRejectRoute     = "reject-route"
+)
+
+// ICMPx (inet) Reject Codes
+const (
+	ICMPxCodeNoRoute         = "no-route"
+	ICMPxCodePortUnreachable = "port-unreachable"
+	ICMPxCodeHostUnreachable = "host-unreachable"
+	ICMPxCodeAdminProhibited = "admin-prohibited"
+)
+
+// Reject is the reject statement.
+// The type and code (expr) are optional, a reject without any fields is encoded as `{"reject":null}`.
+type Reject struct {
+	Type string      `json:"type,omitempty"`
+	Expr *Expression `json:"expr,omitempty"`
+}
@@ -22,6 +22,7 @@ package schema
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 )
 
 type Rule struct {
@@ -36,6 +37,26 @@ type Rule struct {
 
 type Statement struct {
 	Match *Match `json:"match,omitempty"`
+	Vmap  *Vmap  `json:"vmap,omitempty"`
+	Snat  *Nat   `json:"snat,omitempty"`
+	Dnat  *Nat   `json:"dnat,omitempty"`
+
+	Redirect *Redirect `json:"redirect,omitempty"`
+	Counter  *Counter  `json:"counter,omitempty"`
+	Limit    *Limit    `json:"limit,omitempty"`
+	Quota    *Quota    `json:"quota,omitempty"`
+	Reject   *Reject   `json:"reject,omitempty"`
+	Log      *Log      `json:"log,omitempty"`
+	Mangle   *Mangle   `json:"mangle,omitempty"`
+	Queue    *Queue    `json:"queue,omitempty"`
+	Fwd      *Fwd      `json:"fwd,omitempty"`
+	Synproxy *Synproxy `json:"synproxy,omitempty"`
+	Xt       *Xt       `json:"xt,omitempty"`
+	Last     *Last     `json:"last,omitempty"`
+	// CtHelper assigns the named conntrack helper (see NamedCtHelper) to the connection.
+	CtHelper string `json:"ct helper,omitempty"`
+	// CtTimeout assigns the named conntrack timeout policy (see NamedCtTimeout) to the connection.
+	CtTimeout string `json:"ct timeout,omitempty"`
 	Verdict
 }
 
@@ -66,7 +87,29 @@ type Expression struct {
 	String  *string  `json:"-"`
 	Bool    *bool    `json:"-"`
 	Float64 *float64 `json:"-"`
+	// SetRef is a reference to a named set or map (e.g. `@blocked`), holding its name without the `@` prefix.
+	// It is decoded only in the operand positions of set references, the right side of matches and
+	// the verdict map data, other `@` prefixed strings (e.g. a log prefix) are decoded as strings.
+	SetRef  string   `json:"-"`
 	Payload *Payload `json:"payload,omitempty"`
+	Meta    *Meta    `json:"meta,omitempty"`
+	Ct      *Ct      `json:"ct,omitempty"`
+	Rt      *Rt      `json:"rt,omitempty"`
+	// TCPOption is a TCP header option, used to match or mangle the option fields (e.g. `tcp option maxseg size`).
+	TCPOption *TCPOption `json:"tcp option,omitempty"`
+	Numgen    *Numgen    `json:"numgen,omitempty"`
+	// Set is an anonymous set, commonly used as the right operand of a match (e.g. `tcp dport { 22, 80 }`).
+	Set []Expression `json:"set,omitempty"`
+	// Range is an inclusive range of values (e.g. `1024-65535`), formed by its lower and upper bounds.
+	Range *[2]Expression `json:"range,omitempty"`
+	// Concat is a concatenation of expressions, forming a compound key (e.g. `ip saddr . tcp dport`).
+	Concat []Expression `json:"concat,omitempty"`
+	// Binary operations, each expects two expressions (left and right).
+	And    []Expression `json:"&,omitempty"`
+	Or     []Expression `json:"|,omitempty"`
+	Xor    []Expression `json:"^,omitempty"`
+	LShift []Expression `json:"<<,omitempty"`
+	RShift []Expression `json:">>,omitempty"`
 	// RowData accepts arbitrary data which cannot be composed from the existing schema.
 	// Use `json.RawMessage()` or `[]byte()` for the value.
 	// Example:
@@ -74,9 +117,48 @@ type Expression struct {
 	RowData json.RawMessage `json:"-"`
 }
 
+// Payload is the packet payload expression.
+// It refers either to a named protocol field (e.g. `tcp dport`),
+// or to raw header bits when the base is set (e.g. `@th,16,16`), the offset and length are in bits.
 type Payload struct {
-	Protocol string `json:"protocol"`
-	Field    string `json:"field"`
+	Protocol string `json:"protocol,omitempty"`
+	Field    string `json:"field,omitempty"`
+
+	Base   string `json:"base,omitempty"`
+	Offset int    `json:"offset,omitempty"`
+	Len    int    `json:"len,omitempty"`
+}
+
+type Meta struct {
+	Key string `json:"key"`
+}
+
+// Ct is the conntrack expression, e.g. `ct state` or `ct mark`.
+// The direction is optional, used by keys which differ per direction (e.g. `ct original saddr`).
+type Ct struct {
+	Key    string `json:"key"`
+	Family string `json:"family,omitempty"`
+	Dir    string `json:"dir,omitempty"`
+}
+
+// Rt is the routing information expression, e.g. `rt mtu` or `rt ip nexthop`.
+// The family is required by the nexthop key in tables of the inet family.
+type Rt struct {
+	Key    string `json:"key"`
+	Family string `json:"family,omitempty"`
+}
+
+// TCPOption is the TCP option expression, e.g. `tcp option sack-perm` or `tcp option maxseg size`.
+// With no field, the expression tests the presence of the option (compared to a boolean).
+type TCPOption struct {
+	Name  string `json:"name"`
+	Field string `json:"field,omitempty"`
+}
+
+type Numgen struct {
+	Mode   string `json:"mode"`
+	Mod    int    `json:"mod"`
+	Offset int    `json:"offset,omitempty"`
 }
 
 // Verdict Operations
@@ -112,6 +194,13 @@ const (
 	PayloadFieldEtherSAddr = "saddr"
 	PayloadFieldEtherType  = "type"
 
+	// VLAN (802.1Q)
+	PayloadProtocolVLAN  = "vlan"
+	PayloadFieldVLANID   = "id"
+	PayloadFieldVLANPcp  = "pcp"
+	PayloadFieldVLANCfi  = "cfi"
+	PayloadFieldVLANType = "type"
+
 	// IP (common)
 	PayloadFieldIPVer   = "version"
 	PayloadFieldIPDscp  = "dscp"
@@ -134,8 +223,139 @@ const (
 	PayloadFieldIP6FlowLabel = "flowlabel"
 	PayloadFieldIP6NextHdr   = "nexthdr"
 	PayloadFieldIP6HopLimit  = "hoplimit"
+
+	// ARP
+	PayloadProtocolARP       = "arp"
+	PayloadFieldARPOperation = "operation"
+
+	// TCP
+	PayloadProtocolTCP   = "tcp"
+	PayloadFieldTCPSPort = "sport"
+	PayloadFieldTCPDPort = "dport"
+	PayloadFieldTCPFlags = "flags"
+
+	// UDP
+	PayloadProtocolUDP   = "udp"
+	PayloadFieldUDPSPort = "sport"
+	PayloadFieldUDPDPort = "dport"
+
+	// Transport header (any L4 protocol)
+	PayloadProtocolTH   = "th"
+	PayloadFieldTHSPort = "sport"
+	PayloadFieldTHDPort = "dport"
+
+	// Raw payload bases
+	PayloadBaseLL = "ll" // Link layer header
+	PayloadBaseNH = "nh" // Network header
+	PayloadBaseTH = "th" // Transport header
 )
 
+// Meta Expressions
+const (
+	MetaKey        = "meta"
+	MetaKeyL4Proto = "l4proto"
+	MetaKeyIIFName = "iifname"
+	MetaKeyOIFName = "oifname"
+	MetaKeyPktType = "pkttype"
+	MetaKeyMark    = "mark"
+	MetaKeyNfProto = "nfproto"
+
+	MetaKeyIIF      = "iif"
+	MetaKeyOIF      = "oif"
+	MetaKeyIIFGroup = "iifgroup"
+	MetaKeyOIFGroup = "oifgroup"
+)
+
+// Meta Netfilter Protocols (nfproto)
+const (
+	NfProtoIPv4 = "ipv4"
+	NfProtoIPv6 = "ipv6"
+)
+
+// Conntrack Expressions
+const (
+	CtKey      = "ct"
+	CtKeyState = "state"
+	CtKeyMark  = "mark"
+
+	CtDirOriginal = "original"
+	CtDirReply    = "reply"
+)
+
+// Routing Expressions
+const (
+	RtKeyClassid = "classid"
+	RtKeyNexthop = "nexthop"
+	RtKeyMtu     = "mtu"
+	RtKeyIpsec   = "ipsec"
+)
+
+// TCP Option Expressions
+const (
+	TCPOptionKey = "tcp option"
+
+	TCPOptionEOL       = "eol"
+	TCPOptionNOP       = "nop"
+	TCPOptionMaxSeg    = "maxseg"
+	TCPOptionWindow    = "window"
+	TCPOptionSackPerm  = "sack-perm"
+	TCPOptionSack      = "sack"
+	TCPOptionTimestamp = "timestamp"
+
+	TCPOptionFieldKind   = "kind"
+	TCPOptionFieldLength = "length"
+	TCPOptionFieldSize   = "size"
+	TCPOptionFieldCount  = "count"
+	TCPOptionFieldLeft   = "left"
+	TCPOptionFieldRight  = "right"
+	TCPOptionFieldTSVal  = "tsval"
+	TCPOptionFieldTSEcr  = "tsecr"
+)
+
+// Number Generator Modes
+const (
+	NumgenModeRandom = "random"
+	NumgenModeInc    = "inc"
+)
+
+// Meta Packet Types
+const (
+	PktTypeHost      = "host"
+	PktTypeBroadcast = "broadcast"
+	PktTypeMulticast = "multicast"
+	PktTypeOther     = "other"
+)
+
+// SetRefPrefix prefixes the name of a referenced set or map (e.g. `@blocked`).
+const SetRefPrefix = "@"
+
+const (
+	redirectKey = "redirect"
+	counterKey  = "counter"
+	rejectKey   = "reject"
+	logKey      = "log"
+	lastKey     = "last"
+)
+
+var matchOperators = map[string]bool{
+	OperAND: true, OperOR: true, OperXOR: true, OperLSH: true, OperRSH: true,
+	OperEQ: true, OperNEQ: true, OperLS: true, OperGR: true, OperLSE: true, OperGRE: true,
+	OperIN: true,
+}
+
+// IsMatchOperator reports if the operator is one of the known match operators.
+func IsMatchOperator(op string) bool {
+	return matchOperators[op]
+}
+
+func (m Match) MarshalJSON() ([]byte, error) {
+	if !IsMatchOperator(m.Op) {
+		return nil, fmt.Errorf("unsupported match operator %q", m.Op)
+	}
+	type _Match Match
+	return json.Marshal(_Match(m))
+}
+
 func (s Statement) MarshalJSON() ([]byte, error) {
 	type _Statement Statement
 	statement := _Statement(s)
@@ -160,6 +380,19 @@ func (s Statement) MarshalJSON() ([]byte, error) {
 	case s.Return:
 		dynamicStructure[VerdictReturn] = nil
 	}
+	if r := s.Redirect; r != nil && r.Port == nil && r.Flags == nil {
+		dynamicStructure[redirectKey] = nil
+	}
+	if r := s.Reject; r != nil && r.Type == "" && r.Expr == nil {
+		dynamicStructure[rejectKey] = nil
+	}
+	if l := s.Log; l != nil && l.Prefix == "" && l.Group == nil && l.Snaplen == 0 && l.QueueThreshold == 0 &&
+		l.Level == "" && l.Flags == nil {
+		dynamicStructure[logKey] = nil
+	}
+	if l := s.Last; l != nil && l.Used == nil {
+		dynamicStructure[lastKey] = nil
+	}
 
 	data, err = json.Marshal(dynamicStructure)
 	if err != nil {
@@ -185,16 +418,61 @@ func (s *Statement) UnmarshalJSON(data []byte) error {
 	_, s.Continue = dynamicStructure[VerdictContinue]
 	_, s.Drop = dynamicStructure[VerdictDrop]
 	_, s.Return = dynamicStructure[VerdictReturn]
+	if _, exists := dynamicStructure[redirectKey]; exists && s.Redirect == nil {
+		s.Redirect = &Redirect{}
+	}
+	if _, exists := dynamicStructure[counterKey]; exists && s.Counter == nil {
+		s.Counter = &Counter{}
+	}
+	if _, exists := dynamicStructure[rejectKey]; exists && s.Reject == nil {
+		s.Reject = &Reject{}
+	}
+	if _, exists := dynamicStructure[logKey]; exists && s.Log == nil {
+		s.Log = &Log{}
+	}
+	if _, exists := dynamicStructure[lastKey]; exists && s.Last == nil {
+		s.Last = &Last{}
+	}
+	if s.Match != nil {
+		s.Match.Right.decodeSetRef()
+	}
+	if s.Vmap != nil {
+		s.Vmap.Data.decodeSetRef()
+	}
 
 	return nil
 }
 
+// decodeSetRef converts a decoded `@` prefixed string into a set reference.
+// It is applied to the operand positions of set references only.
+func (e *Expression) decodeSetRef() {
+	if e.String != nil && strings.HasPrefix(*e.String, SetRefPrefix) {
+		e.SetRef, e.String = strings.TrimPrefix(*e.String, SetRefPrefix), nil
+	}
+}
+
+func (p Payload) MarshalJSON() ([]byte, error) {
+	if p.Base != "" {
+		return json.Marshal(struct {
+			Base   string `json:"base"`
+			Offset int    `json:"offset"`
+			Len    int    `json:"len"`
+		}{p.Base, p.Offset, p.Len})
+	}
+	return json.Marshal(struct {
+		Protocol string `json:"protocol"`
+		Field    string `json:"field"`
+	}{p.Protocol, p.Field})
+}
+
 func (e Expression) MarshalJSON() ([]byte, error) {
 	var dynamicStruct interface{}
 
 	switch {
 	case e.RowData != nil:
 		return e.RowData, nil
+	case e.SetRef != "":
+		dynamicStruct = SetRefPrefix + e.SetRef
 	case e.String != nil:
 		dynamicStruct = *e.String
 	case e.Float64 != nil:
@@ -232,17 +510,28 @@ func (e *Expression) UnmarshalJSON(data []byte) error {
 			return err
 		}
 		*e = Expression(expression)
+	case []interface{}:
+		// Lists (e.g. map element pairs in anonymous maps) are kept as row data.
 	default:
 		return fmt.Errorf("unsupported field type in expression: %T(%v)", dynamicStruct, dynamicStruct)
 	}
 
-	if e.String == nil && e.Float64 == nil && e.Bool == nil && e.Payload == nil {
-		e.RowData = data
+	if !e.isTyped() {
+		// The data buffer may be reused by the decoder, therefore it is copied.
+		e.RowData = append(json.RawMessage(nil), data...)
 	}
 
 	return nil
 }
 
+// isTyped reports if the expression has been decoded into (at least) one of the typed fields.
+func (e *Expression) isTyped() bool {
+	return e.String != nil || e.SetRef != "" || e.Float64 != nil || e.Bool != nil ||
+		e.Payload != nil || e.Meta != nil || e.Ct != nil || e.Rt != nil || e.TCPOption != nil || e.Numgen != nil ||
+		e.Set != nil || e.Range != nil || e.Concat != nil || e.And != nil || e.Or != nil ||
+		e.Xor != nil || e.LShift != nil || e.RShift != nil
+}
+
 func Accept() Verdict {
 	return Verdict{SimpleVerdict: SimpleVerdict{Accept: true}}
 }
@@ -30,10 +30,25 @@ type Root struct {
 const ruleSetKey = "ruleset"
 
 type Objects struct {
-	Table   *Table `json:"table,omitempty"`
-	Chain   *Chain `json:"chain,omitempty"`
-	Rule    *Rule  `json:"rule,omitempty"`
-	Ruleset bool   `json:"-"`
+	Table     *Table          `json:"table,omitempty"`
+	Chain     *Chain          `json:"chain,omitempty"`
+	Rule      *Rule           `json:"rule,omitempty"`
+	Set       *Set            `json:"set,omitempty"`
+	Map       *Map            `json:"map,omitempty"`
+	Flowtable *Flowtable      `json:"flowtable,omitempty"`
+	Counter   *NamedCounter   `json:"counter,omitempty"`
+	Limit     *NamedLimit     `json:"limit,omitempty"`
+	Quota     *NamedQuota     `json:"quota,omitempty"`
+	Synproxy  *NamedSynproxy  `json:"synproxy,omitempty"`
+	CtHelper  *NamedCtHelper  `json:"ct helper,omitempty"`
+	CtTimeout *NamedCtTimeout `json:"ct timeout,omitempty"`
+	Ruleset   bool            `json:"-"`
+	// RulesetFamily limits the ruleset to the tables of the given family, it is only relevant with Ruleset.
+	RulesetFamily string `json:"-"`
+}
+
+type rulesetFamily struct {
+	Family string `json:"family"`
 }
 
 func (o Objects) MarshalJSON() ([]byte, error) {
@@ -52,6 +67,12 @@ func (o Objects) MarshalJSON() ([]byte, error) {
 			return nil, err
 		}
 		dynamicStructure[ruleSetKey] = nil
+		if o.RulesetFamily != "" {
+			dynamicStructure[ruleSetKey], err = json.Marshal(rulesetFamily{Family: o.RulesetFamily})
+			if err != nil {
+				return nil, err
+			}
+		}
 		data, err = json.Marshal(dynamicStructure)
 		if err != nil {
 			return nil, err
@@ -61,16 +82,72 @@ func (o Objects) MarshalJSON() ([]byte, error) {
 	return data, nil
 }
 
+func (o *Objects) UnmarshalJSON(data []byte) error {
+	type _Objects Objects
+	objects := _Objects{}
+
+	if err := json.Unmarshal(data, &objects); err != nil {
+		return err
+	}
+	*o = Objects(objects)
+
+	dynamicStructure := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &dynamicStructure); err != nil {
+		return err
+	}
+	var ruleset json.RawMessage
+	ruleset, o.Ruleset = dynamicStructure[ruleSetKey]
+	if o.Ruleset {
+		var family rulesetFamily
+		if err := json.Unmarshal(ruleset, &family); err == nil {
+			o.RulesetFamily = family.Family
+		}
+	}
+
+	return nil
+}
+
 type Nftable struct {
-	Table *Table `json:"table,omitempty"`
-	Chain *Chain `json:"chain,omitempty"`
-	Rule  *Rule  `json:"rule,omitempty"`
+	Table     *Table          `json:"table,omitempty"`
+	Chain     *Chain          `json:"chain,omitempty"`
+	Rule      *Rule           `json:"rule,omitempty"`
+	Set       *Set            `json:"set,omitempty"`
+	Map       *Map            `json:"map,omitempty"`
+	Flowtable *Flowtable      `json:"flowtable,omitempty"`
+	Counter   *NamedCounter   `json:"counter,omitempty"`
+	Limit     *NamedLimit     `json:"limit,omitempty"`
+	Quota     *NamedQuota     `json:"quota,omitempty"`
+	Synproxy  *NamedSynproxy  `json:"synproxy,omitempty"`
+	CtHelper  *NamedCtHelper  `json:"ct helper,omitempty"`
+	CtTimeout *NamedCtTimeout `json:"ct timeout,omitempty"`
 
 	Add    *Objects `json:"add,omitempty"`
+	Create *Objects `json:"create,omitempty"`
+	// Insert adds a rule at the beginning of its chain, or before the rule of the given handle.
+	Insert *Objects `json:"insert,omitempty"`
 	Delete *Objects `json:"delete,omitempty"`
-	Flush  *Objects `json:"flush,omitempty"`
+	// Destroy deletes the objects when they exist, it requires nftables 1.0.8 or newer.
+	Destroy *Objects `json:"destroy,omitempty"`
+	Flush   *Objects `json:"flush,omitempty"`
+	Rename  *Objects `json:"rename,omitempty"`
+	// Reset zeroes the stateful objects (e.g. counters and quotas), nft lists their values before the reset.
+	Reset *Objects `json:"reset,omitempty"`
 
 	Metainfo *Metainfo `json:"metainfo,omitempty"`
+
+	// RowData accepts an arbitrary command which cannot be composed from the existing schema.
+	// When set, all other fields are ignored on serialization.
+	// Example:
+	// `schema.Nftable{RowData: json.RawMessage(`{"add":{"element":{...}}}`)}`
+	RowData json.RawMessage `json:"-"`
+}
+
+func (n Nftable) MarshalJSON() ([]byte, error) {
+	if n.RowData != nil {
+		return n.RowData, nil
+	}
+	type _Nftable Nftable
+	return json.Marshal(_Nftable(n))
 }
 
 type Metainfo struct {
@@ -78,3 +155,28 @@ type Metainfo struct {
 	ReleaseName       string `json:"release_name"`
 	JsonSchemaVersion int    `json:"json_schema_version"`
 }
+
+// StringList is a list of strings which is encoded as a single string when it has one element.
+// When decoded, both a string and a list of strings are accepted, as nft emits both forms.
+type StringList []string
+
+func (l StringList) MarshalJSON() ([]byte, error) {
+	if len(l) == 1 {
+		return json.Marshal(l[0])
+	}
+	return json.Marshal([]string(l))
+}
+
+func (l *StringList) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*l = StringList{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
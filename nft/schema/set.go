@@ -0,0 +1,51 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package schema
+
+// Set Key Types
+const (
+	SetTypeIPv4Addr    = "ipv4_addr"
+	SetTypeIPv6Addr    = "ipv6_addr"
+	SetTypeEtherAddr   = "ether_addr"
+	SetTypeInetProto   = "inet_proto"
+	SetTypeInetService = "inet_service"
+	SetTypeMark        = "mark"
+	SetTypeIfname      = "ifname"
+)
+
+// Set Flags
+const (
+	SetFlagConstant = "constant"
+	SetFlagInterval = "interval"
+	SetFlagTimeout  = "timeout"
+	SetFlagDynamic  = "dynamic"
+)
+
+type Set struct {
+	Family string     `json:"family"`
+	Table  string     `json:"table"`
+	Name   string     `json:"name,omitempty"`
+	Handle *int       `json:"handle,omitempty"`
+	Type   StringList `json:"type,omitempty"`
+	Flags  StringList `json:"flags,omitempty"`
+	// Comment is the set description, up to 128 characters.
+	Comment string       `json:"comment,omitempty"`
+	Elem    []Expression `json:"elem,omitempty"`
+}
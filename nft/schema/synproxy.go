@@ -0,0 +1,78 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package schema
+
+import (
+	"encoding/json"
+)
+
+// Synproxy Flags
+const (
+	SynproxyFlagTimestamp = "timestamp"
+	SynproxyFlagSackPerm  = "sack-perm"
+)
+
+// NamedSynproxy is a stateful synproxy object, which rules may reference by name.
+type NamedSynproxy struct {
+	Family string     `json:"family"`
+	Table  string     `json:"table"`
+	Name   string     `json:"name"`
+	Handle *int       `json:"handle,omitempty"`
+	Mss    int        `json:"mss,omitempty"`
+	Wscale int        `json:"wscale,omitempty"`
+	Flags  StringList `json:"flags,omitempty"`
+}
+
+// Synproxy is the synproxy statement, intercepting the TCP three-way handshake on behalf of the server.
+// An anonymous synproxy holds the TCP options used in the handshake,
+// while a named synproxy references a synproxy object (see NamedSynproxy) by its name.
+type Synproxy struct {
+	Name   string
+	Mss    int
+	Wscale int
+	Flags  StringList
+}
+
+type anonymousSynproxy struct {
+	Mss    int        `json:"mss,omitempty"`
+	Wscale int        `json:"wscale,omitempty"`
+	Flags  StringList `json:"flags,omitempty"`
+}
+
+func (s Synproxy) MarshalJSON() ([]byte, error) {
+	if s.Name != "" {
+		return json.Marshal(s.Name)
+	}
+	return json.Marshal(anonymousSynproxy{Mss: s.Mss, Wscale: s.Wscale, Flags: s.Flags})
+}
+
+func (s *Synproxy) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*s = Synproxy{Name: name}
+		return nil
+	}
+	var synproxy anonymousSynproxy
+	if err := json.Unmarshal(data, &synproxy); err != nil {
+		return err
+	}
+	*s = Synproxy{Mss: synproxy.Mss, Wscale: synproxy.Wscale, Flags: synproxy.Flags}
+	return nil
+}
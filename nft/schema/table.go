@@ -29,7 +29,17 @@ const (
 	FamilyNETDEV = "netdev" // Netdev address AddressFamily, handling packets from ingress.
 )
 
+// Table Flags
+const (
+	TableFlagDormant = "dormant" // The table is not evaluated, its chains are not registered on the hooks.
+	TableFlagOwner   = "owner"   // The table is owned by the process which created it, removed when it exits.
+)
+
 type Table struct {
-	Family string `json:"family"`
-	Name   string `json:"name"`
+	Family string     `json:"family"`
+	Name   string     `json:"name,omitempty"`
+	Handle *int       `json:"handle,omitempty"`
+	Flags  StringList `json:"flags,omitempty"`
+	// Comment is the table description, up to 128 characters.
+	Comment string `json:"comment,omitempty"`
 }
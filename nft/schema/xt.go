@@ -0,0 +1,40 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package schema
+
+import (
+	"encoding/json"
+)
+
+// Xt is the statement of an iptables extension, listed in rulesets created by iptables-nft.
+// The extension data is not interpreted, it is preserved for the ruleset to round-trip.
+// Note that nft does not accept the statement as input, rules including it cannot be applied.
+type Xt struct {
+	Type string          `json:"type"`
+	Name string          `json:"name"`
+	Info json.RawMessage `json:"info,omitempty"`
+}
+
+// Xt Types
+const (
+	XtTypeMatch   = "match"
+	XtTypeTarget  = "target"
+	XtTypeWatcher = "watcher"
+)
@@ -0,0 +1,110 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+// Service is a transport (L4) protocol port, e.g. tcp port 22.
+type Service struct {
+	// Protocol is either schema.PayloadProtocolTCP or schema.PayloadProtocolUDP.
+	Protocol string
+	Port     int
+}
+
+// DestinationPort returns the destination port field of the service protocol (TCPDPort or UDPDPort).
+// The generic transport header port (THDPort) is returned for other protocols.
+func (s Service) DestinationPort() PortField {
+	switch s.Protocol {
+	case schema.PayloadProtocolTCP:
+		return TCPDPort
+	case schema.PayloadProtocolUDP:
+		return UDPDPort
+	}
+	return THDPort
+}
+
+// ServiceGroup is a named group of services, which policies may reference as a whole.
+type ServiceGroup struct {
+	Name     string
+	Services []Service
+}
+
+// AddServiceGroup adds to the config a dedicated (regular) chain for the service group,
+// named after the group, applying the verdict to the traffic of the group services.
+// A rule is added per protocol, matching all the protocol ports using an anonymous set.
+// The returned jump statement references the group chain and may be used by any number of rules.
+// Adding a group which has already been added to the config (by name), only returns the jump statement.
+func (c *Config) AddServiceGroup(table *schema.Table, group ServiceGroup, verdict schema.Verdict) (schema.Statement, error) {
+	jump := schema.Statement{Verdict: schema.Verdict{Jump: &schema.ToTarget{Target: group.Name}}}
+
+	chain := NewRegularChain(table, group.Name)
+	if c.LookupChain(chain) != nil {
+		return jump, nil
+	}
+
+	portsByProtocol := map[string][]int{}
+	for _, service := range group.Services {
+		if service.Protocol != schema.PayloadProtocolTCP && service.Protocol != schema.PayloadProtocolUDP {
+			return schema.Statement{}, fmt.Errorf("service group %q: unsupported protocol %q", group.Name, service.Protocol)
+		}
+		if service.Port < 1 || service.Port > 65535 {
+			return schema.Statement{}, fmt.Errorf("service group %q: invalid port %d", group.Name, service.Port)
+		}
+		portsByProtocol[service.Protocol] = append(portsByProtocol[service.Protocol], service.Port)
+	}
+
+	c.AddChain(chain)
+	for _, protocol := range []string{schema.PayloadProtocolTCP, schema.PayloadProtocolUDP} {
+		ports := portsByProtocol[protocol]
+		if len(ports) == 0 {
+			continue
+		}
+		statements := []schema.Statement{matchDestinationPorts(protocol, ports), {Verdict: verdict}}
+		c.AddRule(NewRule(table, chain, statements, nil, nil, ""))
+	}
+
+	return jump, nil
+}
+
+// matchDestinationPorts returns a match statement of the destination ports (e.g. `tcp dport { 22, 80 }`).
+// The ports are sorted and deduplicated.
+func matchDestinationPorts(protocol string, ports []int) schema.Statement {
+	sorted := append([]int(nil), ports...)
+	sort.Ints(sorted)
+
+	var elements []schema.Expression
+	for i, port := range sorted {
+		if i > 0 && port == sorted[i-1] {
+			continue
+		}
+		elements = append(elements, Port(port))
+	}
+
+	right := AnonymousSet(elements...)
+	if len(elements) == 1 {
+		right = elements[0]
+	}
+	return MatchPort(Service{Protocol: protocol}.DestinationPort(), right)
+}
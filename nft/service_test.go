@@ -0,0 +1,89 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft_test
+
+import (
+	"fmt"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/networkplumbing/go-nft/nft"
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+func TestServiceGroup(t *testing.T) {
+	table := nft.NewTable(tableName, nft.FamilyINET)
+	group := nft.ServiceGroup{
+		Name: "web",
+		Services: []nft.Service{
+			{Protocol: schema.PayloadProtocolTCP, Port: 443},
+			{Protocol: schema.PayloadProtocolTCP, Port: 80},
+			{Protocol: schema.PayloadProtocolUDP, Port: 443},
+			{Protocol: schema.PayloadProtocolTCP, Port: 80},
+		},
+	}
+
+	t.Run("Add a service group", func(t *testing.T) {
+		config := nft.NewConfig()
+		jump, err := config.AddServiceGroup(table, group, schema.Accept())
+		assert.NoError(t, err)
+		assert.Equal(t, schema.Statement{Verdict: schema.Verdict{Jump: &schema.ToTarget{Target: "web"}}}, jump)
+
+		serialized, err := config.ToJSON()
+		assert.NoError(t, err)
+		ruleArgs := fmt.Sprintf(`"family":"inet","table":%q,"chain":"web"`, tableName)
+		expected := fmt.Sprintf(
+			`{"nftables":[`+
+				`{"chain":{"family":"inet","table":%[1]q,"name":"web"}},`+
+				`{"rule":{%[2]s,"expr":[{"match":{"op":"==","left":{"payload":{"protocol":"tcp","field":"dport"}},"right":{"set":[80,443]}}},{"accept":null}]}},`+
+				`{"rule":{%[2]s,"expr":[{"match":{"op":"==","left":{"payload":{"protocol":"udp","field":"dport"}},"right":443}},{"accept":null}]}}`+
+				`]}`,
+			tableName, ruleArgs,
+		)
+		assert.Equal(t, expected, string(serialized))
+	})
+
+	t.Run("Add a service group which has already been added", func(t *testing.T) {
+		config := nft.NewConfig()
+		_, err := config.AddServiceGroup(table, group, schema.Accept())
+		assert.NoError(t, err)
+		entries := len(config.Nftables)
+
+		jump, err := config.AddServiceGroup(table, group, schema.Accept())
+		assert.NoError(t, err)
+		assert.Equal(t, "web", jump.Jump.Target)
+		assert.Len(t, config.Nftables, entries)
+	})
+
+	t.Run("Add a service group with an invalid service", func(t *testing.T) {
+		config := nft.NewConfig()
+		invalidGroup := nft.ServiceGroup{Name: "invalid", Services: []nft.Service{{Protocol: "sctp", Port: 22}}}
+		_, err := config.AddServiceGroup(table, invalidGroup, schema.Accept())
+		assert.Error(t, err)
+		assert.Empty(t, config.Nftables)
+	})
+}
+
+func TestServiceDestinationPort(t *testing.T) {
+	assert.Equal(t, nft.TCPDPort, nft.Service{Protocol: schema.PayloadProtocolTCP, Port: 22}.DestinationPort())
+	assert.Equal(t, nft.UDPDPort, nft.Service{Protocol: schema.PayloadProtocolUDP, Port: 53}.DestinationPort())
+	assert.Equal(t, nft.THDPort, nft.Service{Protocol: "sctp", Port: 9}.DestinationPort())
+}
@@ -0,0 +1,151 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft
+
+import (
+	"fmt"
+
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+type SetType string
+type SetFlag string
+
+// Set Key Types
+const (
+	SetTypeIPv4Addr    SetType = schema.SetTypeIPv4Addr
+	SetTypeIPv6Addr    SetType = schema.SetTypeIPv6Addr
+	SetTypeEtherAddr   SetType = schema.SetTypeEtherAddr
+	SetTypeInetProto   SetType = schema.SetTypeInetProto
+	SetTypeInetService SetType = schema.SetTypeInetService
+	SetTypeMark        SetType = schema.SetTypeMark
+	SetTypeIfname      SetType = schema.SetTypeIfname
+)
+
+// Set Flags
+const (
+	SetFlagConstant SetFlag = schema.SetFlagConstant
+	SetFlagInterval SetFlag = schema.SetFlagInterval
+	SetFlagTimeout  SetFlag = schema.SetFlagTimeout
+	SetFlagDynamic  SetFlag = schema.SetFlagDynamic
+)
+
+// NewSet returns a new schema set structure for a named set.
+// Flags and elements are optional.
+// For sets with concatenated keys, set the key types directly on the returned set.
+func NewSet(table *schema.Table, name string, setType SetType, flags []SetFlag, elements []schema.Expression) *schema.Set {
+	s := &schema.Set{
+		Family: table.Family,
+		Table:  table.Name,
+		Name:   name,
+		Type:   schema.StringList{string(setType)},
+		Elem:   elements,
+	}
+
+	for _, flag := range flags {
+		s.Flags = append(s.Flags, string(flag))
+	}
+
+	return s
+}
+
+// AddSet appends the given set to the nftable config.
+// The set is added without an explicit action (`add`).
+// Adding multiple times the same set has no effect when the config is applied,
+// except for adding the given elements to it.
+func (c *Config) AddSet(set *schema.Set) {
+	nftable := schema.Nftable{Set: set}
+	c.Nftables = append(c.Nftables, nftable)
+}
+
+// CreateSet appends the given set to the nftable config with the `create` action.
+// Unlike adding, creating a set which already exists results with a failure when the config is applied.
+func (c *Config) CreateSet(set *schema.Set) {
+	nftable := schema.Nftable{Create: &schema.Objects{Set: set}}
+	c.Nftables = append(c.Nftables, nftable)
+}
+
+// DeleteSet appends a given set to the nftable config
+// with the `delete` action.
+// Attempting to delete a non-existing set, results with a failure when the config is applied.
+// The set must not be referenced by any rule.
+func (c *Config) DeleteSet(set *schema.Set) {
+	nftable := schema.Nftable{Delete: &schema.Objects{Set: set}}
+	c.Nftables = append(c.Nftables, nftable)
+}
+
+// DeleteSetByHandle appends a given set to the nftable config
+// with the `delete` action, identifying the set strictly by its table and handle (ignoring its name).
+// See DeleteTableByHandle for details.
+func (c *Config) DeleteSetByHandle(set *schema.Set) error {
+	if set.Handle == nil {
+		return fmt.Errorf("set %q in table %s %s has no handle", set.Name, set.Family, set.Table)
+	}
+	c.DeleteSet(&schema.Set{Family: set.Family, Table: set.Table, Handle: set.Handle})
+	return nil
+}
+
+// DestroySet appends a given set to the nftable config with the `destroy` action.
+// Unlike deleting, destroying a non-existing set has no effect when the config is applied.
+// The set must not be referenced by any rule.
+func (c *Config) DestroySet(set *schema.Set) {
+	nftable := schema.Nftable{Destroy: &schema.Objects{Set: set}}
+	c.Nftables = append(c.Nftables, nftable)
+}
+
+// FlushSet appends a given set to the nftable config
+// with the `flush` action.
+// All elements of the set are removed (when applied).
+// Attempting to flush a non-existing set, results with a failure when the config is applied.
+func (c *Config) FlushSet(set *schema.Set) {
+	nftable := schema.Nftable{Flush: &schema.Objects{Set: set}}
+	c.Nftables = append(c.Nftables, nftable)
+}
+
+// LookupSet searches the configuration for a matching set and returns it.
+// The set is matched first by the table and set name.
+// The set key type is optional for matching.
+// Mutating the returned set will result in mutating the configuration.
+func (c *Config) LookupSet(toFind *schema.Set) *schema.Set {
+	for _, nftable := range c.Nftables {
+		if set := nftable.Set; set != nil {
+			match := set.Table == toFind.Table && set.Family == toFind.Family && set.Name == toFind.Name
+			if match && len(toFind.Type) > 0 {
+				match = areStringListsEqual(set.Type, toFind.Type)
+			}
+			if match {
+				return set
+			}
+		}
+	}
+	return nil
+}
+
+// AnonymousSet returns an anonymous set expression with the given elements,
+// commonly used as the right operand of a match (e.g. `tcp dport { 22, 80, 443 }`).
+func AnonymousSet(elements ...schema.Expression) schema.Expression {
+	return schema.Expression{Set: elements}
+}
+
+// SetReference returns a reference expression to the given (named) set (e.g. `@blocked`),
+// commonly used as the right operand of a match to test membership in the set.
+func SetReference(set *schema.Set) schema.Expression {
+	return schema.Expression{SetRef: set.Name}
+}
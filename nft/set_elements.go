@@ -0,0 +1,201 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+// SetElementIterator pages through the elements of a set, decoding them lazily from the nft JSON output.
+// It keeps the memory usage bounded when iterating over sets with a large number of elements.
+type SetElementIterator struct {
+	decoder *json.Decoder
+	closer  func() error
+	started bool
+	done    bool
+}
+
+// ReadSetElements lists the given set from the system and returns an iterator over its elements.
+// The elements are decoded while the nft output is consumed.
+// Close must be called when the iteration is over.
+// The system is expected to have the `nft` executable deployed and nftables enabled in the kernel.
+func ReadSetElements(set *schema.Set) (*SetElementIterator, error) {
+	return defaultExecutor.readSetElements(set)
+}
+
+func (e *executor) readSetElements(set *schema.Set) (*SetElementIterator, error) {
+	stdout, wait, err := e.execCommandStream(context.Background(), cmdJSON, cmdList, cmdSet, set.Family, set.Table, set.Name)
+	if err != nil {
+		return nil, err
+	}
+	return &SetElementIterator{decoder: json.NewDecoder(stdout), closer: wait}, nil
+}
+
+// DecodeSetElements returns an iterator over the set elements of a nftables JSON document
+// (e.g. as listed by `nft -j list set`).
+// When the document includes multiple sets, the elements of the first set are iterated.
+func DecodeSetElements(r io.Reader) *SetElementIterator {
+	return &SetElementIterator{decoder: json.NewDecoder(r)}
+}
+
+// Next returns the next page of elements, including up to pageSize elements.
+// An empty page is returned when all the elements have been consumed.
+// The page size must be positive.
+func (it *SetElementIterator) Next(pageSize int) ([]schema.Expression, error) {
+	if pageSize <= 0 {
+		return nil, fmt.Errorf("invalid set elements page size: %d", pageSize)
+	}
+	if !it.started {
+		it.started = true
+		found, err := it.seekElements()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode set elements: %v", err)
+		}
+		it.done = !found
+	}
+
+	var page []schema.Expression
+	for !it.done && len(page) < pageSize {
+		if !it.decoder.More() {
+			it.done = true
+			break
+		}
+		var element schema.Expression
+		if err := it.decoder.Decode(&element); err != nil {
+			return nil, fmt.Errorf("failed to decode set element: %v", err)
+		}
+		page = append(page, element)
+	}
+	return page, nil
+}
+
+// Close releases the resources used by the iterator.
+// An error is returned if the nft execution failed.
+func (it *SetElementIterator) Close() error {
+	if it.closer == nil {
+		return nil
+	}
+	return it.closer()
+}
+
+// seekElements advances the decoder into the first set elements list ("elem"),
+// reporting false when no such list exists.
+func (it *SetElementIterator) seekElements() (bool, error) {
+	dec := it.decoder
+	if err := expectDelim(dec, '{'); err != nil {
+		return false, err
+	}
+	for dec.More() {
+		key, err := nextKey(dec)
+		if err != nil {
+			return false, err
+		}
+		if key != "nftables" {
+			if err := skipValue(dec); err != nil {
+				return false, err
+			}
+			continue
+		}
+
+		if err := expectDelim(dec, '['); err != nil {
+			return false, err
+		}
+		for dec.More() {
+			found, err := seekEntryElements(dec)
+			if err != nil || found {
+				return found, err
+			}
+		}
+		return false, nil
+	}
+	return false, nil
+}
+
+// seekEntryElements consumes a nftables entry, stopping when the set elements list is reached.
+func seekEntryElements(dec *json.Decoder) (bool, error) {
+	if err := expectDelim(dec, '{'); err != nil {
+		return false, err
+	}
+	for dec.More() {
+		key, err := nextKey(dec)
+		if err != nil {
+			return false, err
+		}
+		if key != "set" {
+			if err := skipValue(dec); err != nil {
+				return false, err
+			}
+			continue
+		}
+
+		if err := expectDelim(dec, '{'); err != nil {
+			return false, err
+		}
+		for dec.More() {
+			key, err := nextKey(dec)
+			if err != nil {
+				return false, err
+			}
+			if key == "elem" {
+				return true, expectDelim(dec, '[')
+			}
+			if err := skipValue(dec); err != nil {
+				return false, err
+			}
+		}
+		if err := expectDelim(dec, '}'); err != nil {
+			return false, err
+		}
+	}
+	return false, expectDelim(dec, '}')
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := token.(json.Delim); !ok || d != delim {
+		return fmt.Errorf("unexpected token %v, expecting %v", token, delim)
+	}
+	return nil
+}
+
+func nextKey(dec *json.Decoder) (string, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return "", err
+	}
+	key, ok := token.(string)
+	if !ok {
+		return "", fmt.Errorf("unexpected token %v, expecting an object key", token)
+	}
+	return key, nil
+}
+
+func skipValue(dec *json.Decoder) error {
+	var value json.RawMessage
+	return dec.Decode(&value)
+}
@@ -0,0 +1,87 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft_test
+
+import (
+	"strings"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/networkplumbing/go-nft/nft"
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+func TestSetElementIterator(t *testing.T) {
+	t.Run("Iterate set elements in pages", func(t *testing.T) {
+		listing := `{"nftables":[` +
+			`{"metainfo":{"version":"0.9.3","release_name":"Topsy","json_schema_version":1}},` +
+			`{"set":{"family":"ip","name":"ports","table":"test-table","type":"inet_service","handle":4,` +
+			`"elem":[22,80,443,{"range":[8000,8080]},"ssh"]}}` +
+			`]}`
+		iterator := nft.DecodeSetElements(strings.NewReader(listing))
+
+		var pages [][]schema.Expression
+		for {
+			page, err := iterator.Next(2)
+			assert.NoError(t, err)
+			if len(page) == 0 {
+				break
+			}
+			pages = append(pages, page)
+		}
+		assert.NoError(t, iterator.Close())
+
+		assert.Len(t, pages, 3)
+		assert.Len(t, pages[0], 2)
+		assert.Equal(t, float64(80), *pages[0][1].Float64)
+		assert.Equal(t, nft.NumberRange(8000, 8080), pages[1][1])
+		assert.Equal(t, "ssh", *pages[2][0].String)
+	})
+
+	t.Run("Iterate a set with no elements", func(t *testing.T) {
+		listing := `{"nftables":[{"set":{"family":"ip","name":"ports","table":"test-table","type":"inet_service"}}]}`
+		iterator := nft.DecodeSetElements(strings.NewReader(listing))
+
+		page, err := iterator.Next(10)
+		assert.NoError(t, err)
+		assert.Empty(t, page)
+	})
+
+	t.Run("Iterate set elements of a malformed listing", func(t *testing.T) {
+		iterator := nft.DecodeSetElements(strings.NewReader(`{"nftables":{}}`))
+
+		_, err := iterator.Next(10)
+		assert.Error(t, err)
+	})
+
+	t.Run("Iterate set elements with an invalid page size", func(t *testing.T) {
+		listing := `{"nftables":[{"set":{"family":"ip","name":"ports","table":"test-table","type":"inet_service","elem":[22]}}]}`
+		iterator := nft.DecodeSetElements(strings.NewReader(listing))
+
+		for _, pageSize := range []int{0, -1} {
+			_, err := iterator.Next(pageSize)
+			assert.Error(t, err)
+		}
+		page, err := iterator.Next(10)
+		assert.NoError(t, err)
+		assert.Len(t, page, 1, "An invalid page size does not consume the elements")
+	})
+}
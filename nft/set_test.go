@@ -0,0 +1,206 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/networkplumbing/go-nft/nft"
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+type setAction string
+
+type setActionFunc func(*nft.Config, *schema.Set)
+
+// Set Actions
+const (
+	setADD     setAction = "add"
+	setDELETE  setAction = "delete"
+	setFLUSH   setAction = "flush"
+	setCREATE  setAction = "create"
+	setDESTROY setAction = "destroy"
+)
+
+const setName = "test-set"
+
+func TestSet(t *testing.T) {
+	testSetActions(t)
+	testAddSetWithFlagsAndElements(t)
+	testSetLookup(t)
+	testAnonymousSetMatch(t)
+	testSetReferenceMatch(t)
+}
+
+func testSetActions(t *testing.T) {
+	actions := map[setAction]setActionFunc{
+		setADD:     func(c *nft.Config, s *schema.Set) { c.AddSet(s) },
+		setDELETE:  func(c *nft.Config, s *schema.Set) { c.DeleteSet(s) },
+		setFLUSH:   func(c *nft.Config, s *schema.Set) { c.FlushSet(s) },
+		setCREATE:  func(c *nft.Config, s *schema.Set) { c.CreateSet(s) },
+		setDESTROY: func(c *nft.Config, s *schema.Set) { c.DestroySet(s) },
+	}
+
+	table := nft.NewTable(tableName, nft.FamilyIP)
+	set := nft.NewSet(table, setName, nft.SetTypeIPv4Addr, nil, nil)
+
+	for action, actionFunc := range actions {
+		t.Run(fmt.Sprintf("%s set", action), func(t *testing.T) {
+			config := nft.NewConfig()
+			actionFunc(config, set)
+
+			serializedConfig, err := config.ToJSON()
+			assert.NoError(t, err)
+
+			setArgs := fmt.Sprintf(`"family":%q,"table":%q,"name":%q,"type":"ipv4_addr"`, table.Family, table.Name, setName)
+			var expected string
+			if action == setADD {
+				expected = fmt.Sprintf(`{"nftables":[{"set":{%s}}]}`, setArgs)
+			} else {
+				expected = fmt.Sprintf(`{"nftables":[{%q:{"set":{%s}}}]}`, action, setArgs)
+			}
+			assert.Equal(t, expected, string(serializedConfig))
+		})
+	}
+}
+
+func testAddSetWithFlagsAndElements(t *testing.T) {
+	table := nft.NewTable(tableName, nft.FamilyINET)
+	serializedConfig := fmt.Sprintf(
+		`{"nftables":[{"set":{"family":"inet","table":%q,"name":%q,"type":"inet_service","flags":"interval","elem":[22,"http"]}}]}`,
+		tableName, setName,
+	)
+
+	port, service := float64(22), "http"
+	set := nft.NewSet(
+		table, setName, nft.SetTypeInetService,
+		[]nft.SetFlag{nft.SetFlagInterval},
+		[]schema.Expression{{Float64: &port}, {String: &service}},
+	)
+
+	t.Run("Add set with flags and elements, check serialization", func(t *testing.T) {
+		config := nft.NewConfig()
+		config.AddSet(set)
+
+		serialized, err := config.ToJSON()
+		assert.NoError(t, err)
+		assert.Equal(t, serializedConfig, string(serialized))
+	})
+
+	t.Run("Add set with flags and elements, check deserialization", func(t *testing.T) {
+		var deserializedConfig nft.Config
+		assert.NoError(t, json.Unmarshal([]byte(serializedConfig), &deserializedConfig))
+
+		expectedConfig := nft.NewConfig()
+		expectedConfig.AddSet(set)
+		assert.Equal(t, expectedConfig, &deserializedConfig)
+	})
+}
+
+func testSetLookup(t *testing.T) {
+	config := nft.NewConfig()
+	table := nft.NewTable(tableName, nft.FamilyBridge)
+	config.AddTable(table)
+	set := nft.NewSet(table, setName, nft.SetTypeEtherAddr, nil, nil)
+	config.AddSet(set)
+
+	t.Run("Lookup an existing set", func(t *testing.T) {
+		assert.Equal(t, set, config.LookupSet(set))
+	})
+
+	t.Run("Lookup an existing set by name", func(t *testing.T) {
+		assert.Equal(t, set, config.LookupSet(&schema.Set{Family: table.Family, Table: table.Name, Name: setName}))
+	})
+
+	t.Run("Lookup a missing set (type not matching)", func(t *testing.T) {
+		assert.Nil(t, config.LookupSet(nft.NewSet(table, setName, nft.SetTypeIPv4Addr, nil, nil)))
+	})
+
+	t.Run("Lookup a missing set", func(t *testing.T) {
+		assert.Nil(t, config.LookupSet(nft.NewSet(table, "set-na", nft.SetTypeEtherAddr, nil, nil)))
+	})
+}
+
+func testAnonymousSetMatch(t *testing.T) {
+	const serializedStatement = `{"match":{"op":"==","left":{"payload":{"protocol":"tcp","field":"dport"}},"right":{"set":[22,"http",443]}}}`
+
+	ssh, http, https := float64(22), "http", float64(443)
+	statement := schema.Statement{Match: &schema.Match{
+		Op:    schema.OperEQ,
+		Left:  schema.Expression{Payload: &schema.Payload{Protocol: schema.PayloadProtocolTCP, Field: schema.PayloadFieldTCPDPort}},
+		Right: nft.AnonymousSet(schema.Expression{Float64: &ssh}, schema.Expression{String: &http}, schema.Expression{Float64: &https}),
+	}}
+
+	t.Run("Match an anonymous set, check serialization", func(t *testing.T) {
+		serialized, err := json.Marshal(statement)
+		assert.NoError(t, err)
+		assert.Equal(t, serializedStatement, string(serialized))
+	})
+
+	t.Run("Match an anonymous set, check deserialization", func(t *testing.T) {
+		var deserializedStatement schema.Statement
+		assert.NoError(t, json.Unmarshal([]byte(serializedStatement), &deserializedStatement))
+		assert.Equal(t, statement, deserializedStatement)
+	})
+
+	t.Run("Match an anonymous map, check deserialization", func(t *testing.T) {
+		const serializedMap = `{"set":[[22,{"accept":null}]]}`
+		var expression schema.Expression
+		assert.NoError(t, json.Unmarshal([]byte(serializedMap), &expression))
+		assert.Equal(t, []schema.Expression{{RowData: json.RawMessage(`[22,{"accept":null}]`)}}, expression.Set)
+	})
+}
+
+func testSetReferenceMatch(t *testing.T) {
+	const serializedStatement = `{"match":{"op":"==","left":{"payload":{"protocol":"ip","field":"saddr"}},"right":"@test-set"}}`
+
+	table := nft.NewTable(tableName, nft.FamilyIP)
+	set := nft.NewSet(table, setName, nft.SetTypeIPv4Addr, nil, nil)
+	statement := schema.Statement{Match: &schema.Match{
+		Op:    schema.OperEQ,
+		Left:  schema.Expression{Payload: &schema.Payload{Protocol: schema.PayloadProtocolIP4, Field: schema.PayloadFieldIPSAddr}},
+		Right: nft.SetReference(set),
+	}}
+
+	t.Run("Match a set reference, check serialization", func(t *testing.T) {
+		serialized, err := json.Marshal(statement)
+		assert.NoError(t, err)
+		assert.Equal(t, serializedStatement, string(serialized))
+	})
+
+	t.Run("Match a set reference, check deserialization", func(t *testing.T) {
+		var deserializedStatement schema.Statement
+		assert.NoError(t, json.Unmarshal([]byte(serializedStatement), &deserializedStatement))
+		assert.Equal(t, statement, deserializedStatement)
+	})
+
+	t.Run("Match an anonymous set of prefixed strings, check deserialization", func(t *testing.T) {
+		var deserializedStatement schema.Statement
+		assert.NoError(t, json.Unmarshal(
+			[]byte(`{"match":{"op":"==","left":{"meta":{"key":"iifname"}},"right":{"set":["@eth0"]}}}`), &deserializedStatement,
+		))
+		iface := "@eth0"
+		assert.Equal(t, []schema.Expression{{String: &iface}}, deserializedStatement.Match.Right.Set)
+	})
+}
@@ -0,0 +1,98 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+// EgressRateCap caps the rate of the selected egress traffic:
+// Either the packets marked with the mark or the packets to the destination network.
+type EgressRateCap struct {
+	// Name is the name of the limit object which holds the rate.
+	Name        string
+	Rate        int
+	Unit        LimitUnit
+	Mark        *int
+	Destination *net.IPNet
+}
+
+// AddEgressRateCap adds to the config a limit object, matching the traffic over the cap rate,
+// and a rule to the (egress) chain which drops the selected traffic over the rate.
+// The rate can be changed at runtime using UpdateEgressRateCap, without touching the rule.
+func (c *Config) AddEgressRateCap(chain *schema.Chain, rateCap EgressRateCap) error {
+	match, err := rateCap.match(chain.Family)
+	if err != nil {
+		return err
+	}
+
+	table := &schema.Table{Family: chain.Family, Name: chain.Table}
+	limit := rateCap.limit(table)
+	c.AddLimit(limit)
+	c.AddRule(NewRule(table, chain, []schema.Statement{
+		match,
+		NewLimitReference(limit),
+		{Verdict: schema.Drop()},
+	}, nil, nil, ""))
+	return nil
+}
+
+// UpdateEgressRateCap appends to the config an update of the cap rate (see UpdateLimit).
+func (c *Config) UpdateEgressRateCap(table *schema.Table, rateCap EgressRateCap) {
+	c.UpdateLimit(rateCap.limit(table))
+}
+
+func (r *EgressRateCap) limit(table *schema.Table) *schema.NamedLimit {
+	return NewNamedLimit(table, r.Name, r.Rate, r.Unit, LimitPerSecond, true)
+}
+
+func (r *EgressRateCap) match(family string) (schema.Statement, error) {
+	switch {
+	case r.Mark != nil && r.Destination == nil:
+		mark := float64(*r.Mark)
+		return schema.Statement{Match: &schema.Match{
+			Op:    schema.OperEQ,
+			Left:  schema.Expression{Meta: &schema.Meta{Key: schema.MetaKeyMark}},
+			Right: schema.Expression{Float64: &mark},
+		}}, nil
+	case r.Destination != nil && r.Mark == nil:
+		protocol := schema.PayloadProtocolIP6
+		if r.Destination.IP.To4() != nil {
+			protocol = schema.PayloadProtocolIP4
+		}
+		prefix, err := prefixExpression(r.Destination)
+		if err != nil {
+			return schema.Statement{}, err
+		}
+		statement := schema.Statement{Match: &schema.Match{
+			Op:    schema.OperEQ,
+			Left:  schema.Expression{Payload: &schema.Payload{Protocol: protocol, Field: schema.PayloadFieldIPDAddr}},
+			Right: prefix,
+		}}
+		if !isProtocolSupportedByFamily(protocol, family) {
+			return schema.Statement{}, fmt.Errorf("rate cap %q: destination %s is not supported in the %s family", r.Name, r.Destination, family)
+		}
+		return statement, nil
+	}
+	return schema.Statement{}, fmt.Errorf("rate cap %q: expecting either a mark or a destination", r.Name)
+}
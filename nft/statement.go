@@ -0,0 +1,121 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft
+
+import (
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+// L4Protocol is an IP protocol number, as matched by the layer 4 protocol (`meta l4proto`).
+// The protocols are encoded by number, which unlike the protocol names, does not depend on the host protocols database.
+// nft lists the protocols by name (e.g. `meta l4proto tcp`) unless run in numeric mode, therefore the comparisons
+// (e.g. Diff, Equal and LookupRule) normalize the listed names to numbers (see NormalizeStatements).
+type L4Protocol uint8
+
+// Layer 4 Protocols
+const (
+	L4ProtocolICMP   L4Protocol = 1
+	L4ProtocolIGMP   L4Protocol = 2
+	L4ProtocolTCP    L4Protocol = 6
+	L4ProtocolUDP    L4Protocol = 17
+	L4ProtocolGRE    L4Protocol = 47
+	L4ProtocolESP    L4Protocol = 50
+	L4ProtocolAH     L4Protocol = 51
+	L4ProtocolICMPv6 L4Protocol = 58
+	L4ProtocolSCTP   L4Protocol = 132
+)
+
+// Expression returns the expression of the protocol number.
+func (p L4Protocol) Expression() schema.Expression {
+	number := float64(p)
+	return schema.Expression{Float64: &number}
+}
+
+// Accept returns an accept verdict statement.
+func Accept() schema.Statement {
+	return schema.Statement{Verdict: schema.Accept()}
+}
+
+// Drop returns a drop verdict statement.
+func Drop() schema.Statement {
+	return schema.Statement{Verdict: schema.Drop()}
+}
+
+// Return returns a return verdict statement, resuming the evaluation at the calling chain.
+func Return() schema.Statement {
+	return schema.Statement{Verdict: schema.Return()}
+}
+
+// Continue returns a continue verdict statement.
+func Continue() schema.Statement {
+	return schema.Statement{Verdict: schema.Continue()}
+}
+
+// Jump returns a jump verdict statement to the given chain.
+// The evaluation resumes at the calling chain when the target chain does not issue a terminal verdict.
+func Jump(chain *schema.Chain) schema.Statement {
+	return schema.Statement{Verdict: schema.Verdict{Jump: &schema.ToTarget{Target: chain.Name}}}
+}
+
+// Goto returns a goto verdict statement to the given chain.
+// Unlike a jump, the evaluation does not return to the calling chain.
+func Goto(chain *schema.Chain) schema.Statement {
+	return schema.Statement{Verdict: schema.Verdict{Goto: &schema.ToTarget{Target: chain.Name}}}
+}
+
+// MatchProtocol returns a match statement of the packet layer 4 protocol (`meta l4proto`).
+// Unlike the IP header protocol fields, it matches both IPv4 and IPv6 packets (e.g. in the inet family).
+func MatchProtocol(protocol L4Protocol) schema.Statement {
+	return matchMetaNumber(schema.MetaKeyL4Proto, int(protocol))
+}
+
+// MatchIIFName returns a match statement of the input interface name (`meta iifname`).
+func MatchIIFName(name string) schema.Statement {
+	return matchMeta(schema.MetaKeyIIFName, name)
+}
+
+// MatchOIFName returns a match statement of the output interface name (`meta oifname`).
+func MatchOIFName(name string) schema.Statement {
+	return matchMeta(schema.MetaKeyOIFName, name)
+}
+
+// MatchSrcIP returns a match statement of the source address, given as an IP address or a CIDR prefix
+// (e.g. `10.0.0.1` or `2001:db8::/32`).
+// The address is matched in the IP header of its version (`ip saddr` or `ip6 saddr`).
+func MatchSrcIP(address string) (schema.Statement, error) {
+	return matchIP(AddressFieldSource, address)
+}
+
+// MatchDstIP returns a match statement of the destination address, see MatchSrcIP for details.
+func MatchDstIP(address string) (schema.Statement, error) {
+	return matchIP(AddressFieldDestination, address)
+}
+
+func matchIP(field AddressField, address string) (schema.Statement, error) {
+	right, isIPv4, err := addressExpression(address)
+	if err != nil {
+		return schema.Statement{}, err
+	}
+	protocol := schema.PayloadProtocolIP6
+	if isIPv4 {
+		protocol = schema.PayloadProtocolIP4
+	}
+	return addressMatch(protocol, field, right), nil
+}
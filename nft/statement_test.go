@@ -0,0 +1,106 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/networkplumbing/go-nft/nft"
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+func TestStatementConstructors(t *testing.T) {
+	table := nft.NewTable(tableName, nft.FamilyIP)
+	chain := nft.NewRegularChain(table, chainName)
+
+	tests := []struct {
+		name                string
+		statement           schema.Statement
+		serializedStatement string
+	}{
+		{"accept", nft.Accept(), `{"accept":null}`},
+		{"drop", nft.Drop(), `{"drop":null}`},
+		{"return", nft.Return(), `{"return":null}`},
+		{"continue", nft.Continue(), `{"continue":null}`},
+		{"jump", nft.Jump(chain), `{"jump":{"target":"test-chain"}}`},
+		{"goto", nft.Goto(chain), `{"goto":{"target":"test-chain"}}`},
+		{
+			"protocol",
+			nft.MatchProtocol(nft.L4ProtocolTCP),
+			`{"match":{"op":"==","left":{"meta":{"key":"l4proto"}},"right":6}}`,
+		},
+		{
+			"protocol icmpv6",
+			nft.MatchProtocol(nft.L4ProtocolICMPv6),
+			`{"match":{"op":"==","left":{"meta":{"key":"l4proto"}},"right":58}}`,
+		},
+		{
+			"iifname",
+			nft.MatchIIFName("eth0"),
+			`{"match":{"op":"==","left":{"meta":{"key":"iifname"}},"right":"eth0"}}`,
+		},
+		{
+			"oifname",
+			nft.MatchOIFName("eth1"),
+			`{"match":{"op":"==","left":{"meta":{"key":"oifname"}},"right":"eth1"}}`,
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run("Statement "+test.name+", check serialization", func(t *testing.T) {
+			serialized, err := json.Marshal(test.statement)
+			assert.NoError(t, err)
+			assert.Equal(t, test.serializedStatement, string(serialized))
+		})
+	}
+
+	t.Run("Match IPv4 source address", func(t *testing.T) {
+		statement, err := nft.MatchSrcIP("10.0.0.1")
+		assert.NoError(t, err)
+		serialized, err := json.Marshal(statement)
+		assert.NoError(t, err)
+		assert.Equal(t,
+			`{"match":{"op":"==","left":{"payload":{"protocol":"ip","field":"saddr"}},"right":"10.0.0.1"}}`,
+			string(serialized),
+		)
+	})
+
+	t.Run("Match IPv6 destination prefix", func(t *testing.T) {
+		statement, err := nft.MatchDstIP("2001:db8::/32")
+		assert.NoError(t, err)
+		serialized, err := json.Marshal(statement)
+		assert.NoError(t, err)
+		assert.Equal(t,
+			`{"match":{"op":"==","left":{"payload":{"protocol":"ip6","field":"daddr"}},`+
+				`"right":{"prefix":{"addr":"2001:db8::","len":32}}}}`,
+			string(serialized),
+		)
+	})
+
+	t.Run("Invalid address", func(t *testing.T) {
+		_, err := nft.MatchSrcIP("10.0.0")
+		assert.Error(t, err)
+		_, err = nft.MatchDstIP("10.0.0.0/33")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,95 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft
+
+import (
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+type SynproxyFlag string
+
+// Synproxy Flags
+const (
+	SynproxyFlagTimestamp SynproxyFlag = schema.SynproxyFlagTimestamp
+	SynproxyFlagSackPerm  SynproxyFlag = schema.SynproxyFlagSackPerm
+)
+
+// NewNamedSynproxy returns a new schema synproxy object structure, which rules may reference by name.
+// The mss and wscale are the TCP options announced to the clients, which should match the server ones.
+func NewNamedSynproxy(table *schema.Table, name string, mss int, wscale int, flags []SynproxyFlag) *schema.NamedSynproxy {
+	return &schema.NamedSynproxy{
+		Family: table.Family,
+		Table:  table.Name,
+		Name:   name,
+		Mss:    mss,
+		Wscale: wscale,
+		Flags:  synproxyFlags(flags),
+	}
+}
+
+// NewSynproxy returns a synproxy statement with the given TCP options (see NewNamedSynproxy).
+// The statement is commonly applied to the untracked (`ct state invalid,untracked`) SYN and ACK packets,
+// mitigating SYN flood attacks.
+func NewSynproxy(mss int, wscale int, flags []SynproxyFlag) schema.Statement {
+	return schema.Statement{Synproxy: &schema.Synproxy{Mss: mss, Wscale: wscale, Flags: synproxyFlags(flags)}}
+}
+
+// NewSynproxyReference returns a synproxy statement which applies the given named synproxy.
+func NewSynproxyReference(synproxy *schema.NamedSynproxy) schema.Statement {
+	return schema.Statement{Synproxy: &schema.Synproxy{Name: synproxy.Name}}
+}
+
+// AddSynproxy appends the given synproxy object to the nftable config.
+// The synproxy is added without an explicit action (`add`).
+func (c *Config) AddSynproxy(synproxy *schema.NamedSynproxy) {
+	nftable := schema.Nftable{Synproxy: synproxy}
+	c.Nftables = append(c.Nftables, nftable)
+}
+
+// DeleteSynproxy appends a given synproxy object to the nftable config
+// with the `delete` action.
+// Attempting to delete a non-existing synproxy, results with a failure when the config is applied.
+// The synproxy must not be referenced by any rule.
+func (c *Config) DeleteSynproxy(synproxy *schema.NamedSynproxy) {
+	nftable := schema.Nftable{Delete: &schema.Objects{Synproxy: synproxy}}
+	c.Nftables = append(c.Nftables, nftable)
+}
+
+// LookupSynproxy searches the configuration for a matching synproxy object and returns it.
+// The synproxy is matched by the table and synproxy name.
+// Mutating the returned synproxy will result in mutating the configuration.
+func (c *Config) LookupSynproxy(toFind *schema.NamedSynproxy) *schema.NamedSynproxy {
+	for _, nftable := range c.Nftables {
+		if synproxy := nftable.Synproxy; synproxy != nil {
+			if synproxy.Table == toFind.Table && synproxy.Family == toFind.Family && synproxy.Name == toFind.Name {
+				return synproxy
+			}
+		}
+	}
+	return nil
+}
+
+func synproxyFlags(flags []SynproxyFlag) schema.StringList {
+	var list schema.StringList
+	for _, flag := range flags {
+		list = append(list, string(flag))
+	}
+	return list
+}
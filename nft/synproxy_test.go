@@ -0,0 +1,120 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/networkplumbing/go-nft/nft"
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+const synproxyName = "test-synproxy"
+
+func TestSynproxy(t *testing.T) {
+	testSynproxyObjectActions(t)
+	testSynproxyLookup(t)
+	testSynproxyStatement(t)
+}
+
+func testSynproxyObjectActions(t *testing.T) {
+	table := nft.NewTable(tableName, nft.FamilyIP)
+	synproxy := nft.NewNamedSynproxy(table, synproxyName, 1460, 7, []nft.SynproxyFlag{nft.SynproxyFlagTimestamp, nft.SynproxyFlagSackPerm})
+	synproxyArgs := fmt.Sprintf(
+		`"family":"ip","table":%q,"name":%q,"mss":1460,"wscale":7,"flags":["timestamp","sack-perm"]`, tableName, synproxyName,
+	)
+
+	actions := map[string]func(*nft.Config){
+		"add":    func(c *nft.Config) { c.AddSynproxy(synproxy) },
+		"delete": func(c *nft.Config) { c.DeleteSynproxy(synproxy) },
+	}
+	expected := map[string]string{
+		"add":    fmt.Sprintf(`{"nftables":[{"synproxy":{%s}}]}`, synproxyArgs),
+		"delete": fmt.Sprintf(`{"nftables":[{"delete":{"synproxy":{%s}}}]}`, synproxyArgs),
+	}
+
+	for action, actionFunc := range actions {
+		action, actionFunc := action, actionFunc
+		t.Run(fmt.Sprintf("%s synproxy", action), func(t *testing.T) {
+			config := nft.NewConfig()
+			actionFunc(config)
+
+			serialized, err := config.ToJSON()
+			assert.NoError(t, err)
+			assert.Equal(t, expected[action], string(serialized))
+		})
+	}
+
+	t.Run("Read synproxy", func(t *testing.T) {
+		var deserializedConfig nft.Config
+		assert.NoError(t, json.Unmarshal([]byte(expected["add"]), &deserializedConfig))
+
+		expectedConfig := nft.NewConfig()
+		expectedConfig.AddSynproxy(synproxy)
+		assert.Equal(t, expectedConfig, &deserializedConfig)
+	})
+
+	t.Run("Synproxy reference statement, check serialization and deserialization", func(t *testing.T) {
+		statement := nft.NewSynproxyReference(synproxy)
+		serialized, err := json.Marshal(statement)
+		assert.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf(`{"synproxy":%q}`, synproxyName), string(serialized))
+
+		var deserializedStatement schema.Statement
+		assert.NoError(t, json.Unmarshal(serialized, &deserializedStatement))
+		assert.Equal(t, statement, deserializedStatement)
+	})
+}
+
+func testSynproxyLookup(t *testing.T) {
+	config := nft.NewConfig()
+	table := nft.NewTable(tableName, nft.FamilyIP)
+	synproxy := nft.NewNamedSynproxy(table, synproxyName, 1460, 7, nil)
+	config.AddSynproxy(synproxy)
+
+	t.Run("Lookup an existing synproxy", func(t *testing.T) {
+		assert.Equal(t, synproxy, config.LookupSynproxy(&schema.NamedSynproxy{Family: table.Family, Table: table.Name, Name: synproxyName}))
+	})
+
+	t.Run("Lookup a missing synproxy", func(t *testing.T) {
+		assert.Nil(t, config.LookupSynproxy(&schema.NamedSynproxy{Family: table.Family, Table: table.Name, Name: "synproxy-na"}))
+	})
+}
+
+func testSynproxyStatement(t *testing.T) {
+	const serializedStatement = `{"synproxy":{"mss":1460,"wscale":7,"flags":"timestamp"}}`
+	statement := nft.NewSynproxy(1460, 7, []nft.SynproxyFlag{nft.SynproxyFlagTimestamp})
+
+	t.Run("Synproxy statement, check serialization", func(t *testing.T) {
+		serialized, err := json.Marshal(statement)
+		assert.NoError(t, err)
+		assert.Equal(t, serializedStatement, string(serialized))
+	})
+
+	t.Run("Synproxy statement, check deserialization", func(t *testing.T) {
+		var deserializedStatement schema.Statement
+		assert.NoError(t, json.Unmarshal([]byte(serializedStatement), &deserializedStatement))
+		assert.Equal(t, statement, deserializedStatement)
+	})
+}
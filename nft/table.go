@@ -19,7 +19,12 @@
 
 package nft
 
-import "github.com/networkplumbing/go-nft/nft/schema"
+import (
+	"fmt"
+	"strings"
+
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
 
 type AddressFamily string
 
@@ -37,9 +42,11 @@ type TableAction string
 
 // Table Actions
 const (
-	TableADD    TableAction = "add"
-	TableDELETE TableAction = "delete"
-	TableFLUSH  TableAction = "flush"
+	TableADD     TableAction = "add"
+	TableCREATE  TableAction = "create"
+	TableDELETE  TableAction = "delete"
+	TableFLUSH   TableAction = "flush"
+	TableDESTROY TableAction = "destroy"
 )
 
 // NewTable returns a new schema table structure.
@@ -58,6 +65,14 @@ func (c *Config) AddTable(table *schema.Table) {
 	c.Nftables = append(c.Nftables, nftable)
 }
 
+// CreateTable appends the given table to the nftable config with the `create` action.
+// Unlike adding, creating a table which already exists results with a failure when the config is applied.
+// It is commonly used to detect a table owned by another tool or instance.
+func (c *Config) CreateTable(table *schema.Table) {
+	nftable := schema.Nftable{Create: &schema.Objects{Table: table}}
+	c.Nftables = append(c.Nftables, nftable)
+}
+
 // DeleteTable appends a given table to the nftable config
 // with the `delete` action.
 // Attempting to delete a non-existing table, results with a failure when the config is applied.
@@ -67,6 +82,26 @@ func (c *Config) DeleteTable(table *schema.Table) {
 	c.Nftables = append(c.Nftables, nftable)
 }
 
+// DeleteTableByHandle appends a given table to the nftable config
+// with the `delete` action, identifying the table strictly by its handle (ignoring its name).
+// The handle is commonly found from a listed table (e.g. using LookupTable on the ReadConfig result).
+// An error is returned when the table has no handle.
+func (c *Config) DeleteTableByHandle(table *schema.Table) error {
+	if table.Handle == nil {
+		return fmt.Errorf("table %s %s has no handle", table.Family, table.Name)
+	}
+	c.DeleteTable(&schema.Table{Family: table.Family, Handle: table.Handle})
+	return nil
+}
+
+// DestroyTable appends a given table to the nftable config with the `destroy` action.
+// Unlike deleting, destroying a non-existing table has no effect when the config is applied.
+// All chains and rules under the table are removed as well (when applied).
+func (c *Config) DestroyTable(table *schema.Table) {
+	nftable := schema.Nftable{Destroy: &schema.Objects{Table: table}}
+	c.Nftables = append(c.Nftables, nftable)
+}
+
 // FlushTable appends a given table to the nftable config
 // with the `flush` action.
 // All chains and rules under the table are removed (when applied).
@@ -76,6 +111,84 @@ func (c *Config) FlushTable(table *schema.Table) {
 	c.Nftables = append(c.Nftables, nftable)
 }
 
+// SetTableDormant appends the given table to the nftable config with the dormant flag.
+// When applied, the table is not evaluated anymore (it is disabled), while its chains, rules and objects are kept.
+// The table in the config is a copy, the given table is not modified.
+// Any other flag of the given table is kept.
+func (c *Config) SetTableDormant(table *schema.Table) {
+	dormant := *table
+	dormant.Flags = append(tableFlagsWithout(table.Flags, schema.TableFlagDormant), schema.TableFlagDormant)
+	c.AddTable(&dormant)
+}
+
+// ClearTableDormant appends the given table to the nftable config without the dormant flag.
+// When applied, a dormant table is evaluated again (it is enabled).
+// The table in the config is a copy, the given table is not modified.
+// Any other flag of the given table is kept.
+func (c *Config) ClearTableDormant(table *schema.Table) {
+	active := *table
+	active.Flags = tableFlagsWithout(table.Flags, schema.TableFlagDormant)
+	c.AddTable(&active)
+}
+
+// IsTableDormant reports if the given table has the dormant flag, commonly for a table read from the system.
+func IsTableDormant(table *schema.Table) bool {
+	for _, flag := range table.Flags {
+		if flag == schema.TableFlagDormant {
+			return true
+		}
+	}
+	return false
+}
+
+func tableFlagsWithout(flags schema.StringList, toRemove string) schema.StringList {
+	var list schema.StringList
+	for _, flag := range flags {
+		if flag != toRemove {
+			list = append(list, flag)
+		}
+	}
+	return list
+}
+
+// DeleteTablesMatching appends to the nftable config a `delete` command for each table
+// found in the current config (commonly read from the system using ReadConfig) which satisfies the predicate.
+// All chains and rules under the deleted tables are removed as well (when applied).
+func (c *Config) DeleteTablesMatching(current *Config, predicate func(*schema.Table) bool) {
+	for _, nftable := range current.Nftables {
+		if table := nftable.Table; table != nil && predicate(table) {
+			c.DeleteTable(table)
+		}
+	}
+}
+
+// EnsureTable appends the given table to the nftable config, unless an equivalent table is already
+// appended to it (with the same name, family and attributes).
+// It allows idempotent config builders (e.g. reconcilers), which do not generate duplicate entries.
+// It reports if the table has been appended.
+func (c *Config) EnsureTable(table *schema.Table) bool {
+	if c.hasEquivalentObject(schema.Nftable{Table: table}) {
+		return false
+	}
+	c.AddTable(table)
+	return true
+}
+
+// RemoveTable removes a previously appended table from the nftable config, together with the objects
+// (e.g. chains, rules and sets) which are appended to the table, it does not affect the system.
+// Entries with an explicit action (e.g. a table deletion) are not removed.
+// It reports if the table has been found and removed.
+func (c *Config) RemoveTable(table *schema.Table) bool {
+	removed := c.removeEntries(func(nftable schema.Nftable) bool {
+		id, isObject := objectIdentity(nftable)
+		if rule := nftable.Rule; rule != nil {
+			id, isObject = ruleChainID(rule), true
+		}
+		return isObject && id.family == table.Family && id.table == table.Name
+	})
+	return removed > 0
+}
+
 // LookupTable searches the configuration for a matching table and returns it.
 // Mutating the returned table will result in mutating the configuration.
 func (c *Config) LookupTable(toFind *schema.Table) *schema.Table {
@@ -88,3 +201,59 @@ func (c *Config) LookupTable(toFind *schema.Table) *schema.Table {
 	}
 	return nil
 }
+
+// LookupTables searches the configuration for the matching tables and returns them.
+// Unlike LookupTable, an empty family or name matches any,
+// e.g. all the tables with a given name are matched regardless of their family.
+// Mutating the returned tables will result in mutating the configuration.
+func (c *Config) LookupTables(toFind *schema.Table) []*schema.Table {
+	var tables []*schema.Table
+	for _, nftable := range c.Nftables {
+		if t := nftable.Table; t != nil && isWildcardMatch(toFind.Name, t.Name) && isWildcardMatch(toFind.Family, t.Family) {
+			tables = append(tables, t)
+		}
+	}
+	return tables
+}
+
+// isWildcardMatch reports if the value matches the pattern, an empty pattern matches any value.
+func isWildcardMatch(pattern, value string) bool {
+	return pattern == "" || pattern == value
+}
+
+// checkTableDeclared verifies that a table with the given family and name is declared in the config,
+// either added, created or flushed.
+// When missing, the returned error mentions tables with the same name in other families,
+// as mixing families is a common mistake.
+func (c *Config) checkTableDeclared(family string, name string) error {
+	var otherFamilies []string
+	for _, nftable := range c.Nftables {
+		tables := []*schema.Table{nftable.Table}
+		if nftable.Add != nil {
+			tables = append(tables, nftable.Add.Table)
+		}
+		if nftable.Create != nil {
+			tables = append(tables, nftable.Create.Table)
+		}
+		if nftable.Flush != nil {
+			tables = append(tables, nftable.Flush.Table)
+		}
+		for _, t := range tables {
+			if t == nil || t.Name != name {
+				continue
+			}
+			if t.Family == family {
+				return nil
+			}
+			otherFamilies = append(otherFamilies, t.Family)
+		}
+	}
+
+	if len(otherFamilies) > 0 {
+		return fmt.Errorf(
+			"table %s %s is not declared in the config, found it in family: %s",
+			family, name, strings.Join(otherFamilies, ", "),
+		)
+	}
+	return fmt.Errorf("table %s %s is not declared in the config", family, name)
+}
@@ -21,6 +21,7 @@ package nft_test
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	assert "github.com/stretchr/testify/require"
@@ -36,13 +37,20 @@ const tableName = "test-table"
 func TestTable(t *testing.T) {
 	testTableActions(t)
 	testTableLookup(t)
+	testTablesWildcardLookup(t)
+	testDeleteTablesMatching(t)
+	testTableDormant(t)
+	testRemoveTable(t)
+	testEnsureTable(t)
 }
 
 func testTableActions(t *testing.T) {
 	actions := map[nft.TableAction]tableActionFunc{
-		nft.TableADD:    func(c *nft.Config, t *schema.Table) { c.AddTable(t) },
-		nft.TableDELETE: func(c *nft.Config, t *schema.Table) { c.DeleteTable(t) },
-		nft.TableFLUSH:  func(c *nft.Config, t *schema.Table) { c.FlushTable(t) },
+		nft.TableADD:     func(c *nft.Config, t *schema.Table) { c.AddTable(t) },
+		nft.TableDELETE:  func(c *nft.Config, t *schema.Table) { c.DeleteTable(t) },
+		nft.TableFLUSH:   func(c *nft.Config, t *schema.Table) { c.FlushTable(t) },
+		nft.TableCREATE:  func(c *nft.Config, t *schema.Table) { c.CreateTable(t) },
+		nft.TableDESTROY: func(c *nft.Config, t *schema.Table) { c.DestroyTable(t) },
 	}
 	families := []nft.AddressFamily{
 		nft.FamilyIP,
@@ -98,3 +106,166 @@ func testTableLookup(t *testing.T) {
 		assert.Nil(t, table)
 	})
 }
+
+func testDeleteTablesMatching(t *testing.T) {
+	t.Run("Delete tables matching a predicate", func(t *testing.T) {
+		current := nft.NewConfig()
+		current.AddTable(nft.NewTable("app-ip", nft.FamilyIP))
+		current.AddTable(nft.NewTable("other", nft.FamilyIP))
+		current.AddTable(nft.NewTable("app-ip6", nft.FamilyIP6))
+
+		config := nft.NewConfig()
+		config.DeleteTablesMatching(current, func(table *schema.Table) bool {
+			return strings.HasPrefix(table.Name, "app-")
+		})
+
+		serializedConfig, err := config.ToJSON()
+		assert.NoError(t, err)
+
+		expected := `{"nftables":[` +
+			`{"delete":{"table":{"family":"ip","name":"app-ip"}}},{"delete":{"table":{"family":"ip6","name":"app-ip6"}}}` +
+			`]}`
+		assert.Equal(t, expected, string(serializedConfig))
+	})
+}
+
+func testTableDormant(t *testing.T) {
+	table := nft.NewTable(tableName, nft.FamilyINET)
+
+	t.Run("Set table dormant", func(t *testing.T) {
+		config := nft.NewConfig()
+		config.SetTableDormant(table)
+
+		serializedConfig, err := config.ToJSON()
+		assert.NoError(t, err)
+		assert.Equal(t, `{"nftables":[{"table":{"family":"inet","name":"test-table","flags":"dormant"}}]}`, string(serializedConfig))
+		assert.True(t, nft.IsTableDormant(config.Nftables[0].Table))
+		assert.Nil(t, table.Flags)
+	})
+
+	t.Run("Clear table dormant", func(t *testing.T) {
+		dormantTable := &schema.Table{
+			Family: schema.FamilyINET,
+			Name:   tableName,
+			Flags:  schema.StringList{schema.TableFlagOwner, schema.TableFlagDormant},
+		}
+		config := nft.NewConfig()
+		config.ClearTableDormant(dormantTable)
+
+		serializedConfig, err := config.ToJSON()
+		assert.NoError(t, err)
+		assert.Equal(t, `{"nftables":[{"table":{"family":"inet","name":"test-table","flags":"owner"}}]}`, string(serializedConfig))
+		assert.False(t, nft.IsTableDormant(config.Nftables[0].Table))
+		assert.True(t, nft.IsTableDormant(dormantTable))
+	})
+
+	t.Run("Read dormant table", func(t *testing.T) {
+		config := nft.NewConfig()
+		assert.NoError(t, config.FromJSON([]byte(`{"nftables":[{"table":{"family":"ip","name":"test-table","handle":1,"flags":["dormant"]}}]}`)))
+		assert.True(t, nft.IsTableDormant(config.Nftables[0].Table))
+	})
+}
+
+func TestDeleteByHandle(t *testing.T) {
+	const listedConfig = `{"nftables":[` +
+		`{"table":{"family":"ip","name":"test-table","handle":1}},` +
+		`{"chain":{"family":"ip","table":"test-table","name":"test-chain","handle":2}},` +
+		`{"set":{"family":"ip","table":"test-table","name":"test-set","handle":3,"type":"ipv4_addr"}}` +
+		`]}`
+	current := nft.NewConfig()
+	assert.NoError(t, current.FromJSON([]byte(listedConfig)))
+
+	table := current.LookupTable(nft.NewTable(tableName, nft.FamilyIP))
+	chain := current.LookupChain(nft.NewRegularChain(table, chainName))
+	set := current.LookupSet(&schema.Set{Family: table.Family, Table: table.Name, Name: setName})
+
+	t.Run("Delete table, chain and set by handle", func(t *testing.T) {
+		config := nft.NewConfig()
+		assert.NoError(t, config.DeleteSetByHandle(set))
+		assert.NoError(t, config.DeleteChainByHandle(chain))
+		assert.NoError(t, config.DeleteTableByHandle(table))
+
+		serialized, err := config.ToJSON()
+		assert.NoError(t, err)
+		expected := `{"nftables":[` +
+			`{"delete":{"set":{"family":"ip","table":"test-table","handle":3}}},` +
+			`{"delete":{"chain":{"family":"ip","table":"test-table","handle":2}}},` +
+			`{"delete":{"table":{"family":"ip","handle":1}}}` +
+			`]}`
+		assert.Equal(t, expected, string(serialized))
+	})
+
+	t.Run("Delete by handle objects with no handle", func(t *testing.T) {
+		config := nft.NewConfig()
+		newTable := nft.NewTable(tableName, nft.FamilyIP)
+		assert.Error(t, config.DeleteTableByHandle(newTable))
+		assert.Error(t, config.DeleteChainByHandle(nft.NewRegularChain(newTable, chainName)))
+		assert.Error(t, config.DeleteSetByHandle(nft.NewSet(newTable, setName, nft.SetTypeIPv4Addr, nil, nil)))
+		assert.Empty(t, config.Nftables)
+	})
+}
+
+func testTablesWildcardLookup(t *testing.T) {
+	ipTable := nft.NewTable(tableName, nft.FamilyIP)
+	ip6Table := nft.NewTable(tableName, nft.FamilyIP6)
+	otherTable := nft.NewTable("other-table", nft.FamilyIP)
+	config := nft.NewConfig()
+	config.AddTable(ipTable)
+	config.AddTable(ip6Table)
+	config.AddTable(otherTable)
+
+	t.Run("Lookup tables by name in any family", func(t *testing.T) {
+		assert.Equal(t, []*schema.Table{ipTable, ip6Table}, config.LookupTables(&schema.Table{Name: tableName}))
+	})
+
+	t.Run("Lookup tables by family", func(t *testing.T) {
+		assert.Equal(t, []*schema.Table{ipTable, otherTable}, config.LookupTables(&schema.Table{Family: string(nft.FamilyIP)}))
+	})
+
+	t.Run("Lookup a missing table", func(t *testing.T) {
+		assert.Empty(t, config.LookupTables(&schema.Table{Name: "missing-table"}))
+	})
+}
+
+func testRemoveTable(t *testing.T) {
+	table := nft.NewTable(tableName, nft.FamilyIP)
+	otherTable := nft.NewTable(tableName, nft.FamilyIP6)
+	chain := nft.NewRegularChain(table, chainName)
+	config := nft.NewConfig()
+	config.AddTable(table)
+	config.AddChain(chain)
+	config.AddSet(nft.NewSet(table, setName, nft.SetTypeIPv4Addr, nil, nil))
+	config.AddRule(nft.NewRule(table, chain, []schema.Statement{{Verdict: schema.Accept()}}, nil, nil, ""))
+	config.AddTable(otherTable)
+	config.FlushTable(table)
+
+	t.Run("Remove a table", func(t *testing.T) {
+		assert.True(t, config.RemoveTable(table))
+
+		expected := nft.NewConfig()
+		expected.AddTable(otherTable)
+		expected.FlushTable(table)
+		assert.Equal(t, expected, config)
+	})
+
+	t.Run("Remove a missing table", func(t *testing.T) {
+		assert.False(t, config.RemoveTable(table))
+	})
+}
+
+func testEnsureTable(t *testing.T) {
+	config := nft.NewConfig()
+
+	t.Run("Ensure a table", func(t *testing.T) {
+		assert.True(t, config.EnsureTable(nft.NewTable(tableName, nft.FamilyIP)))
+		assert.False(t, config.EnsureTable(nft.NewTable(tableName, nft.FamilyIP)))
+		assert.Len(t, config.Nftables, 1)
+	})
+
+	t.Run("Ensure a table with different attributes", func(t *testing.T) {
+		table := nft.NewTable(tableName, nft.FamilyIP)
+		table.Comment = "changed"
+		assert.True(t, config.EnsureTable(table))
+		assert.Len(t, config.Nftables, 2)
+	})
+}
@@ -0,0 +1,129 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft
+
+import (
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+// TCPFlags is a bitmask of TCP header flags.
+type TCPFlags uint8
+
+// TCP Flags
+const (
+	TCPFlagFIN TCPFlags = 1 << iota
+	TCPFlagSYN
+	TCPFlagRST
+	TCPFlagPSH
+	TCPFlagACK
+	TCPFlagURG
+	TCPFlagECN
+	TCPFlagCWR
+)
+
+var tcpFlagNames = [...]string{"fin", "syn", "rst", "psh", "ack", "urg", "ecn", "cwr"}
+
+// Names returns the nft names of the flags which are set in the bitmask.
+func (f TCPFlags) Names() []string {
+	var names []string
+	for i, name := range tcpFlagNames {
+		if f&(1<<i) != 0 {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Expression returns the flags as an expression.
+// A single flag is represented by its name, multiple flags are combined with a binary OR
+// (e.g. `syn|ack`) and no flags are represented by the value 0.
+func (f TCPFlags) Expression() schema.Expression {
+	names := f.Names()
+	if len(names) == 0 {
+		zero := float64(0)
+		return schema.Expression{Float64: &zero}
+	}
+
+	expression := schema.Expression{String: &names[0]}
+	for i := 1; i < len(names); i++ {
+		expression = schema.Expression{Or: []schema.Expression{expression, {String: &names[i]}}}
+	}
+	return expression
+}
+
+// MatchTCPFlags returns a match statement which tests the TCP flags, under the given mask, to equal the given flags.
+// For example, `MatchTCPFlags(TCPFlagSYN, TCPFlagSYN|TCPFlagACK)` matches `tcp flags & (syn|ack) == syn`.
+// A zero mask tests all the flags.
+func MatchTCPFlags(flags TCPFlags, mask TCPFlags) schema.Statement {
+	left := schema.Expression{Payload: &schema.Payload{
+		Protocol: schema.PayloadProtocolTCP,
+		Field:    schema.PayloadFieldTCPFlags,
+	}}
+	if mask != 0 {
+		left = schema.Expression{And: []schema.Expression{left, mask.Expression()}}
+	}
+
+	return schema.Statement{Match: &schema.Match{
+		Op:    schema.OperEQ,
+		Left:  left,
+		Right: flags.Expression(),
+	}}
+}
+
+type TCPOption string
+
+// TCP Options
+const (
+	TCPOptionMaxSeg    TCPOption = schema.TCPOptionMaxSeg
+	TCPOptionWindow    TCPOption = schema.TCPOptionWindow
+	TCPOptionSackPerm  TCPOption = schema.TCPOptionSackPerm
+	TCPOptionSack      TCPOption = schema.TCPOptionSack
+	TCPOptionTimestamp TCPOption = schema.TCPOptionTimestamp
+)
+
+// TCPOptionField returns an expression of the given TCP option field (e.g. `tcp option maxseg size`).
+func TCPOptionField(option TCPOption, field string) schema.Expression {
+	return schema.Expression{TCPOption: &schema.TCPOption{Name: string(option), Field: field}}
+}
+
+// MatchTCPOption returns a match statement which tests the presence of the TCP option (e.g. `tcp option sack-perm exists`).
+func MatchTCPOption(option TCPOption) schema.Statement {
+	exists := true
+	return schema.Statement{Match: &schema.Match{
+		Op:    schema.OperEQ,
+		Left:  TCPOptionField(option, ""),
+		Right: schema.Expression{Bool: &exists},
+	}}
+}
+
+// SetMSS returns a mangle statement setting the TCP maximum segment size (`tcp option maxseg size set <size>`).
+func SetMSS(size int) schema.Statement {
+	return Mangle(TCPOptionField(TCPOptionMaxSeg, schema.TCPOptionFieldSize), size)
+}
+
+// ClampMSSToPMTU returns a mangle statement setting the TCP maximum segment size to the one of the route
+// (`tcp option maxseg size set rt mtu`).
+// It is commonly applied to SYN packets only, see MatchTCPFlags.
+func ClampMSSToPMTU() schema.Statement {
+	return schema.Statement{Mangle: &schema.Mangle{
+		Key:   TCPOptionField(TCPOptionMaxSeg, schema.TCPOptionFieldSize),
+		Value: RtExpression(RtKeyMtu, ""),
+	}}
+}
@@ -0,0 +1,111 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/networkplumbing/go-nft/nft"
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+func TestTCPFlags(t *testing.T) {
+	t.Run("TCP flags names", func(t *testing.T) {
+		assert.Equal(t, []string{"fin", "syn", "ack"}, (nft.TCPFlagSYN | nft.TCPFlagACK | nft.TCPFlagFIN).Names())
+		assert.Empty(t, nft.TCPFlags(0).Names())
+	})
+
+	t.Run("Match TCP flags under a mask, check serialization", func(t *testing.T) {
+		statement := nft.MatchTCPFlags(nft.TCPFlagSYN, nft.TCPFlagSYN|nft.TCPFlagACK)
+
+		serialized, err := json.Marshal(statement)
+		assert.NoError(t, err)
+		expected := `{"match":{"op":"==","left":{"\u0026":[{"payload":{"protocol":"tcp","field":"flags"}},{"|":["syn","ack"]}]},"right":"syn"}}`
+		assert.Equal(t, expected, string(serialized))
+	})
+
+	t.Run("Match TCP flags under a mask, check deserialization", func(t *testing.T) {
+		serialized := `{"match":{"op":"==","left":{"&":[{"payload":{"protocol":"tcp","field":"flags"}},{"|":[{"|":["fin","syn"]},"rst"]}]},"right":0}}`
+
+		var statement schema.Statement
+		assert.NoError(t, json.Unmarshal([]byte(serialized), &statement))
+		assert.Equal(t, nft.MatchTCPFlags(0, nft.TCPFlagFIN|nft.TCPFlagSYN|nft.TCPFlagRST), statement)
+	})
+
+	t.Run("Match TCP flags with no mask", func(t *testing.T) {
+		serialized, err := json.Marshal(nft.MatchTCPFlags(nft.TCPFlagRST, 0))
+		assert.NoError(t, err)
+		assert.Equal(t, `{"match":{"op":"==","left":{"payload":{"protocol":"tcp","field":"flags"}},"right":"rst"}}`, string(serialized))
+	})
+}
+
+func TestTCPOption(t *testing.T) {
+	tests := []struct {
+		name                string
+		statement           schema.Statement
+		serializedStatement string
+	}{
+		{
+			name:                "Match TCP option presence",
+			statement:           nft.MatchTCPOption(nft.TCPOptionSackPerm),
+			serializedStatement: `{"match":{"op":"==","left":{"tcp option":{"name":"sack-perm"}},"right":true}}`,
+		},
+		{
+			name:                "Set MSS",
+			statement:           nft.SetMSS(1400),
+			serializedStatement: `{"mangle":{"key":{"tcp option":{"name":"maxseg","field":"size"}},"value":1400}}`,
+		},
+		{
+			name:                "Clamp MSS to the path MTU",
+			statement:           nft.ClampMSSToPMTU(),
+			serializedStatement: `{"mangle":{"key":{"tcp option":{"name":"maxseg","field":"size"}},"value":{"rt":{"key":"mtu"}}}}`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name+", check serialization", func(t *testing.T) {
+			serialized, err := json.Marshal(test.statement)
+			assert.NoError(t, err)
+			assert.Equal(t, test.serializedStatement, string(serialized))
+		})
+
+		t.Run(test.name+", check deserialization", func(t *testing.T) {
+			var statement schema.Statement
+			assert.NoError(t, json.Unmarshal([]byte(test.serializedStatement), &statement))
+			assert.Equal(t, test.statement, statement)
+		})
+	}
+
+	t.Run("Match TCP option field", func(t *testing.T) {
+		size := float64(1460)
+		statement := schema.Statement{Match: &schema.Match{
+			Op:    schema.OperLS,
+			Left:  nft.TCPOptionField(nft.TCPOptionMaxSeg, schema.TCPOptionFieldSize),
+			Right: schema.Expression{Float64: &size},
+		}}
+		serialized, err := json.Marshal(statement)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"match":{"op":"\u003c","left":{"tcp option":{"name":"maxseg","field":"size"}},"right":1460}}`, string(serialized))
+	})
+}
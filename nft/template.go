@@ -0,0 +1,126 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft
+
+import (
+	"encoding/json"
+
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+// ExpandStrategy defines how a rule template is expanded over multiple values.
+type ExpandStrategy int
+
+const (
+	// ExpandPerValue generates a rule per value, favoring the readability of the ruleset.
+	ExpandPerValue ExpandStrategy = iota
+	// ExpandToSet generates a single rule matching the values through an anonymous set (or verdict map),
+	// favoring the performance of the ruleset.
+	ExpandToSet
+)
+
+// RuleTemplate describes a rule which matches the key against a list of values,
+// such as interface names (`meta iifname`) or addresses (`ip saddr`).
+type RuleTemplate struct {
+	Table *schema.Table
+	Chain *schema.Chain
+	// Key is the left operand which is matched against the values.
+	Key schema.Expression
+	// Statements follow the key match, e.g. a verdict.
+	Statements []schema.Statement
+	Comment    string
+}
+
+// Expand generates the rules which match the template key against the given values,
+// following the selected strategy.
+// No rules are generated when there are no values.
+func (t *RuleTemplate) Expand(values []string, strategy ExpandStrategy) []*schema.Rule {
+	if len(values) == 0 {
+		return nil
+	}
+
+	valueExpressions := make([]schema.Expression, 0, len(values))
+	for i := range values {
+		valueExpressions = append(valueExpressions, schema.Expression{String: &values[i]})
+	}
+
+	if strategy == ExpandToSet {
+		right := AnonymousSet(valueExpressions...)
+		if len(valueExpressions) == 1 {
+			right = valueExpressions[0]
+		}
+		return []*schema.Rule{t.newRule(right)}
+	}
+
+	rules := make([]*schema.Rule, 0, len(valueExpressions))
+	for _, value := range valueExpressions {
+		rules = append(rules, t.newRule(value))
+	}
+	return rules
+}
+
+func (t *RuleTemplate) newRule(right schema.Expression) *schema.Rule {
+	statements := make([]schema.Statement, 0, len(t.Statements)+1)
+	statements = append(statements, schema.Statement{Match: &schema.Match{
+		Op:    schema.OperEQ,
+		Left:  t.Key,
+		Right: right,
+	}})
+	statements = append(statements, t.Statements...)
+	return NewRule(t.Table, t.Chain, statements, nil, nil, t.Comment)
+}
+
+// ExpandVerdicts generates the rules which apply a verdict per value of the template key,
+// following the selected strategy:
+// Either a rule per value with its verdict or a single rule with an anonymous verdict map (vmap).
+// Template statements are not used, as the verdict is the last statement of each rule.
+// Elements are expected to carry a verdict (see NewVerdictMapElement).
+func (t *RuleTemplate) ExpandVerdicts(elements []schema.MapElement, strategy ExpandStrategy) ([]*schema.Rule, error) {
+	if len(elements) == 0 {
+		return nil, nil
+	}
+
+	if strategy == ExpandToSet {
+		mapElements := make([]schema.Expression, 0, len(elements))
+		for _, element := range elements {
+			data, err := json.Marshal(element)
+			if err != nil {
+				return nil, err
+			}
+			mapElements = append(mapElements, schema.Expression{RowData: data})
+		}
+		vmap := schema.Statement{Vmap: &schema.Vmap{Key: t.Key, Data: AnonymousSet(mapElements...)}}
+		return []*schema.Rule{NewRule(t.Table, t.Chain, []schema.Statement{vmap}, nil, nil, t.Comment)}, nil
+	}
+
+	rules := make([]*schema.Rule, 0, len(elements))
+	for _, element := range elements {
+		statements := []schema.Statement{{Match: &schema.Match{
+			Op:    schema.OperEQ,
+			Left:  t.Key,
+			Right: element.Key,
+		}}}
+		if element.Verdict != nil {
+			statements = append(statements, schema.Statement{Verdict: *element.Verdict})
+		}
+		rules = append(rules, NewRule(t.Table, t.Chain, statements, nil, nil, t.Comment))
+	}
+	return rules, nil
+}
@@ -0,0 +1,125 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft_test
+
+import (
+	"fmt"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/networkplumbing/go-nft/nft"
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+func TestRuleTemplate(t *testing.T) {
+	table := nft.NewTable(tableName, nft.FamilyINET)
+	chain := nft.NewRegularChain(table, chainName)
+	template := nft.RuleTemplate{
+		Table:      table,
+		Chain:      chain,
+		Key:        schema.Expression{Meta: &schema.Meta{Key: schema.MetaKeyIIFName}},
+		Statements: []schema.Statement{{Verdict: schema.Accept()}},
+		Comment:    "trusted",
+	}
+	interfaces := []string{"eth0", "eth1"}
+
+	ruleArgs := fmt.Sprintf(`"family":"inet","table":%q,"chain":%q`, tableName, chainName)
+	const iifname = `{"meta":{"key":"iifname"}}`
+
+	t.Run("Expand a template per value", func(t *testing.T) {
+		config := nft.NewConfig()
+		for _, rule := range template.Expand(interfaces, nft.ExpandPerValue) {
+			config.AddRule(rule)
+		}
+
+		serialized, err := config.ToJSON()
+		assert.NoError(t, err)
+		expected := fmt.Sprintf(
+			`{"nftables":[`+
+				`{"rule":{%[1]s,"expr":[{"match":{"op":"==","left":%[2]s,"right":"eth0"}},{"accept":null}],"comment":"trusted"}},`+
+				`{"rule":{%[1]s,"expr":[{"match":{"op":"==","left":%[2]s,"right":"eth1"}},{"accept":null}],"comment":"trusted"}}`+
+				`]}`,
+			ruleArgs, iifname,
+		)
+		assert.Equal(t, expected, string(serialized))
+	})
+
+	t.Run("Expand a template to a set", func(t *testing.T) {
+		rules := template.Expand(interfaces, nft.ExpandToSet)
+		assert.Len(t, rules, 1)
+
+		config := nft.NewConfig()
+		config.AddRule(rules[0])
+		serialized, err := config.ToJSON()
+		assert.NoError(t, err)
+		expected := fmt.Sprintf(
+			`{"nftables":[{"rule":{%s,"expr":[{"match":{"op":"==","left":%s,"right":{"set":["eth0","eth1"]}}},{"accept":null}],"comment":"trusted"}}]}`,
+			ruleArgs, iifname,
+		)
+		assert.Equal(t, expected, string(serialized))
+	})
+
+	t.Run("Expand a template without values", func(t *testing.T) {
+		assert.Empty(t, template.Expand(nil, nft.ExpandToSet))
+	})
+
+	eth0, eth1 := "eth0", "eth1"
+	elements := []schema.MapElement{
+		nft.NewVerdictMapElement(schema.Expression{String: &eth0}, schema.Accept()),
+		nft.NewVerdictMapElement(schema.Expression{String: &eth1}, schema.Verdict{Jump: &schema.ToTarget{Target: "from-eth1"}}),
+	}
+
+	t.Run("Expand verdicts per value", func(t *testing.T) {
+		rules, err := template.ExpandVerdicts(elements, nft.ExpandPerValue)
+		assert.NoError(t, err)
+
+		config := nft.NewConfig()
+		for _, rule := range rules {
+			config.AddRule(rule)
+		}
+		serialized, err := config.ToJSON()
+		assert.NoError(t, err)
+		expected := fmt.Sprintf(
+			`{"nftables":[`+
+				`{"rule":{%[1]s,"expr":[{"match":{"op":"==","left":%[2]s,"right":"eth0"}},{"accept":null}],"comment":"trusted"}},`+
+				`{"rule":{%[1]s,"expr":[{"match":{"op":"==","left":%[2]s,"right":"eth1"}},{"jump":{"target":"from-eth1"}}],"comment":"trusted"}}`+
+				`]}`,
+			ruleArgs, iifname,
+		)
+		assert.Equal(t, expected, string(serialized))
+	})
+
+	t.Run("Expand verdicts to a verdict map", func(t *testing.T) {
+		rules, err := template.ExpandVerdicts(elements, nft.ExpandToSet)
+		assert.NoError(t, err)
+		assert.Len(t, rules, 1)
+
+		config := nft.NewConfig()
+		config.AddRule(rules[0])
+		serialized, err := config.ToJSON()
+		assert.NoError(t, err)
+		expected := fmt.Sprintf(
+			`{"nftables":[{"rule":{%s,"expr":[{"vmap":{"key":%s,"data":{"set":[["eth0",{"accept":null}],["eth1",{"jump":{"target":"from-eth1"}}]]}}}],"comment":"trusted"}}]}`,
+			ruleArgs, iifname,
+		)
+		assert.Equal(t, expected, string(serialized))
+	})
+}
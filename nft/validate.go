@@ -0,0 +1,466 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+// payloadProtocolFamilies lists the address families which support a payload protocol.
+// Protocols which are not listed are supported by all families.
+var payloadProtocolFamilies = map[string][]string{
+	schema.PayloadProtocolIP4: {schema.FamilyIP, schema.FamilyINET, schema.FamilyBridge, schema.FamilyNETDEV},
+	schema.PayloadProtocolIP6: {schema.FamilyIP6, schema.FamilyINET, schema.FamilyBridge, schema.FamilyNETDEV},
+	schema.PayloadProtocolARP: {schema.FamilyARP, schema.FamilyBridge, schema.FamilyNETDEV},
+	schema.PayloadProtocolTCP: {schema.FamilyIP, schema.FamilyIP6, schema.FamilyINET, schema.FamilyBridge, schema.FamilyNETDEV},
+	schema.PayloadProtocolUDP: {schema.FamilyIP, schema.FamilyIP6, schema.FamilyINET, schema.FamilyBridge, schema.FamilyNETDEV},
+	schema.PayloadProtocolTH:  {schema.FamilyIP, schema.FamilyIP6, schema.FamilyINET, schema.FamilyBridge, schema.FamilyNETDEV},
+}
+
+// ValidateRuleFamily verifies that the payload protocols used by the rule statements
+// are compatible with the rule address family.
+// For example, ip6 fields are not supported in ip family rules and arp fields are not supported
+// in ip, ip6 and inet family rules.
+// Reject statements are validated as well (see ValidateReject) and fwd statements are limited to the netdev family.
+// The returned error lists each offending statement by its index.
+func ValidateRuleFamily(rule *schema.Rule) error {
+	var violations []string
+	for i, statement := range rule.Expr {
+		protocols, err := statementPayloadProtocols(statement)
+		if err != nil {
+			return fmt.Errorf("statement %d: %v", i, err)
+		}
+		for _, protocol := range protocols {
+			if !isProtocolSupportedByFamily(protocol, rule.Family) {
+				violations = append(violations, fmt.Sprintf(
+					"statement %d: payload protocol %q is not supported in the %s family", i, protocol, rule.Family,
+				))
+			}
+		}
+		if statement.Reject != nil {
+			if err := ValidateReject(rule.Family, statement.Reject); err != nil {
+				violations = append(violations, fmt.Sprintf("statement %d: %v", i, err))
+			}
+		}
+		if statement.Fwd != nil && rule.Family != schema.FamilyNETDEV {
+			violations = append(violations, fmt.Sprintf(
+				"statement %d: fwd is not supported in the %s family", i, rule.Family,
+			))
+		}
+	}
+
+	if len(violations) > 0 {
+		return fmt.Errorf("rule in chain %q: %s", rule.Chain, strings.Join(violations, "; "))
+	}
+	return nil
+}
+
+// familyHooks lists the chain hooks which are supported by each address family.
+var familyHooks = map[string][]string{
+	schema.FamilyIP: {
+		schema.HookPreRouting, schema.HookInput, schema.HookForward, schema.HookOutput, schema.HookPostRouting,
+	},
+	schema.FamilyIP6: {
+		schema.HookPreRouting, schema.HookInput, schema.HookForward, schema.HookOutput, schema.HookPostRouting,
+	},
+	schema.FamilyINET: {
+		schema.HookPreRouting, schema.HookInput, schema.HookForward, schema.HookOutput, schema.HookPostRouting,
+		schema.HookIngress,
+	},
+	schema.FamilyARP: {schema.HookInput, schema.HookOutput},
+	schema.FamilyBridge: {
+		schema.HookPreRouting, schema.HookInput, schema.HookForward, schema.HookOutput, schema.HookPostRouting,
+	},
+	schema.FamilyNETDEV: {schema.HookIngress, schema.HookEgress},
+}
+
+// Validate verifies the structural correctness of the config, before it is applied.
+// The config is expected to declare all the objects it uses, as the following is verified:
+//   - Base chains specify their type, hook and priority, the hook is supported by the chain family.
+//   - Rules (including the added and inserted ones) belong to chains which are declared in the config
+//     (including the added and created ones) and jump (or goto) to declared chains.
+//   - Rules reference declared sets and maps (see ValidateSetReferences).
+//   - Rule statements are compatible with the rule family (see ValidateRuleFamily).
+//   - Verdict statements (including verdict map lookups) are the last statement of their rule.
+//   - NAT statements (snat, dnat and redirect) are not used in base chains of other types than nat.
+//
+// The returned error lists all the violations, each with its context.
+func (c *Config) Validate() error {
+	chains := map[string]*schema.Chain{}
+	var violations []string
+	for _, nftable := range c.Nftables {
+		for _, object := range []schema.Nftable{nftable, objectsEntry(nftable.Add), objectsEntry(nftable.Create)} {
+			if chain := object.Chain; chain != nil {
+				chains[setKey(chain.Family, chain.Table, chain.Name)] = chain
+				violations = append(violations, baseChainViolations(chain)...)
+			}
+		}
+	}
+
+	for _, nftable := range c.Nftables {
+		rule := addedRule(nftable)
+		if rule == nil {
+			continue
+		}
+		chain, declared := chains[setKey(rule.Family, rule.Table, rule.Chain)]
+		if !declared {
+			violations = append(violations, fmt.Sprintf("rule in chain %q: chain is not declared in table %q", rule.Chain, rule.Table))
+		}
+		for i, statement := range rule.Expr {
+			for _, target := range []*schema.ToTarget{statement.Jump, statement.Goto} {
+				if target == nil {
+					continue
+				}
+				if _, exists := chains[setKey(rule.Family, rule.Table, target.Target)]; !exists {
+					violations = append(violations, fmt.Sprintf(
+						"rule in chain %q: statement %d: target chain %q is not declared in table %q", rule.Chain, i, target.Target, rule.Table,
+					))
+				}
+			}
+			if isVerdictStatement(statement) && i < len(rule.Expr)-1 {
+				violations = append(violations, fmt.Sprintf("rule in chain %q: statement %d: verdict is not the last statement", rule.Chain, i))
+			}
+			isNAT := statement.Snat != nil || statement.Dnat != nil || statement.Redirect != nil
+			if isNAT && chain != nil && chain.Type != "" && chain.Type != schema.TypeNAT {
+				violations = append(violations, fmt.Sprintf(
+					"rule in chain %q: statement %d: NAT is not supported in a %s chain", rule.Chain, i, chain.Type,
+				))
+			}
+		}
+		if err := ValidateRuleFamily(rule); err != nil {
+			violations = append(violations, err.Error())
+		}
+	}
+
+	setViolations, err := c.setReferenceViolations()
+	if err != nil {
+		return err
+	}
+	violations = append(violations, setViolations...)
+
+	if len(violations) > 0 {
+		return fmt.Errorf("%s", strings.Join(violations, "; "))
+	}
+	return nil
+}
+
+func baseChainViolations(chain *schema.Chain) []string {
+	hasPrio := chain.Prio != nil || chain.PrioName != ""
+	if chain.Type == "" && chain.Hook == "" && !hasPrio {
+		return nil
+	}
+
+	var violations []string
+	if chain.Type == "" || chain.Hook == "" || !hasPrio {
+		violations = append(violations, fmt.Sprintf("base chain %q in table %q: type, hook and priority are required", chain.Name, chain.Table))
+	}
+	if chain.Hook != "" && !containsString(familyHooks[chain.Family], chain.Hook) {
+		violations = append(violations, fmt.Sprintf(
+			"base chain %q in table %q: hook %q is not supported in the %s family", chain.Name, chain.Table, chain.Hook, chain.Family,
+		))
+	}
+	return violations
+}
+
+func isVerdictStatement(statement schema.Statement) bool {
+	verdict := statement.Verdict
+	return verdict.Accept || verdict.Continue || verdict.Drop || verdict.Return ||
+		verdict.Jump != nil || verdict.Goto != nil || statement.Vmap != nil
+}
+
+// ValidateSetReferences verifies that the named sets and maps referenced by the config rules
+// (e.g. `ip saddr @blocked`) are declared in the config, in the table of the referencing rule.
+// The returned error lists each offending rule statement by its chain and index.
+func (c *Config) ValidateSetReferences() error {
+	violations, err := c.setReferenceViolations()
+	if err != nil {
+		return err
+	}
+	if len(violations) > 0 {
+		return fmt.Errorf("%s", strings.Join(violations, "; "))
+	}
+	return nil
+}
+
+func (c *Config) setReferenceViolations() ([]string, error) {
+	declared := map[string]struct{}{}
+	for _, nftable := range c.Nftables {
+		for _, object := range []schema.Nftable{nftable, objectsEntry(nftable.Add), objectsEntry(nftable.Create)} {
+			switch {
+			case object.Set != nil:
+				declared[setKey(object.Set.Family, object.Set.Table, object.Set.Name)] = struct{}{}
+			case object.Map != nil:
+				declared[setKey(object.Map.Family, object.Map.Table, object.Map.Name)] = struct{}{}
+			}
+		}
+	}
+
+	var violations []string
+	for _, nftable := range c.Nftables {
+		rule := addedRule(nftable)
+		if rule == nil {
+			continue
+		}
+		for i, statement := range rule.Expr {
+			references, err := statementSetReferences(statement)
+			if err != nil {
+				return nil, fmt.Errorf("rule in chain %q: statement %d: %v", rule.Chain, i, err)
+			}
+			for _, name := range references {
+				if _, exists := declared[setKey(rule.Family, rule.Table, name)]; !exists {
+					violations = append(violations, fmt.Sprintf(
+						"rule in chain %q: statement %d: set %q is not declared in table %q", rule.Chain, i, name, rule.Table,
+					))
+				}
+			}
+		}
+	}
+
+	return violations, nil
+}
+
+// CheckReferences verifies that the references of the config rules (including the added and inserted ones)
+// resolve to declared objects:
+// the jump and goto target chains, the named sets and maps (e.g. `@blocked`) and the named stateful objects
+// (counters, limits, quotas, synproxies, ct helpers and ct timeouts).
+// References are resolved in the table of the referencing rule, against the objects which are declared
+// by the config (including the added and created ones) and by the existing config, when given.
+// The existing config is commonly the live ruleset, read from the system using ReadConfig.
+// The returned error lists each dangling reference, by the rule chain and the statement index.
+func (c *Config) CheckReferences(existing *Config) error {
+	declared := map[objectID]bool{}
+	for _, config := range []*Config{c, existing} {
+		if config == nil {
+			continue
+		}
+		for _, nftable := range config.Nftables {
+			for _, object := range []schema.Nftable{nftable, objectsEntry(nftable.Add), objectsEntry(nftable.Create)} {
+				if id, isObject := objectIdentity(object); isObject {
+					declared[id] = true
+				}
+			}
+		}
+	}
+
+	var violations []string
+	for _, nftable := range c.Nftables {
+		rule := addedRule(nftable)
+		if rule == nil {
+			continue
+		}
+		for i, statement := range rule.Expr {
+			references, err := statementReferences(statement)
+			if err != nil {
+				return fmt.Errorf("rule in chain %q: statement %d: %v", rule.Chain, i, err)
+			}
+			for _, reference := range references {
+				id := objectID{kind: reference.kind, family: rule.Family, table: rule.Table, name: reference.name}
+				if reference.kind == kindSet {
+					mapID := id
+					mapID.kind = kindMap
+					if declared[mapID] {
+						continue
+					}
+				}
+				if !declared[id] {
+					violations = append(violations, fmt.Sprintf(
+						"rule in chain %q: statement %d: %s %q is not declared in table %q", rule.Chain, i, reference.kind, reference.name, rule.Table,
+					))
+				}
+			}
+		}
+	}
+
+	if len(violations) > 0 {
+		return fmt.Errorf("%s", strings.Join(violations, "; "))
+	}
+	return nil
+}
+
+// objectsEntry returns an entry holding the objects of an action, to be identified as a declared object.
+func objectsEntry(objects *schema.Objects) schema.Nftable {
+	if objects == nil {
+		return schema.Nftable{}
+	}
+	return schema.Nftable{
+		Table:     objects.Table,
+		Chain:     objects.Chain,
+		Set:       objects.Set,
+		Map:       objects.Map,
+		Flowtable: objects.Flowtable,
+		Counter:   objects.Counter,
+		Limit:     objects.Limit,
+		Quota:     objects.Quota,
+		Synproxy:  objects.Synproxy,
+		CtHelper:  objects.CtHelper,
+		CtTimeout: objects.CtTimeout,
+	}
+}
+
+// objectReference is a reference to a named object, by its kind and name.
+// Set references are resolved to either a set or a map.
+type objectReference struct {
+	kind, name string
+}
+
+// statementReferences returns the references of the statement to named objects.
+func statementReferences(statement schema.Statement) ([]objectReference, error) {
+	var references []objectReference
+	if statement.Jump != nil {
+		references = append(references, objectReference{kindChain, statement.Jump.Target})
+	}
+	if statement.Goto != nil {
+		references = append(references, objectReference{kindChain, statement.Goto.Target})
+	}
+	if statement.Counter != nil && statement.Counter.Name != "" {
+		references = append(references, objectReference{kindCounter, statement.Counter.Name})
+	}
+	if statement.Limit != nil && statement.Limit.Name != "" {
+		references = append(references, objectReference{kindLimit, statement.Limit.Name})
+	}
+	if statement.Quota != nil && statement.Quota.Name != "" {
+		references = append(references, objectReference{kindQuota, statement.Quota.Name})
+	}
+	if statement.Synproxy != nil && statement.Synproxy.Name != "" {
+		references = append(references, objectReference{kindSynproxy, statement.Synproxy.Name})
+	}
+	if statement.CtHelper != "" {
+		references = append(references, objectReference{kindCtHelper, statement.CtHelper})
+	}
+	if statement.CtTimeout != "" {
+		references = append(references, objectReference{kindCtTimeout, statement.CtTimeout})
+	}
+
+	setNames, err := statementSetReferences(statement)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range setNames {
+		references = append(references, objectReference{kindSet, name})
+	}
+	return references, nil
+}
+
+func setKey(family, table, name string) string {
+	return family + " " + table + " " + name
+}
+
+// setReferenceOperands are the operands which may reference a set or map, per expression or statement key:
+// the right side of a match, the data of a verdict map or map lookup and the target of a set or map statement
+// (e.g. `{"set":{"op":"add","elem":...,"set":"@name"}}`).
+var setReferenceOperands = map[string][]string{
+	"match": {"right"},
+	"vmap":  {"data"},
+	"map":   {"data", "map"},
+	"set":   {"set"},
+}
+
+// statementSetReferences returns the (sorted) names of the sets and maps which are referenced by the statement.
+// Only the operand positions of set references are considered (see setReferenceOperands),
+// as other strings may start with the reference prefix (e.g. a log prefix).
+func statementSetReferences(statement schema.Statement) ([]string, error) {
+	names := map[string]struct{}{}
+	err := walkStatementObjects(statement, func(object map[string]interface{}) {
+		for key, operands := range setReferenceOperands {
+			fields, isObject := object[key].(map[string]interface{})
+			if !isObject {
+				continue
+			}
+			for _, operand := range operands {
+				if s, isString := fields[operand].(string); isString && strings.HasPrefix(s, schema.SetRefPrefix) {
+					names[strings.TrimPrefix(s, schema.SetRefPrefix)] = struct{}{}
+				}
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sortedNames := make([]string, 0, len(names))
+	for name := range names {
+		sortedNames = append(sortedNames, name)
+	}
+	sort.Strings(sortedNames)
+	return sortedNames, nil
+}
+
+func isProtocolSupportedByFamily(protocol string, family string) bool {
+	families, restricted := payloadProtocolFamilies[protocol]
+	return !restricted || containsString(families, family)
+}
+
+// statementPayloadProtocols returns the (sorted) payload protocols which are used by the statement,
+// including ones nested in other expressions.
+func statementPayloadProtocols(statement schema.Statement) ([]string, error) {
+	protocols := map[string]struct{}{}
+	err := walkStatementObjects(statement, func(object map[string]interface{}) {
+		if payload, isPayload := object[schema.PayloadKey].(map[string]interface{}); isPayload {
+			if protocol, hasProtocol := payload["protocol"].(string); hasProtocol {
+				protocols[protocol] = struct{}{}
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sortedProtocols := make([]string, 0, len(protocols))
+	for protocol := range protocols {
+		sortedProtocols = append(sortedProtocols, protocol)
+	}
+	sort.Strings(sortedProtocols)
+	return sortedProtocols, nil
+}
+
+// walkStatementObjects calls the visit function with each JSON object of the serialized statement,
+// including the statement itself and all the nested objects.
+// Walking the serialized form covers both the typed and the row data expressions.
+func walkStatementObjects(statement schema.Statement, visit func(map[string]interface{})) error {
+	data, err := json.Marshal(statement)
+	if err != nil {
+		return err
+	}
+	var dynamicStructure interface{}
+	if err := json.Unmarshal(data, &dynamicStructure); err != nil {
+		return err
+	}
+	walkObjects(dynamicStructure, visit)
+	return nil
+}
+
+func walkObjects(value interface{}, visit func(map[string]interface{})) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		visit(v)
+		for _, item := range v {
+			walkObjects(item, visit)
+		}
+	case []interface{}:
+		for _, item := range v {
+			walkObjects(item, visit)
+		}
+	}
+}
@@ -0,0 +1,318 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft_test
+
+import (
+	"net"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/networkplumbing/go-nft/nft"
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+func TestValidateRuleFamily(t *testing.T) {
+	t.Run("Validate a rule with payload protocols supported by its family", func(t *testing.T) {
+		rule := newFamilyRule(schema.FamilyINET,
+			matchPayload(schema.PayloadProtocolIP4, schema.PayloadFieldIPSAddr, "10.0.0.1"),
+			matchPayload(schema.PayloadProtocolIP6, schema.PayloadFieldIPSAddr, "::1"),
+			matchPayload(schema.PayloadProtocolTCP, schema.PayloadFieldTCPDPort, "22"),
+		)
+		assert.NoError(t, nft.ValidateRuleFamily(rule))
+	})
+
+	t.Run("Validate a rule with an ip6 payload in the ip family", func(t *testing.T) {
+		rule := newFamilyRule(schema.FamilyIP,
+			matchPayload(schema.PayloadProtocolIP4, schema.PayloadFieldIPSAddr, "10.0.0.1"),
+			matchPayload(schema.PayloadProtocolIP6, schema.PayloadFieldIPSAddr, "::1"),
+		)
+		err := nft.ValidateRuleFamily(rule)
+		assert.EqualError(t, err,
+			`rule in chain "test-chain": statement 1: payload protocol "ip6" is not supported in the ip family`,
+		)
+	})
+
+	t.Run("Validate a rule with arp and tcp payloads outside the arp family", func(t *testing.T) {
+		rule := newFamilyRule(schema.FamilyINET,
+			matchPayload(schema.PayloadProtocolARP, schema.PayloadFieldARPOperation, "request"),
+		)
+		assert.Error(t, nft.ValidateRuleFamily(rule))
+
+		rule = newFamilyRule(schema.FamilyARP,
+			matchPayload(schema.PayloadProtocolARP, schema.PayloadFieldARPOperation, "request"),
+			matchPayload(schema.PayloadProtocolTCP, schema.PayloadFieldTCPDPort, "22"),
+		)
+		assert.EqualError(t, nft.ValidateRuleFamily(rule),
+			`rule in chain "test-chain": statement 1: payload protocol "tcp" is not supported in the arp family`,
+		)
+	})
+
+	t.Run("Validate a rule with a nested payload expression", func(t *testing.T) {
+		protocol := schema.PayloadProtocolIP6
+		rule := newFamilyRule(schema.FamilyIP, schema.Statement{Match: &schema.Match{
+			Op:   schema.OperEQ,
+			Left: schema.Expression{String: &protocol},
+			Right: schema.Expression{And: []schema.Expression{
+				{Payload: &schema.Payload{Protocol: schema.PayloadProtocolIP6, Field: schema.PayloadFieldIP6NextHdr}},
+				{String: &protocol},
+			}},
+		}})
+		assert.Error(t, nft.ValidateRuleFamily(rule))
+	})
+
+	t.Run("Validate a rule with a fwd statement", func(t *testing.T) {
+		assert.NoError(t, nft.ValidateRuleFamily(newFamilyRule(schema.FamilyNETDEV, nft.ForwardTo("eth1"))))
+		assert.EqualError(t, nft.ValidateRuleFamily(newFamilyRule(schema.FamilyINET, nft.ForwardTo("eth1"))),
+			`rule in chain "test-chain": statement 0: fwd is not supported in the inet family`,
+		)
+	})
+
+	t.Run("Add a checked rule with an incompatible payload protocol", func(t *testing.T) {
+		config := nft.NewConfig()
+		config.AddTable(nft.NewTable(tableName, nft.FamilyIP))
+
+		rule := newFamilyRule(schema.FamilyIP,
+			matchPayload(schema.PayloadProtocolIP6, schema.PayloadFieldIPSAddr, "::1"),
+		)
+		assert.Error(t, config.AddRuleChecked(rule))
+		assert.Nil(t, config.LookupRule(rule))
+	})
+}
+
+func newFamilyRule(family string, statements ...schema.Statement) *schema.Rule {
+	return &schema.Rule{
+		Family: family,
+		Table:  tableName,
+		Chain:  chainName,
+		Expr:   statements,
+	}
+}
+
+func matchPayload(protocol, field, value string) schema.Statement {
+	return schema.Statement{Match: &schema.Match{
+		Op:    schema.OperEQ,
+		Left:  schema.Expression{Payload: &schema.Payload{Protocol: protocol, Field: field}},
+		Right: schema.Expression{String: &value},
+	}}
+}
+
+func TestValidateSetReferences(t *testing.T) {
+	table := nft.NewTable(tableName, nft.FamilyIP)
+	chain := nft.NewRegularChain(table, chainName)
+	set := nft.NewSet(table, setName, nft.SetTypeIPv4Addr, nil, nil)
+	vmap := nft.NewVerdictMap(table, mapName, nft.SetTypeInetService, nil)
+	saddr := schema.Expression{Payload: &schema.Payload{Protocol: schema.PayloadProtocolIP4, Field: schema.PayloadFieldIPSAddr}}
+	dport := schema.Expression{Payload: &schema.Payload{Protocol: schema.PayloadProtocolTCP, Field: schema.PayloadFieldTCPDPort}}
+
+	newConfig := func() *nft.Config {
+		config := nft.NewConfig()
+		config.AddTable(table)
+		config.AddChain(chain)
+		config.AddRule(nft.NewRule(table, chain, []schema.Statement{
+			{Match: &schema.Match{Op: schema.OperEQ, Left: saddr, Right: nft.SetReference(set)}},
+			nft.VerdictMapLookup(dport, vmap),
+		}, nil, nil, ""))
+		return config
+	}
+
+	t.Run("Validate references to declared set and map", func(t *testing.T) {
+		config := newConfig()
+		config.AddSet(set)
+		config.AddMap(vmap)
+		assert.NoError(t, config.ValidateSetReferences())
+	})
+
+	t.Run("Validate references to created set and added map", func(t *testing.T) {
+		config := newConfig()
+		config.CreateSet(set)
+		config.Nftables = append(config.Nftables, schema.Nftable{Add: &schema.Objects{Map: vmap}})
+		assert.NoError(t, config.ValidateSetReferences())
+	})
+
+	t.Run("Validate references of an inserted rule to an undeclared set", func(t *testing.T) {
+		config := nft.NewConfig()
+		config.AddTable(table)
+		config.AddChain(chain)
+		config.InsertRule(nft.NewRule(table, chain, []schema.Statement{
+			{Match: &schema.Match{Op: schema.OperEQ, Left: saddr, Right: nft.SetReference(set)}},
+		}, nil, nil, ""))
+		assert.EqualError(t, config.ValidateSetReferences(),
+			`rule in chain "test-chain": statement 0: set "test-set" is not declared in table "test-table"`,
+		)
+	})
+
+	t.Run("Validate a log prefix which looks like a reference", func(t *testing.T) {
+		config := nft.NewConfig()
+		config.AddTable(table)
+		config.AddChain(chain)
+		config.AddRule(nft.NewRule(table, chain, []schema.Statement{nft.NewLog("@boot", "", nil)}, nil, nil, ""))
+		assert.NoError(t, config.ValidateSetReferences())
+	})
+
+	t.Run("Validate references to undeclared set and map", func(t *testing.T) {
+		config := newConfig()
+		otherTable := nft.NewTable("other-table", nft.FamilyIP)
+		config.AddSet(nft.NewSet(otherTable, setName, nft.SetTypeIPv4Addr, nil, nil))
+		assert.EqualError(t, config.ValidateSetReferences(),
+			`rule in chain "test-chain": statement 0: set "test-set" is not declared in table "test-table"; `+
+				`rule in chain "test-chain": statement 1: set "test-map" is not declared in table "test-table"`,
+		)
+	})
+}
+
+func TestValidate(t *testing.T) {
+	table := nft.NewTable(tableName, nft.FamilyIP)
+	ctype, hook, prio := nft.TypeFilter, nft.HookInput, 0
+	chain := nft.NewChain(table, chainName, &ctype, &hook, &prio, nil)
+	targetChain := nft.NewRegularChain(table, "target-chain")
+	address := net.ParseIP("10.0.0.1")
+
+	t.Run("Validate a valid config", func(t *testing.T) {
+		config := nft.NewConfig()
+		config.AddTable(table)
+		config.AddChain(chain)
+		config.AddChain(targetChain)
+		config.AddRule(nft.NewRule(table, chain, []schema.Statement{
+			nft.NewCounter(), {Verdict: schema.Verdict{Jump: &schema.ToTarget{Target: targetChain.Name}}},
+		}, nil, nil, ""))
+		config.AddRule(nft.NewRule(table, targetChain, []schema.Statement{nft.SNATTo(address, 0)}, nil, nil, ""))
+
+		assert.NoError(t, config.Validate())
+	})
+
+	t.Run("Validate a config of added and created chains", func(t *testing.T) {
+		config := nft.NewConfig()
+		config.AddTable(table)
+		config.CreateChain(chain)
+		config.Nftables = append(config.Nftables, schema.Nftable{Add: &schema.Objects{Chain: targetChain}})
+		config.AddRule(nft.NewRule(table, chain, []schema.Statement{
+			{Verdict: schema.Verdict{Jump: &schema.ToTarget{Target: targetChain.Name}}},
+		}, nil, nil, ""))
+		config.AddRule(nft.NewRule(table, targetChain, []schema.Statement{{Verdict: schema.Drop()}}, nil, nil, ""))
+
+		assert.NoError(t, config.Validate())
+	})
+
+	t.Run("Validate added and inserted rules", func(t *testing.T) {
+		config := nft.NewConfig()
+		config.AddTable(table)
+		config.AddChain(chain)
+		config.InsertRule(nft.NewRule(table, targetChain, []schema.Statement{
+			{Verdict: schema.Verdict{Jump: &schema.ToTarget{Target: "missing-target"}}},
+		}, nil, nil, ""))
+		config.Nftables = append(config.Nftables, schema.Nftable{Add: &schema.Objects{
+			Rule: nft.NewRule(table, chain, []schema.Statement{{Verdict: schema.Accept()}, nft.NewCounter()}, nil, nil, ""),
+		}})
+
+		assert.EqualError(t, config.Validate(),
+			`rule in chain "target-chain": chain is not declared in table "test-table"; `+
+				`rule in chain "target-chain": statement 0: target chain "missing-target" is not declared in table "test-table"; `+
+				`rule in chain "test-chain": statement 0: verdict is not the last statement`,
+		)
+	})
+
+	t.Run("Validate an invalid config", func(t *testing.T) {
+		config := nft.NewConfig()
+		config.AddTable(table)
+		config.AddChain(chain)
+		incompleteChain := nft.NewRegularChain(table, "incomplete-chain")
+		incompleteChain.Hook = string(nft.HookIngress)
+		config.AddChain(incompleteChain)
+		config.AddRule(nft.NewRule(table, chain, []schema.Statement{
+			{Verdict: schema.Accept()},
+			{Verdict: schema.Verdict{Goto: &schema.ToTarget{Target: "missing-target"}}},
+		}, nil, nil, ""))
+		config.AddRule(nft.NewRule(table, chain, []schema.Statement{nft.DNATTo(address, 8080)}, nil, nil, ""))
+		config.AddRule(nft.NewRule(table, targetChain, []schema.Statement{{Verdict: schema.Drop()}}, nil, nil, ""))
+
+		assert.EqualError(t, config.Validate(),
+			`base chain "incomplete-chain" in table "test-table": type, hook and priority are required; `+
+				`base chain "incomplete-chain" in table "test-table": hook "ingress" is not supported in the ip family; `+
+				`rule in chain "test-chain": statement 0: verdict is not the last statement; `+
+				`rule in chain "test-chain": statement 1: target chain "missing-target" is not declared in table "test-table"; `+
+				`rule in chain "test-chain": statement 0: NAT is not supported in a filter chain; `+
+				`rule in chain "target-chain": chain is not declared in table "test-table"`,
+		)
+	})
+}
+
+func TestCheckReferences(t *testing.T) {
+	table := nft.NewTable(tableName, nft.FamilyIP)
+	chain := nft.NewRegularChain(table, chainName)
+	targetChain := nft.NewRegularChain(table, "target-chain")
+	set := nft.NewSet(table, setName, nft.SetTypeIPv4Addr, nil, nil)
+	counter := &schema.NamedCounter{Family: table.Family, Table: table.Name, Name: "test-counter"}
+	saddr := schema.Expression{Payload: &schema.Payload{Protocol: schema.PayloadProtocolIP4, Field: schema.PayloadFieldIPSAddr}}
+
+	config := nft.NewConfig()
+	config.AddChain(chain)
+	config.AddRule(nft.NewRule(table, chain, []schema.Statement{
+		{Match: &schema.Match{Op: schema.OperEQ, Left: saddr, Right: nft.SetReference(set)}},
+		nft.NewCounterReference(counter),
+		{Verdict: schema.Verdict{Jump: &schema.ToTarget{Target: targetChain.Name}}},
+	}, nil, nil, ""))
+
+	t.Run("Check dangling references", func(t *testing.T) {
+		assert.EqualError(t, config.CheckReferences(nil),
+			`rule in chain "test-chain": statement 0: set "test-set" is not declared in table "test-table"; `+
+				`rule in chain "test-chain": statement 1: counter "test-counter" is not declared in table "test-table"; `+
+				`rule in chain "test-chain": statement 2: chain "target-chain" is not declared in table "test-table"`,
+		)
+	})
+
+	t.Run("Check references against an existing config", func(t *testing.T) {
+		existing := nft.NewConfig()
+		existing.AddSet(set)
+		existing.Nftables = append(existing.Nftables, schema.Nftable{Counter: counter})
+		config := config.Clone()
+		config.CreateChain(targetChain)
+
+		assert.NoError(t, config.CheckReferences(existing))
+	})
+
+	t.Run("Check references of added and inserted rules to created and added objects", func(t *testing.T) {
+		config := nft.NewConfig()
+		config.CreateChain(chain)
+		config.CreateSet(set)
+		config.Nftables = append(config.Nftables, schema.Nftable{Add: &schema.Objects{Counter: counter}})
+		config.InsertRule(nft.NewRule(table, chain, []schema.Statement{
+			{Match: &schema.Match{Op: schema.OperEQ, Left: saddr, Right: nft.SetReference(set)}},
+			nft.NewCounterReference(counter),
+		}, nil, nil, ""))
+		config.Nftables = append(config.Nftables, schema.Nftable{Add: &schema.Objects{
+			Rule: nft.NewRule(table, chain, []schema.Statement{{Verdict: schema.Verdict{Goto: &schema.ToTarget{Target: chain.Name}}}}, nil, nil, ""),
+		}})
+
+		assert.NoError(t, config.CheckReferences(nil))
+	})
+
+	t.Run("Check dangling references of an inserted rule", func(t *testing.T) {
+		config := nft.NewConfig()
+		config.CreateChain(chain)
+		config.InsertRule(nft.NewRule(table, chain, []schema.Statement{
+			{Verdict: schema.Verdict{Jump: &schema.ToTarget{Target: targetChain.Name}}},
+		}, nil, nil, ""))
+
+		assert.EqualError(t, config.CheckReferences(nil),
+			`rule in chain "test-chain": statement 0: chain "target-chain" is not declared in table "test-table"`,
+		)
+	})
+}
@@ -0,0 +1,60 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft
+
+import (
+	"fmt"
+
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+const (
+	vlanIDMax       = 4095
+	vlanPriorityMax = 7
+)
+
+// MatchVLANID returns a match statement of the 802.1Q VLAN identifier (`vlan id`).
+// It is commonly used in bridge family chains to filter the traffic of a specific VLAN.
+func MatchVLANID(id int) (schema.Statement, error) {
+	if id < 0 || id > vlanIDMax {
+		return schema.Statement{}, fmt.Errorf("invalid VLAN id %d", id)
+	}
+	return matchVLAN(schema.PayloadFieldVLANID, id), nil
+}
+
+// MatchVLANPriority returns a match statement of the 802.1Q VLAN priority code point (`vlan pcp`).
+func MatchVLANPriority(pcp int) (schema.Statement, error) {
+	if pcp < 0 || pcp > vlanPriorityMax {
+		return schema.Statement{}, fmt.Errorf("invalid VLAN priority %d", pcp)
+	}
+	return matchVLAN(schema.PayloadFieldVLANPcp, pcp), nil
+}
+
+func matchVLAN(field string, value int) schema.Statement {
+	v := float64(value)
+	return schema.Statement{Match: &schema.Match{
+		Op: schema.OperEQ,
+		Left: schema.Expression{Payload: &schema.Payload{
+			Protocol: schema.PayloadProtocolVLAN,
+			Field:    field,
+		}},
+		Right: schema.Expression{Float64: &v},
+	}}
+}
@@ -0,0 +1,63 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/networkplumbing/go-nft/nft"
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+func TestMatchVLAN(t *testing.T) {
+	t.Run("Match VLAN id, check serialization", func(t *testing.T) {
+		statement, err := nft.MatchVLANID(100)
+		assert.NoError(t, err)
+
+		serialized, err := json.Marshal(statement)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"match":{"op":"==","left":{"payload":{"protocol":"vlan","field":"id"}},"right":100}}`, string(serialized))
+	})
+
+	t.Run("Match VLAN priority, check deserialization", func(t *testing.T) {
+		serialized := `{"match":{"op":"==","left":{"payload":{"protocol":"vlan","field":"pcp"}},"right":5}}`
+
+		var statement schema.Statement
+		assert.NoError(t, json.Unmarshal([]byte(serialized), &statement))
+		expected, err := nft.MatchVLANPriority(5)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, statement)
+	})
+
+	t.Run("Match invalid VLAN id", func(t *testing.T) {
+		_, err := nft.MatchVLANID(4096)
+		assert.Error(t, err)
+		_, err = nft.MatchVLANID(-1)
+		assert.Error(t, err)
+	})
+
+	t.Run("Match invalid VLAN priority", func(t *testing.T) {
+		_, err := nft.MatchVLANPriority(8)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,144 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+const (
+	cmdMonitor = "monitor"
+
+	// monitorNewGeneration prefixes the monitor event of a committed transaction,
+	// e.g. `# new generation 12 by process 4242 (nft)`.
+	monitorNewGeneration = "# new generation "
+
+	// maxMonitorEventSize limits the size of a monitor event line, e.g. of a large rule.
+	maxMonitorEventSize = 16 * 1024 * 1024
+)
+
+// RulesetChange describes the changes of the ruleset which were committed by a (kernel) ruleset generation.
+type RulesetChange struct {
+	FromGeneration uint64
+	ToGeneration   uint64
+	// Added lists the objects (tables, chains, rules, sets, etc) which were added, in their committed order.
+	Added []schema.Nftable
+	// Removed lists the objects which were deleted, in their committed order.
+	// Deleted objects are commonly identified only by their name or handle.
+	Removed []schema.Nftable
+}
+
+// RulesetWatcher listens to the ruleset events reported by `nft -j monitor` and combines them
+// per kernel ruleset generation: each committed transaction is reported as one change.
+// Only the changes which are committed after the watch started are reported, therefore a consumer
+// which tracks the whole ruleset reads it (see ReadConfig) once the watch is started.
+// Events of other objects than tables, chains, rules, sets, maps, flowtables and named stateful objects
+// (e.g. set elements) are not reported.
+type RulesetWatcher struct {
+	executor *executor
+}
+
+// NewRulesetWatcher returns a watcher of the system ruleset.
+// The system is expected to have the `nft` executable deployed and nftables enabled in the kernel.
+func NewRulesetWatcher() *RulesetWatcher {
+	return &RulesetWatcher{executor: defaultExecutor}
+}
+
+// Watch listens to the ruleset events until the context is done or the monitor fails,
+// calling onChange for each committed ruleset generation which changed reported objects.
+// The returned error wraps the context error once the context is done.
+func (w *RulesetWatcher) Watch(ctx context.Context, onChange func(RulesetChange)) error {
+	monitorCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stdout, wait, err := w.executor.execCommandStream(monitorCtx, cmdJSON, cmdMonitor)
+	if err != nil {
+		return err
+	}
+
+	err = watchEvents(stdout, onChange)
+	if err != nil {
+		// The monitor is stopped, as its events are no longer consumed.
+		cancel()
+	}
+	if waitErr := wait(); waitErr != nil && err == nil {
+		err = waitErr
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return fmt.Errorf("ruleset watch stopped: %w", ctxErr)
+	}
+	return err
+}
+
+// watchEvents reads the monitor events until the end of the stream,
+// reporting the objects of the events which precede each new generation event.
+func watchEvents(stdout io.Reader, onChange func(RulesetChange)) error {
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(nil, maxMonitorEventSize)
+
+	var change RulesetChange
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		switch {
+		case len(line) == 0:
+		case bytes.HasPrefix(line, []byte(monitorNewGeneration)):
+			var generation uint64
+			if _, err := fmt.Sscanf(string(line), monitorNewGeneration+"%d", &generation); err != nil {
+				return fmt.Errorf("failed to parse the monitor event %q: %v", line, err)
+			}
+			if len(change.Added) > 0 || len(change.Removed) > 0 {
+				change.FromGeneration, change.ToGeneration = generation-1, generation
+				onChange(change)
+			}
+			change = RulesetChange{}
+		case line[0] == '#':
+		default:
+			var nftable schema.Nftable
+			if err := json.Unmarshal(line, &nftable); err != nil {
+				return fmt.Errorf("failed to parse the monitor event %q: %v", line, err)
+			}
+			if object, isObject := monitorObject(nftable.Add); isObject {
+				change.Added = append(change.Added, object)
+			}
+			if object, isObject := monitorObject(nftable.Delete); isObject {
+				change.Removed = append(change.Removed, object)
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// monitorObject returns the entry of the object (or rule) of a monitor event action,
+// if its kind is reported.
+func monitorObject(objects *schema.Objects) (schema.Nftable, bool) {
+	if objects == nil {
+		return schema.Nftable{}, false
+	}
+	nftable := objectsEntry(objects)
+	nftable.Rule = objects.Rule
+	_, isObject := objectIdentity(nftable)
+	return nftable, isObject || nftable.Rule != nil
+}
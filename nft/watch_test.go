@@ -0,0 +1,96 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/networkplumbing/go-nft/nft"
+)
+
+func TestRulesetWatcher(t *testing.T) {
+	// The fake nft prints the monitor events, then runs the given command.
+	fakeNft := func(t *testing.T, events, then string) string {
+		executable := filepath.Join(t.TempDir(), "fake-nft")
+		script := "#!/bin/sh\n[ \"$*\" = \"-j monitor\" ] || exit 1\ncat <<'EOF'\n" + events + "EOF\n" + then + "\n"
+		assert.NoError(t, os.WriteFile(executable, []byte(script), 0o755))
+		return executable
+	}
+
+	const events = `{"add": {"table": {"family": "ip", "name": "test-table", "handle": 1}}}
+{"add": {"chain": {"family": "ip", "table": "test-table", "name": "test-chain", "handle": 1}}}
+
+{"add": {"rule": {"family": "ip", "table": "test-table", "chain": "test-chain", "handle": 2, "expr": [{"accept": null}]}}}
+# new generation 7 by process 4242 (nft)
+{"add": {"element": {"family": "ip", "table": "test-table", "name": "test-set", "elem": {"set": ["10.0.0.1"]}}}}
+# new generation 8 by process 4242 (nft)
+{"delete": {"rule": {"family": "ip", "table": "test-table", "chain": "test-chain", "handle": 2}}}
+{"delete": {"table": {"family": "ip", "name": "test-table", "handle": 1}}}
+# new generation 9 by process 4242 (nft)
+`
+
+	t.Run("Report the changes of each committed generation", func(t *testing.T) {
+		watcher := nft.NewClient(nft.WithExecutable(fakeNft(t, events, "exit 0"))).NewRulesetWatcher()
+
+		var changes []nft.RulesetChange
+		assert.NoError(t, watcher.Watch(context.Background(), func(change nft.RulesetChange) {
+			changes = append(changes, change)
+		}))
+		assert.Len(t, changes, 2)
+
+		assert.Equal(t, uint64(6), changes[0].FromGeneration)
+		assert.Equal(t, uint64(7), changes[0].ToGeneration)
+		assert.Len(t, changes[0].Added, 3)
+		assert.Equal(t, tableName, changes[0].Added[0].Table.Name)
+		assert.Equal(t, chainName, changes[0].Added[1].Chain.Name)
+		assert.Equal(t, chainName, changes[0].Added[2].Rule.Chain)
+		assert.Empty(t, changes[0].Removed)
+
+		assert.Equal(t, uint64(8), changes[1].FromGeneration)
+		assert.Equal(t, uint64(9), changes[1].ToGeneration)
+		assert.Empty(t, changes[1].Added)
+		assert.Len(t, changes[1].Removed, 2)
+		assert.Equal(t, 2, *changes[1].Removed[0].Rule.Handle)
+		assert.Equal(t, tableName, changes[1].Removed[1].Table.Name)
+	})
+
+	t.Run("Stop watching once the context is done", func(t *testing.T) {
+		watcher := nft.NewClient(nft.WithExecutable(fakeNft(t, events, "exec sleep 10"))).NewRulesetWatcher()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		err := watcher.Watch(ctx, func(nft.RulesetChange) { cancel() })
+		assert.True(t, errors.Is(err, context.Canceled), err)
+	})
+
+	t.Run("Fail on an invalid monitor event", func(t *testing.T) {
+		watcher := nft.NewClient(nft.WithExecutable(fakeNft(t, "{\"add\": \n", "exec sleep 10"))).NewRulesetWatcher()
+
+		err := watcher.Watch(context.Background(), func(nft.RulesetChange) {})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to parse the monitor event")
+	})
+}
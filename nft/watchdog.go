@@ -0,0 +1,134 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+// Invariant is a named condition which the ruleset is expected to satisfy.
+type Invariant struct {
+	Name string
+	// Check returns an error describing the violation, or nil when the ruleset satisfies the invariant.
+	Check func(*Config) error
+}
+
+// InvariantViolation describes an invariant which the ruleset does not satisfy.
+type InvariantViolation struct {
+	Invariant string
+	Err       error
+}
+
+func (v InvariantViolation) Error() string {
+	return fmt.Sprintf("invariant %q violated: %v", v.Invariant, v.Err)
+}
+
+// Watchdog observes the ruleset periodically and verifies the invariants on each observation,
+// protecting against external changes (e.g. other tools flushing the ruleset).
+type Watchdog struct {
+	// Interval is the period between ruleset observations, it must be positive.
+	Interval time.Duration
+	// Read loads the observed ruleset. ReadConfig is used when not specified.
+	Read       func() (*Config, error)
+	Invariants []Invariant
+	// OnViolation is called with the observed ruleset and its violations, commonly to repair the ruleset
+	// (e.g. by re-applying the desired config) or to alert.
+	// Returning an error stops the watchdog.
+	OnViolation func(*Config, []InvariantViolation) error
+}
+
+// Run observes the ruleset until the context is done, a read fails or the violation callback fails.
+// The ruleset is verified immediately and then on each interval.
+// It fails when the interval is not positive.
+func (w *Watchdog) Run(ctx context.Context) error {
+	if w.Interval <= 0 {
+		return fmt.Errorf("invalid watchdog interval: %v", w.Interval)
+	}
+
+	read := w.Read
+	if read == nil {
+		read = ReadConfig
+	}
+
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+	for {
+		config, err := read()
+		if err != nil {
+			return err
+		}
+		if violations := w.Verify(config); len(violations) > 0 && w.OnViolation != nil {
+			if err := w.OnViolation(config, violations); err != nil {
+				return err
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Verify returns the invariants which the given ruleset violates.
+func (w *Watchdog) Verify(config *Config) []InvariantViolation {
+	var violations []InvariantViolation
+	for _, invariant := range w.Invariants {
+		if err := invariant.Check(config); err != nil {
+			violations = append(violations, InvariantViolation{Invariant: invariant.Name, Err: err})
+		}
+	}
+	return violations
+}
+
+// TableExistsInvariant returns an invariant which verifies that the table exists.
+func TableExistsInvariant(table *schema.Table) Invariant {
+	return Invariant{
+		Name: fmt.Sprintf("table %s %s exists", table.Family, table.Name),
+		Check: func(c *Config) error {
+			if c.LookupTable(table) == nil {
+				return fmt.Errorf("table not found")
+			}
+			return nil
+		},
+	}
+}
+
+// ChainPolicyInvariant returns an invariant which verifies that the (base) chain exists with the given policy.
+func ChainPolicyInvariant(chain *schema.Chain, policy ChainPolicy) Invariant {
+	return Invariant{
+		Name: fmt.Sprintf("chain %s %s %s policy is %s", chain.Family, chain.Table, chain.Name, policy),
+		Check: func(c *Config) error {
+			found := c.LookupChain(&schema.Chain{Family: chain.Family, Table: chain.Table, Name: chain.Name})
+			if found == nil {
+				return fmt.Errorf("chain not found")
+			}
+			if found.Policy != string(policy) {
+				return fmt.Errorf("chain policy is %q", found.Policy)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,116 @@
+/*
+ * This file is part of the go-nft project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package nft_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/networkplumbing/go-nft/nft"
+	"github.com/networkplumbing/go-nft/nft/schema"
+)
+
+func TestWatchdog(t *testing.T) {
+	table := nft.NewTable(tableName, nft.FamilyINET)
+	ctype, hook, prio, policy := nft.TypeFilter, nft.HookInput, 0, nft.PolicyDrop
+	chain := nft.NewChain(table, chainName, &ctype, &hook, &prio, &policy)
+	acceptPolicy := nft.PolicyAccept
+	acceptingChain := nft.NewChain(table, chainName, &ctype, &hook, &prio, &acceptPolicy)
+
+	invariants := []nft.Invariant{
+		nft.TableExistsInvariant(table),
+		nft.ChainPolicyInvariant(chain, nft.PolicyDrop),
+	}
+
+	t.Run("Verify a ruleset which satisfies the invariants", func(t *testing.T) {
+		watchdog := nft.Watchdog{Invariants: invariants}
+		config := nft.NewConfig()
+		config.AddTable(table)
+		config.AddChain(chain)
+		assert.Empty(t, watchdog.Verify(config))
+	})
+
+	t.Run("Verify a ruleset which violates the invariants", func(t *testing.T) {
+		watchdog := nft.Watchdog{Invariants: invariants}
+		config := nft.NewConfig()
+		config.AddChain(acceptingChain)
+
+		violations := watchdog.Verify(config)
+		assert.Len(t, violations, 2)
+		assert.EqualError(t, violations[0], `invariant "table inet test-table exists" violated: table not found`)
+		assert.EqualError(t, violations[1], `invariant "chain inet test-table test-chain policy is drop" violated: chain policy is "accept"`)
+	})
+
+	t.Run("Run the watchdog and repair violations", func(t *testing.T) {
+		healthy := []schema.Nftable{{Table: table}, {Chain: chain}}
+		generations := [][]schema.Nftable{healthy, {}, healthy}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		reads := 0
+		var repaired []int
+		watchdog := nft.Watchdog{
+			Interval:   time.Millisecond,
+			Invariants: invariants,
+			Read: func() (*nft.Config, error) {
+				config := nft.NewConfig()
+				config.Nftables = generations[reads]
+				if reads < len(generations)-1 {
+					reads++
+				} else {
+					cancel()
+				}
+				return config, nil
+			},
+			OnViolation: func(config *nft.Config, violations []nft.InvariantViolation) error {
+				repaired = append(repaired, len(violations))
+				return nil
+			},
+		}
+
+		assert.Equal(t, context.Canceled, watchdog.Run(ctx))
+		assert.Equal(t, []int{2}, repaired)
+	})
+
+	t.Run("Run the watchdog until the violation callback fails", func(t *testing.T) {
+		watchdog := nft.Watchdog{
+			Interval:   time.Millisecond,
+			Invariants: invariants,
+			Read:       func() (*nft.Config, error) { return nft.NewConfig(), nil },
+			OnViolation: func(*nft.Config, []nft.InvariantViolation) error {
+				return fmt.Errorf("repair failed")
+			},
+		}
+		assert.EqualError(t, watchdog.Run(context.Background()), "repair failed")
+	})
+
+	t.Run("Run the watchdog without an interval", func(t *testing.T) {
+		watchdog := nft.Watchdog{
+			Invariants: invariants,
+			Read:       func() (*nft.Config, error) { return nft.NewConfig(), nil },
+		}
+		assert.Error(t, watchdog.Run(context.Background()))
+	})
+}
@@ -20,7 +20,10 @@
 package tests
 
 import (
+	"context"
+	"errors"
 	"testing"
+	"time"
 
 	assert "github.com/stretchr/testify/require"
 
@@ -31,6 +34,16 @@ import (
 func TestConfig(t *testing.T) {
 	runTestWithFlushTable(t, testReadEmptyConfig)
 	runTestWithFlushTable(t, testApplyConfigWithAnEmptyTable)
+	runTestWithFlushTable(t, testReadSetsAndCounters)
+	runTestWithFlushTable(t, testConntrackAvailable)
+	runTestWithFlushTable(t, testApplyConfigResult)
+	runTestWithFlushTable(t, testApplyAndReadConfigContext)
+	runTestWithFlushTable(t, testApplyConfigEcho)
+	runTestWithFlushTable(t, testDeleteRulesWhere)
+	runTestWithFlushTable(t, testReadRuleCountersByComment)
+	runTestWithFlushTable(t, testReadOnlyClient)
+	runTestWithFlushTable(t, testApplyConfigCommandErrors)
+	runTestWithFlushTable(t, testResetCountersAndQuotas)
 }
 
 func runTestWithFlushTable(t *testing.T, test func(t *testing.T)) {
@@ -65,5 +78,200 @@ func testApplyConfigWithAnEmptyTable(t *testing.T) {
 	assert.NoError(t, err)
 
 	assert.Len(t, newConfig.Nftables, 2, "Expecting the metainfo and an empty table entry")
+	newConfig.Nftables[1].Table.Handle = nil
 	assert.Equal(t, config.Nftables[0], newConfig.Nftables[1])
 }
+
+func testReadSetsAndCounters(t *testing.T) {
+	config := nft.NewConfig()
+	table := nft.NewTable("mytable", nft.FamilyIP)
+	config.AddTable(table)
+	assert.NoError(t, config.AddRawCommand([]byte(
+		`{"add":{"set":{"family":"ip","table":"mytable","name":"myset","type":"inet_service","elem":[22]}}}`,
+	)))
+	assert.NoError(t, config.AddRawCommand([]byte(
+		`{"add":{"counter":{"family":"ip","table":"mytable","name":"mycounter"}}}`,
+	)))
+	assert.NoError(t, nft.ApplyConfig(config))
+
+	setsConfig, err := nft.ReadSets(table)
+	assert.NoError(t, err)
+	assert.Len(t, setsConfig.Nftables, 2, "Expecting the metainfo and a set entry")
+	assert.Equal(t, "myset", setsConfig.Nftables[1].Set.Name)
+
+	countersConfig, err := nft.ReadCounters(table)
+	assert.NoError(t, err)
+	assert.Len(t, countersConfig.Nftables, 2, "Expecting the metainfo and a counter entry")
+	assert.Equal(t, "mycounter", countersConfig.Nftables[1].Counter.Name)
+
+	iterator, err := nft.ReadSetElements(setsConfig.Nftables[1].Set)
+	assert.NoError(t, err)
+	elements, err := iterator.Next(10)
+	assert.NoError(t, err)
+	assert.NoError(t, iterator.Close())
+	assert.Len(t, elements, 1)
+	assert.Equal(t, float64(22), *elements[0].Float64)
+}
+
+func testConntrackAvailable(t *testing.T) {
+	available, err := nft.ConntrackAvailable()
+	assert.NoError(t, err)
+	assert.True(t, available)
+
+	config, err := nft.ReadConfig()
+	assert.NoError(t, err)
+	assert.Len(t, config.Nftables, 1, "Expecting the probe not to be applied")
+}
+
+func testApplyConfigResult(t *testing.T) {
+	config := nft.NewConfig()
+	config.AddTable(nft.NewTable("mytable", nft.FamilyIP))
+	assert.NoError(t, config.AddRawCommand([]byte(
+		`{"add":{"counter":{"family":"ip","table":"mytable","name":"mycounter"}}}`,
+	)))
+
+	result, err := nft.ApplyConfigResult(config)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.Commands)
+	assert.NotEmpty(t, result.NftVersion)
+	assert.Positive(t, int64(result.Duration))
+	assert.Equal(t, config.RawCommandWarnings(), result.Warnings)
+}
+
+func testApplyAndReadConfigContext(t *testing.T) {
+	config := nft.NewConfig()
+	config.AddTable(nft.NewTable("mytable", nft.FamilyIP))
+
+	canceledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := nft.ApplyConfigContext(canceledCtx, config)
+	assert.True(t, errors.Is(err, context.Canceled), err)
+	_, err = nft.ReadConfigContext(canceledCtx)
+	assert.True(t, errors.Is(err, context.Canceled), err)
+
+	expiredCtx, cancel := context.WithTimeout(context.Background(), -time.Second)
+	defer cancel()
+	_, err = nft.ReadConfigContext(expiredCtx)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	assert.NoError(t, nft.ApplyConfigContext(ctx, config))
+	newConfig, err := nft.ReadConfigContext(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, newConfig.Nftables, 2, "Expecting the metainfo and an empty table entry")
+}
+
+func testApplyConfigEcho(t *testing.T) {
+	config := nft.NewConfig()
+	table := nft.NewTable("mytable", nft.FamilyIP)
+	config.AddTable(table)
+	chain := nft.NewRegularChain(table, "mychain")
+	config.AddChain(chain)
+	config.AddRule(nft.NewRule(table, chain, []schema.Statement{{Verdict: schema.Accept()}}, nil, nil, "first"))
+	config.AddRule(nft.NewRule(table, chain, []schema.Statement{{Verdict: schema.Drop()}}, nil, nil, "second"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	echoed, err := nft.ApplyConfigEchoContext(ctx, config)
+	assert.NoError(t, err)
+	assert.Len(t, echoed.Nftables, len(config.Nftables), "Expecting an echoed entry per applied entry")
+
+	newConfig, err := nft.ReadConfig()
+	assert.NoError(t, err)
+	for i, nftable := range config.Nftables {
+		rule := nftable.Rule
+		if rule == nil {
+			continue
+		}
+		echoedRule := echoed.Nftables[i].Rule
+		assert.NotNil(t, echoedRule)
+		assert.Equal(t, rule.Comment, echoedRule.Comment)
+		assert.NotNil(t, echoedRule.Handle)
+
+		listed := newConfig.LookupRule(&schema.Rule{Family: table.Family, Table: table.Name, Chain: chain.Name, Handle: echoedRule.Handle})
+		assert.Len(t, listed, 1)
+		assert.Equal(t, rule.Comment, listed[0].Comment, "The echoed handle is the one of the added rule")
+	}
+}
+
+func testDeleteRulesWhere(t *testing.T) {
+	config := nft.NewConfig()
+	table := nft.NewTable("mytable", nft.FamilyIP)
+	config.AddTable(table)
+	chain := nft.NewRegularChain(table, "mychain")
+	config.AddChain(chain)
+	config.AddRule(nft.NewRule(table, chain, []schema.Statement{{Verdict: schema.Accept()}}, nil, nil, "keep"))
+	config.AddRule(nft.NewRule(table, chain, []schema.Statement{{Verdict: schema.Drop()}}, nil, nil, "remove"))
+	assert.NoError(t, nft.ApplyConfig(config))
+
+	deleted, err := nft.DeleteRulesWhere(table, chain, func(rule *schema.Rule) bool { return rule.Comment == "remove" })
+	assert.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+
+	newConfig, err := nft.ReadConfig()
+	assert.NoError(t, err)
+	rules := newConfig.LookupRule(&schema.Rule{Family: table.Family, Table: table.Name, Chain: chain.Name})
+	assert.Len(t, rules, 1)
+	assert.Equal(t, "keep", rules[0].Comment)
+}
+
+func testReadRuleCountersByComment(t *testing.T) {
+	config := nft.NewConfig()
+	table := nft.NewTable("mytable", nft.FamilyIP)
+	config.AddTable(table)
+	chain := nft.NewRegularChain(table, "mychain")
+	config.AddChain(chain)
+	config.AddRule(nft.NewRule(table, chain, []schema.Statement{nft.NewCounter()}, nil, nil, "counted"))
+	assert.NoError(t, nft.ApplyConfig(config))
+
+	counters, err := nft.ReadRuleCountersByComment(table)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]nft.CounterValue{"counted": {}}, counters)
+}
+
+func testReadOnlyClient(t *testing.T) {
+	config := nft.NewConfig()
+	config.AddTable(nft.NewTable("mytable", nft.FamilyIP))
+
+	client := nft.NewClient(nft.WithReadOnly())
+	assert.True(t, errors.Is(client.ApplyConfig(config), nft.ErrReadOnly))
+
+	newConfig, err := client.ReadConfig()
+	assert.NoError(t, err)
+	assert.Nil(t, newConfig.LookupTable(config.Nftables[0].Table))
+}
+
+func testApplyConfigCommandErrors(t *testing.T) {
+	config := nft.NewConfig()
+	table := nft.NewTable("mytable", nft.FamilyIP)
+	config.AddTable(table)
+	config.AddRule(nft.NewRule(table, nft.NewRegularChain(table, "missing-chain"), []schema.Statement{{Verdict: schema.Accept()}}, nil, nil, ""))
+
+	err := nft.ApplyConfig(config)
+	var applyErr *nft.ApplyError
+	assert.True(t, errors.As(err, &applyErr), err)
+	assert.Len(t, applyErr.Commands, 1)
+	assert.Equal(t, 1, applyErr.Commands[0].Index)
+}
+
+func testResetCountersAndQuotas(t *testing.T) {
+	config := nft.NewConfig()
+	table := nft.NewTable("mytable", nft.FamilyIP)
+	config.AddTable(table)
+	config.Nftables = append(config.Nftables, schema.Nftable{
+		Counter: &schema.NamedCounter{Family: table.Family, Table: table.Name, Name: "mycounter"},
+	})
+	config.AddQuota(nft.NewNamedQuota(table, "myquota", 1000, false))
+	assert.NoError(t, nft.ApplyConfig(config))
+
+	countersConfig, err := nft.ResetCounters(table)
+	assert.NoError(t, err)
+	assert.Len(t, countersConfig.Nftables, 2, "Expecting the metainfo and a counter entry")
+	assert.Equal(t, "mycounter", countersConfig.Nftables[1].Counter.Name)
+
+	quotasConfig, err := nft.ResetQuotas(table)
+	assert.NoError(t, err)
+	assert.Len(t, quotasConfig.Nftables, 2, "Expecting the metainfo and a quota entry")
+	assert.Equal(t, 1000, quotasConfig.Nftables[1].Quota.Bytes)
+}
@@ -75,6 +75,12 @@ func normalizeConfigForComparison(config *nft.Config) *nft.Config {
 	}
 
 	for _, nftable := range config.Nftables {
+		if nftable.Table != nil {
+			nftable.Table.Handle = nil
+		}
+		if nftable.Chain != nil {
+			nftable.Chain.Handle = nil
+		}
 		if nftable.Rule != nil {
 			nftable.Rule.Index = nil
 			nftable.Rule.Handle = nil